@@ -0,0 +1,139 @@
+package v1
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cylonchau/hermes/pkg/app/api/query"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+type GeoIPOverrideRouter struct {
+	DAO *rdb.GeoIPOverrideDAO
+}
+
+func (gr *GeoIPOverrideRouter) List(c *gin.Context) {
+	overrides, err := gr.DAO.GetAll(c.Request.Context())
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, overrides)
+}
+
+func (gr *GeoIPOverrideRouter) Create(c *gin.Context) {
+	var override model.GeoIPOverride
+	if err := c.ShouldBindJSON(&override); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateGeoIPOverride(&override); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := gr.DAO.Create(c.Request.Context(), &override); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, override)
+}
+
+func (gr *GeoIPOverrideRouter) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	override, err := gr.DAO.GetByID(c.Request.Context(), id)
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+	query.SuccessResponse(c, nil, override)
+}
+
+func (gr *GeoIPOverrideRouter) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	override, err := gr.DAO.GetByID(c.Request.Context(), id)
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&override); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	override.ID = id
+	if err := validateGeoIPOverride(override); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+
+	if err := gr.DAO.Update(c.Request.Context(), override); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, override)
+}
+
+func (gr *GeoIPOverrideRouter) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	if err := gr.DAO.Delete(c.Request.Context(), id); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
+
+// validateGeoIPOverride normalizes the CIDR and codes of an override and
+// checks that it pins at least a location or an autonomous system
+func validateGeoIPOverride(override *model.GeoIPOverride) error {
+	cidr := strings.TrimSpace(override.CIDR)
+	if !strings.Contains(cidr, "/") {
+		if addr, err := netip.ParseAddr(cidr); err == nil {
+			cidr = netip.PrefixFrom(addr, addr.BitLen()).String()
+		}
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return fmt.Errorf("invalid cidr %q", override.CIDR)
+	}
+	override.CIDR = prefix.Masked().String()
+
+	override.Continent = strings.ToUpper(strings.TrimSpace(override.Continent))
+	switch override.Continent {
+	case "", "AF", "AN", "AS", "EU", "NA", "OC", "SA":
+	default:
+		return fmt.Errorf("invalid continent %q", override.Continent)
+	}
+	override.Country = strings.ToUpper(strings.TrimSpace(override.Country))
+	if override.Country != "" && (len(override.Country) != 2 || !isAlnum(override.Country)) {
+		return fmt.Errorf("invalid country %q", override.Country)
+	}
+	override.Region = strings.ToUpper(strings.TrimSpace(override.Region))
+	if override.Region != "" {
+		if override.Country == "" {
+			return fmt.Errorf("region needs a country")
+		}
+		if !isAlnum(override.Region) {
+			return fmt.Errorf("invalid region %q", override.Region)
+		}
+	}
+	override.Organization = strings.TrimSpace(override.Organization)
+	if override.Organization != "" && override.ASN == 0 {
+		return fmt.Errorf("organization needs an asn")
+	}
+
+	if err := validateCoordinates(override.Latitude, override.Longitude); err != nil {
+		return err
+	}
+	if override.Continent == "" && override.Country == "" && override.ASN == 0 && override.Latitude == nil {
+		return fmt.Errorf("override must set a continent, country, asn or coordinates")
+	}
+	return nil
+}
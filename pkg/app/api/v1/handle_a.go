@@ -10,7 +10,8 @@ import (
 )
 
 type ARecordRouter struct {
-	DAO *rdb.RecordDAO
+	DAO  *rdb.RecordDAO
+	PoPs *rdb.PoPDAO
 }
 
 func (ar *ARecordRouter) List(c *gin.Context) {
@@ -38,6 +39,13 @@ func (ar *ARecordRouter) Create(c *gin.Context) {
 		query.BadRequest(c, err)
 		return
 	}
+	if err := validateCoordinates(recordAReq.A.Latitude, recordAReq.A.Longitude); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if !checkRecordPoP(c, ar.PoPs, &recordAReq.A.PoP) {
+		return
+	}
 	if err := ar.DAO.CreateARecord(c.Request.Context(), &recordAReq.Record, &recordAReq.A); err != nil {
 		query.InternalError(c, err)
 		return
@@ -69,6 +77,13 @@ func (ar *ARecordRouter) Update(c *gin.Context) {
 		query.BadRequest(c, err)
 		return
 	}
+	if err := validateCoordinates(record.Latitude, record.Longitude); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if !checkRecordPoP(c, ar.PoPs, &record.PoP) {
+		return
+	}
 
 	if err := ar.DAO.UpdateARecord(c.Request.Context(), &record.Record, record); err != nil {
 		query.InternalError(c, err)
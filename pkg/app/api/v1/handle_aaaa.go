@@ -10,7 +10,8 @@ import (
 )
 
 type AAAARecordRouter struct {
-	DAO *rdb.RecordDAO
+	DAO  *rdb.RecordDAO
+	PoPs *rdb.PoPDAO
 }
 
 func (ar *AAAARecordRouter) List(c *gin.Context) {
@@ -38,6 +39,13 @@ func (ar *AAAARecordRouter) Create(c *gin.Context) {
 		query.BadRequest(c, err)
 		return
 	}
+	if err := validateCoordinates(req.AAAA.Latitude, req.AAAA.Longitude); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if !checkRecordPoP(c, ar.PoPs, &req.AAAA.PoP) {
+		return
+	}
 	if err := ar.DAO.CreateAAAARecord(c.Request.Context(), &req.Record, &req.AAAA); err != nil {
 		query.InternalError(c, err)
 		return
@@ -69,6 +77,13 @@ func (ar *AAAARecordRouter) Update(c *gin.Context) {
 		query.BadRequest(c, err)
 		return
 	}
+	if err := validateCoordinates(record.Latitude, record.Longitude); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if !checkRecordPoP(c, ar.PoPs, &record.PoP) {
+		return
+	}
 
 	if err := ar.DAO.UpdateAAAARecord(c.Request.Context(), &record.Record, record); err != nil {
 		query.InternalError(c, err)
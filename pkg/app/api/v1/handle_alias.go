@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cylonchau/hermes/pkg/app/api/query"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+	"github.com/gin-gonic/gin"
+	"github.com/miekg/dns"
+)
+
+type ALIASRecordRouter struct {
+	DAO *rdb.RecordDAO
+}
+
+func (ar *ALIASRecordRouter) List(c *gin.Context) {
+	viewIDStr := c.Query("view_id")
+	var viewID *int64
+	if viewIDStr != "" {
+		id, _ := strconv.ParseInt(viewIDStr, 10, 64)
+		viewID = &id
+	}
+
+	records, err := ar.DAO.ListALIASRecords(c.Request.Context(), viewID)
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, records)
+}
+
+func (ar *ALIASRecordRouter) Create(c *gin.Context) {
+	var req struct {
+		Record model.Record      `json:"record"`
+		ALIAS  model.ALIASRecord `json:"alias"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateALIAS(&req.ALIAS); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := ar.DAO.CreateALIASRecord(c.Request.Context(), &req.Record, &req.ALIAS); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, req.ALIAS)
+}
+
+func (ar *ALIASRecordRouter) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := ar.DAO.GetALIASRecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (ar *ALIASRecordRouter) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := ar.DAO.GetALIASRecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateALIAS(record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+
+	if err := ar.DAO.UpdateALIASRecord(c.Request.Context(), &record.Record, record); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (ar *ALIASRecordRouter) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	if err := ar.DAO.DeleteALIASRecord(c.Request.Context(), uint(id)); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
+
+// validateALIAS checks the flattening target and stores it fully qualified
+func validateALIAS(record *model.ALIASRecord) error {
+	if _, ok := dns.IsDomainName(record.Target); !ok || record.Target == "" || record.Target == "." {
+		return fmt.Errorf("invalid target %q", record.Target)
+	}
+	record.Target = dns.Fqdn(record.Target)
+	return nil
+}
@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cylonchau/hermes/pkg/app/api/query"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+	"github.com/gin-gonic/gin"
+	"github.com/miekg/dns"
+)
+
+type DNAMERecordRouter struct {
+	DAO *rdb.RecordDAO
+}
+
+func (dr *DNAMERecordRouter) List(c *gin.Context) {
+	viewIDStr := c.Query("view_id")
+	var viewID *int64
+	if viewIDStr != "" {
+		id, _ := strconv.ParseInt(viewIDStr, 10, 64)
+		viewID = &id
+	}
+
+	records, err := dr.DAO.ListDNAMERecords(c.Request.Context(), viewID)
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, records)
+}
+
+func (dr *DNAMERecordRouter) Create(c *gin.Context) {
+	var req struct {
+		Record model.Record      `json:"record"`
+		DNAME  model.DNAMERecord `json:"dname"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateDNAME(&req.DNAME); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := dr.DAO.CreateDNAMERecord(c.Request.Context(), &req.Record, &req.DNAME); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, req.DNAME)
+}
+
+func (dr *DNAMERecordRouter) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := dr.DAO.GetDNAMERecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (dr *DNAMERecordRouter) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := dr.DAO.GetDNAMERecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateDNAME(record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+
+	if err := dr.DAO.UpdateDNAMERecord(c.Request.Context(), &record.Record, record); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (dr *DNAMERecordRouter) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	if err := dr.DAO.DeleteDNAMERecord(c.Request.Context(), uint(id)); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
+
+// validateDNAME checks the redirection target and stores it fully qualified
+func validateDNAME(record *model.DNAMERecord) error {
+	if _, ok := dns.IsDomainName(record.Target); !ok || record.Target == "" {
+		return fmt.Errorf("invalid target %q", record.Target)
+	}
+	record.Target = dns.Fqdn(record.Target)
+	return nil
+}
@@ -0,0 +1,170 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cylonchau/hermes/pkg/app/api/query"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+	"github.com/gin-gonic/gin"
+	"github.com/miekg/dns"
+)
+
+type GenericRecordRouter struct {
+	DAO *rdb.RecordDAO
+}
+
+func (gr *GenericRecordRouter) List(c *gin.Context) {
+	viewIDStr := c.Query("view_id")
+	var viewID *int64
+	if viewIDStr != "" {
+		id, _ := strconv.ParseInt(viewIDStr, 10, 64)
+		viewID = &id
+	}
+
+	records, err := gr.DAO.ListGenericRecords(c.Request.Context(), viewID)
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, records)
+}
+
+func (gr *GenericRecordRouter) Create(c *gin.Context) {
+	var req struct {
+		Record  model.Record        `json:"record"`
+		Generic model.GenericRecord `json:"generic"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateGeneric(&req.Record, &req.Generic); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := gr.DAO.CreateGenericRecord(c.Request.Context(), &req.Record, &req.Generic); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, req.Generic)
+}
+
+func (gr *GenericRecordRouter) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := gr.DAO.GetGenericRecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (gr *GenericRecordRouter) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := gr.DAO.GetGenericRecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateGeneric(&record.Record, record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+
+	if err := gr.DAO.UpdateGenericRecord(c.Request.Context(), &record.Record, record); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (gr *GenericRecordRouter) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	if err := gr.DAO.DeleteGenericRecord(c.Request.Context(), uint(id)); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
+
+// dedicatedTypes are served from their own tables and cannot be stored as generic records
+var dedicatedTypes = map[uint16]bool{
+	dns.TypeA:     true,
+	dns.TypeAAAA:  true,
+	dns.TypeCNAME: true,
+	dns.TypeDNAME: true,
+	dns.TypeMX:    true,
+	dns.TypeTXT:   true,
+	dns.TypeNS:    true,
+	dns.TypeSOA:   true,
+	dns.TypeSRV:   true,
+	dns.TypePTR:   true,
+	dns.TypeCAA:   true,
+	dns.TypeSVCB:  true,
+	dns.TypeHTTPS: true,
+	dns.TypeTLSA:  true,
+	dns.TypeSSHFP: true,
+	// Private-use number ALIAS answers are cached under; generic records of
+	// that type would share their cache keys
+	model.TypeALIAS: true,
+}
+
+// metaTypes only exist in queries or transactions, never as zone data
+var metaTypes = map[uint16]bool{
+	dns.TypeNone:  true,
+	dns.TypeOPT:   true,
+	dns.TypeTKEY:  true,
+	dns.TypeTSIG:  true,
+	dns.TypeIXFR:  true,
+	dns.TypeAXFR:  true,
+	dns.TypeMAILB: true,
+	dns.TypeMAILA: true,
+	dns.TypeANY:   true,
+}
+
+// validateGeneric resolves the record type from rr_type or the record's type
+// name (e.g. "NAPTR" or "TYPE65534") and checks that the RDATA parses
+func validateGeneric(record *model.Record, generic *model.GenericRecord) error {
+	rrType := generic.RRType
+	if rrType == 0 {
+		name := strings.ToUpper(strings.TrimSpace(record.Type))
+		if t, ok := dns.StringToType[name]; ok {
+			rrType = t
+		} else if strings.HasPrefix(name, "TYPE") {
+			n, err := strconv.ParseUint(name[4:], 10, 16)
+			if err != nil {
+				return fmt.Errorf("invalid record type %q", record.Type)
+			}
+			rrType = uint16(n)
+		}
+	}
+	if rrType == 0 || metaTypes[rrType] {
+		return fmt.Errorf("invalid record type %q", record.Type)
+	}
+	if dedicatedTypes[rrType] {
+		return fmt.Errorf("%s records must be created through /api/v1/records/%s", dns.Type(rrType), strings.ToLower(dns.Type(rrType).String()))
+	}
+
+	generic.RData = strings.TrimSpace(generic.RData)
+	if generic.RData == "" {
+		return fmt.Errorf("rdata is required")
+	}
+	if _, err := dns.NewRR(fmt.Sprintf(". 0 IN %s %s", dns.Type(rrType), generic.RData)); err != nil {
+		return fmt.Errorf("invalid rdata: %w", err)
+	}
+
+	generic.RRType = rrType
+	record.Type = dns.Type(rrType).String()
+	return nil
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"strconv"
+
+	"github.com/cylonchau/hermes/pkg/app/api/query"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+	"github.com/gin-gonic/gin"
+)
+
+type HTTPSRecordRouter struct {
+	DAO *rdb.RecordDAO
+}
+
+func (hr *HTTPSRecordRouter) List(c *gin.Context) {
+	viewIDStr := c.Query("view_id")
+	var viewID *int64
+	if viewIDStr != "" {
+		id, _ := strconv.ParseInt(viewIDStr, 10, 64)
+		viewID = &id
+	}
+
+	records, err := hr.DAO.ListHTTPSRecords(c.Request.Context(), viewID)
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, records)
+}
+
+func (hr *HTTPSRecordRouter) Create(c *gin.Context) {
+	var req struct {
+		Record model.Record      `json:"record"`
+		HTTPS  model.HTTPSRecord `json:"https"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateSVCB(req.HTTPS.Priority, req.HTTPS.Target, req.HTTPS.SvcParams); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := hr.DAO.CreateHTTPSRecord(c.Request.Context(), &req.Record, &req.HTTPS); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, req.HTTPS)
+}
+
+func (hr *HTTPSRecordRouter) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := hr.DAO.GetHTTPSRecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (hr *HTTPSRecordRouter) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := hr.DAO.GetHTTPSRecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateSVCB(record.Priority, record.Target, record.SvcParams); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+
+	if err := hr.DAO.UpdateHTTPSRecord(c.Request.Context(), &record.Record, record); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (hr *HTTPSRecordRouter) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	if err := hr.DAO.DeleteHTTPSRecord(c.Request.Context(), uint(id)); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"strconv"
+
+	"github.com/cylonchau/hermes/pkg/app/api/query"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+	"github.com/gin-gonic/gin"
+)
+
+type PTRRecordRouter struct {
+	DAO *rdb.RecordDAO
+}
+
+func (pr *PTRRecordRouter) List(c *gin.Context) {
+	viewIDStr := c.Query("view_id")
+	var viewID *int64
+	if viewIDStr != "" {
+		id, _ := strconv.ParseInt(viewIDStr, 10, 64)
+		viewID = &id
+	}
+
+	records, err := pr.DAO.ListPTRRecords(c.Request.Context(), viewID)
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, records)
+}
+
+func (pr *PTRRecordRouter) Create(c *gin.Context) {
+	var req struct {
+		Record model.Record    `json:"record"`
+		PTR    model.PTRRecord `json:"ptr"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := pr.DAO.CreatePTRRecord(c.Request.Context(), &req.Record, &req.PTR); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, req.PTR)
+}
+
+func (pr *PTRRecordRouter) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := pr.DAO.GetPTRRecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (pr *PTRRecordRouter) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := pr.DAO.GetPTRRecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+
+	if err := pr.DAO.UpdatePTRRecord(c.Request.Context(), &record.Record, record); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (pr *PTRRecordRouter) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	if err := pr.DAO.DeletePTRRecord(c.Request.Context(), uint(id)); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
+
+// Report lists A/AAAA records whose reverse entry is missing or points elsewhere
+func (pr *PTRRecordRouter) Report(c *gin.Context) {
+	report, err := pr.DAO.GetReverseReport(c.Request.Context())
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, report)
+}
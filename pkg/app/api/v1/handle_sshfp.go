@@ -0,0 +1,192 @@
+package v1
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cylonchau/hermes/pkg/app/api/query"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+	"github.com/gin-gonic/gin"
+)
+
+type SSHFPRecordRouter struct {
+	DAO *rdb.RecordDAO
+}
+
+func (sr *SSHFPRecordRouter) List(c *gin.Context) {
+	viewIDStr := c.Query("view_id")
+	var viewID *int64
+	if viewIDStr != "" {
+		id, _ := strconv.ParseInt(viewIDStr, 10, 64)
+		viewID = &id
+	}
+
+	records, err := sr.DAO.ListSSHFPRecords(c.Request.Context(), viewID)
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, records)
+}
+
+func (sr *SSHFPRecordRouter) Create(c *gin.Context) {
+	var req struct {
+		Record model.Record      `json:"record"`
+		SSHFP  model.SSHFPRecord `json:"sshfp"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateSSHFP(&req.SSHFP); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := sr.DAO.CreateSSHFPRecord(c.Request.Context(), &req.Record, &req.SSHFP); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, req.SSHFP)
+}
+
+func (sr *SSHFPRecordRouter) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := sr.DAO.GetSSHFPRecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (sr *SSHFPRecordRouter) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := sr.DAO.GetSSHFPRecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateSSHFP(record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+
+	if err := sr.DAO.UpdateSSHFPRecord(c.Request.Context(), &record.Record, record); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (sr *SSHFPRecordRouter) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	if err := sr.DAO.DeleteSSHFPRecord(c.Request.Context(), uint(id)); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
+
+// Compute 根据上传的SSH公钥计算SHA-1和SHA-256两种SSHFP指纹
+func (sr *SSHFPRecordRouter) Compute(c *gin.Context) {
+	var req struct {
+		PublicKey string `json:"public_key"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	records, err := sshfpFromPublicKey(req.PublicKey)
+	if err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, records)
+}
+
+// sshfpAlgorithms maps SSH key types to SSHFP algorithm numbers (RFC 4255, 6594, 7479, 8709)
+var sshfpAlgorithms = map[string]uint8{
+	"ssh-rsa":             1,
+	"ssh-dss":             2,
+	"ecdsa-sha2-nistp256": 3,
+	"ecdsa-sha2-nistp384": 3,
+	"ecdsa-sha2-nistp521": 3,
+	"ssh-ed25519":         4,
+	"ssh-ed448":           6,
+}
+
+// sshfpDigestLen is the fingerprint length in bytes per fingerprint type
+var sshfpDigestLen = map[uint8]int{
+	1: sha1.Size,
+	2: sha256.Size,
+}
+
+// validateSSHFP checks the algorithm, fingerprint type and digest length
+func validateSSHFP(record *model.SSHFPRecord) error {
+	switch record.Algorithm {
+	case 1, 2, 3, 4, 6:
+	default:
+		return fmt.Errorf("invalid algorithm %d", record.Algorithm)
+	}
+	size, ok := sshfpDigestLen[record.Type]
+	if !ok {
+		return fmt.Errorf("invalid fingerprint type %d, must be 1 (SHA-1) or 2 (SHA-256)", record.Type)
+	}
+	data, err := hex.DecodeString(record.FingerPrint)
+	if err != nil {
+		return fmt.Errorf("fingerprint must be hex")
+	}
+	if len(data) != size {
+		return fmt.Errorf("fingerprint must be %d bytes, got %d", size, len(data))
+	}
+	return nil
+}
+
+// sshfpFromPublicKey computes SSHFP records from a public key in
+// authorized_keys format, e.g. "ssh-ed25519 AAAA... host"
+func sshfpFromPublicKey(key string) ([]model.SSHFPRecord, error) {
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("public key must be in authorized_keys format")
+	}
+	algorithm, ok := sshfpAlgorithms[fields[0]]
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %q", fields[0])
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+
+	// The key blob starts with its own type as a length-prefixed string
+	if len(blob) < 4 {
+		return nil, fmt.Errorf("public key is truncated")
+	}
+	n := binary.BigEndian.Uint32(blob)
+	if uint32(len(blob)-4) < n || !bytes.Equal(blob[4:4+n], []byte(fields[0])) {
+		return nil, fmt.Errorf("public key does not match type %q", fields[0])
+	}
+
+	sum1 := sha1.Sum(blob)
+	sum256 := sha256.Sum256(blob)
+	return []model.SSHFPRecord{
+		{Algorithm: algorithm, Type: 1, FingerPrint: hex.EncodeToString(sum1[:])},
+		{Algorithm: algorithm, Type: 2, FingerPrint: hex.EncodeToString(sum256[:])},
+	}, nil
+}
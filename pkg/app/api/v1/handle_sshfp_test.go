@@ -0,0 +1,76 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// Host keys and their fingerprints as printed by ssh-keygen -r
+const (
+	testEd25519Key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIB0PIYyB6R5VPhv+f4F+j+lSNsOcwa4B8WH7Y/reVIB9 host"
+	testECDSAKey   = "ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBJi1hz/DvZkVweCEgmFYh04vTLf0h/lWG2XcjCnLHd6q0f6W9eRW3rPDbXc7P2ymwr2LpLWSBIRQ489sba4b/HE= host"
+)
+
+func TestValidateSSHFP(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  model.SSHFPRecord
+		wantErr bool
+	}{
+		{"SHA-1", model.SSHFPRecord{Algorithm: 4, Type: 1, FingerPrint: "bc8dda9235ba39f708b4f7931fdf70a1955865a0"}, false},
+		{"SHA-256", model.SSHFPRecord{Algorithm: 4, Type: 2, FingerPrint: "a2ce803348bd2c2352f361d0f4577103d1c0d66759a64fcbc7b9b65273b67661"}, false},
+		{"Ed448", model.SSHFPRecord{Algorithm: 6, Type: 1, FingerPrint: "bc8dda9235ba39f708b4f7931fdf70a1955865a0"}, false},
+		{"Unknown algorithm", model.SSHFPRecord{Algorithm: 5, Type: 1, FingerPrint: "bc8dda9235ba39f708b4f7931fdf70a1955865a0"}, true},
+		{"Unknown fingerprint type", model.SSHFPRecord{Algorithm: 4, Type: 3, FingerPrint: "bc8dda9235ba39f708b4f7931fdf70a1955865a0"}, true},
+		{"SHA-1 as SHA-256", model.SSHFPRecord{Algorithm: 4, Type: 2, FingerPrint: "bc8dda9235ba39f708b4f7931fdf70a1955865a0"}, true},
+		{"Not hex", model.SSHFPRecord{Algorithm: 4, Type: 1, FingerPrint: "not-a-fingerprint"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSSHFP(&tt.record)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSSHFPFromPublicKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		want    []model.SSHFPRecord
+		wantErr bool
+	}{
+		{"Ed25519", testEd25519Key, []model.SSHFPRecord{
+			{Algorithm: 4, Type: 1, FingerPrint: "bc8dda9235ba39f708b4f7931fdf70a1955865a0"},
+			{Algorithm: 4, Type: 2, FingerPrint: "a2ce803348bd2c2352f361d0f4577103d1c0d66759a64fcbc7b9b65273b67661"},
+		}, false},
+		{"ECDSA without comment", testECDSAKey[:len(testECDSAKey)-5], []model.SSHFPRecord{
+			{Algorithm: 3, Type: 1, FingerPrint: "55c12a3b87b25f981783e49934541b892bd4054a"},
+			{Algorithm: 3, Type: 2, FingerPrint: "b6b6eebe65142439742bcc0f0419dfc7d7558e7c3450788db010b609a1c6fd51"},
+		}, false},
+		{"Missing key", "ssh-ed25519", nil, true},
+		{"Unsupported type", "ssh-foo AAAAC3NzaC1lZDI1NTE5AAAAIB0PIYyB6R5VPhv+f4F+j+lSNsOcwa4B8WH7Y/reVIB9", nil, true},
+		{"Invalid base64", "ssh-ed25519 !!!", nil, true},
+		{"Truncated", "ssh-ed25519 AAA=", nil, true},
+		{"Type mismatch", "ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIB0PIYyB6R5VPhv+f4F+j+lSNsOcwa4B8WH7Y/reVIB9", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := sshfpFromPublicKey(tt.key)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, records)
+			}
+		})
+	}
+}
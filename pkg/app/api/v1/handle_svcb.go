@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/cylonchau/hermes/pkg/app/api/query"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+	"github.com/gin-gonic/gin"
+	"github.com/miekg/dns"
+)
+
+type SVCBRecordRouter struct {
+	DAO *rdb.RecordDAO
+}
+
+func (sr *SVCBRecordRouter) List(c *gin.Context) {
+	viewIDStr := c.Query("view_id")
+	var viewID *int64
+	if viewIDStr != "" {
+		id, _ := strconv.ParseInt(viewIDStr, 10, 64)
+		viewID = &id
+	}
+
+	records, err := sr.DAO.ListSVCBRecords(c.Request.Context(), viewID)
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, records)
+}
+
+func (sr *SVCBRecordRouter) Create(c *gin.Context) {
+	var req struct {
+		Record model.Record     `json:"record"`
+		SVCB   model.SVCBRecord `json:"svcb"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateSVCB(req.SVCB.Priority, req.SVCB.Target, req.SVCB.SvcParams); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := sr.DAO.CreateSVCBRecord(c.Request.Context(), &req.Record, &req.SVCB); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, req.SVCB)
+}
+
+func (sr *SVCBRecordRouter) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := sr.DAO.GetSVCBRecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (sr *SVCBRecordRouter) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := sr.DAO.GetSVCBRecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateSVCB(record.Priority, record.Target, record.SvcParams); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+
+	if err := sr.DAO.UpdateSVCBRecord(c.Request.Context(), &record.Record, record); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (sr *SVCBRecordRouter) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	if err := sr.DAO.DeleteSVCBRecord(c.Request.Context(), uint(id)); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
+
+// validateSVCB checks a SVCB/HTTPS record against RFC 9460 before it is stored
+func validateSVCB(priority uint16, target string, params model.SvcParams) error {
+	if target == "" {
+		return fmt.Errorf("target is required")
+	}
+	if _, ok := dns.IsDomainName(target); !ok {
+		return fmt.Errorf("invalid target %q", target)
+	}
+
+	// AliasMode only points at another name, parameters are not allowed
+	if priority == 0 {
+		if params != (model.SvcParams{}) {
+			return fmt.Errorf("alias mode (priority 0) does not take service parameters")
+		}
+		return nil
+	}
+
+	for _, id := range strings.Split(params.ALPN, ",") {
+		id = strings.TrimSpace(id)
+		if params.ALPN != "" && (id == "" || len(id) > 255) {
+			return fmt.Errorf("invalid alpn %q", params.ALPN)
+		}
+	}
+	if params.NoDefaultALPN && params.ALPN == "" {
+		return fmt.Errorf("no_default_alpn requires alpn")
+	}
+	if err := validateHints(params.IPv4Hint, true); err != nil {
+		return err
+	}
+	if err := validateHints(params.IPv6Hint, false); err != nil {
+		return err
+	}
+	if params.ECH != "" {
+		if _, err := base64.StdEncoding.DecodeString(params.ECH); err != nil {
+			return fmt.Errorf("ech must be a base64 encoded ECHConfigList")
+		}
+	}
+	return nil
+}
+
+// validateHints checks a comma separated list of addresses of one family
+func validateHints(hints string, v4 bool) error {
+	if hints == "" {
+		return nil
+	}
+	for _, v := range strings.Split(hints, ",") {
+		ip := net.ParseIP(strings.TrimSpace(v))
+		if ip == nil || (ip.To4() != nil) != v4 {
+			return fmt.Errorf("invalid address hint %q", v)
+		}
+	}
+	return nil
+}
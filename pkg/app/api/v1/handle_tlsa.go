@@ -0,0 +1,191 @@
+package v1
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+
+	"github.com/cylonchau/hermes/pkg/app/api/query"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+	"github.com/gin-gonic/gin"
+	"github.com/miekg/dns"
+)
+
+type TLSARecordRouter struct {
+	DAO *rdb.RecordDAO
+}
+
+func (tr *TLSARecordRouter) List(c *gin.Context) {
+	viewIDStr := c.Query("view_id")
+	var viewID *int64
+	if viewIDStr != "" {
+		id, _ := strconv.ParseInt(viewIDStr, 10, 64)
+		viewID = &id
+	}
+
+	records, err := tr.DAO.ListTLSARecords(c.Request.Context(), viewID)
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, records)
+}
+
+func (tr *TLSARecordRouter) Create(c *gin.Context) {
+	var req struct {
+		Record model.Record     `json:"record"`
+		TLSA   model.TLSARecord `json:"tlsa"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateTLSA(&req.TLSA); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := tr.DAO.CreateTLSARecord(c.Request.Context(), &req.Record, &req.TLSA); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, req.TLSA)
+}
+
+func (tr *TLSARecordRouter) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := tr.DAO.GetTLSARecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (tr *TLSARecordRouter) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	record, err := tr.DAO.GetTLSARecordByID(c.Request.Context(), uint(id))
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateTLSA(record); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+
+	if err := tr.DAO.UpdateTLSARecord(c.Request.Context(), &record.Record, record); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+func (tr *TLSARecordRouter) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseUint(idStr, 10, 64)
+	if err := tr.DAO.DeleteTLSARecord(c.Request.Context(), uint(id)); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
+
+// Compute 根据上传的PEM证书或公钥计算TLSA关联数据
+func (tr *TLSARecordRouter) Compute(c *gin.Context) {
+	var req struct {
+		PEM          string `json:"pem"`
+		Usage        uint8  `json:"usage"`
+		Selector     uint8  `json:"selector"`
+		MatchingType uint8  `json:"matching_type"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	record, err := tlsaFromPEM(req.PEM, req.Usage, req.Selector, req.MatchingType)
+	if err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, record)
+}
+
+// validateTLSA checks the TLSA parameters and the digest length
+func validateTLSA(record *model.TLSARecord) error {
+	if record.Usage > 3 {
+		return fmt.Errorf("invalid usage %d, must be 0-3", record.Usage)
+	}
+	if record.Selector > 1 {
+		return fmt.Errorf("invalid selector %d, must be 0 or 1", record.Selector)
+	}
+
+	data, err := hex.DecodeString(record.Certificate)
+	if err != nil || len(data) == 0 {
+		return fmt.Errorf("certificate must be non-empty hex")
+	}
+	switch record.MatchingType {
+	case 0:
+	case 1:
+		if len(data) != 32 {
+			return fmt.Errorf("SHA-256 digest must be 32 bytes, got %d", len(data))
+		}
+	case 2:
+		if len(data) != 64 {
+			return fmt.Errorf("SHA-512 digest must be 64 bytes, got %d", len(data))
+		}
+	default:
+		return fmt.Errorf("invalid matching type %d, must be 0-2", record.MatchingType)
+	}
+	return nil
+}
+
+// tlsaFromPEM computes TLSA association data from a PEM certificate, or from
+// a PEM public key when only the SubjectPublicKeyInfo is selected
+func tlsaFromPEM(data string, usage, selector, matchingType uint8) (*model.TLSARecord, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	record := &model.TLSARecord{Usage: usage, Selector: selector, MatchingType: matchingType}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if record.Certificate, err = dns.CertificateToDANE(selector, matchingType, cert); err != nil {
+			return nil, err
+		}
+	case "PUBLIC KEY":
+		if selector != 1 {
+			return nil, fmt.Errorf("a public key can only be used with selector 1")
+		}
+		if _, err := x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, err
+		}
+		// SubjectPublicKeyInfo is hashed the same way as in a certificate
+		cert := &x509.Certificate{RawSubjectPublicKeyInfo: block.Bytes}
+		var err error
+		if record.Certificate, err = dns.CertificateToDANE(selector, matchingType, cert); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM type %q", block.Type)
+	}
+
+	if err := validateTLSA(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// Self-signed P-256 certificate for www.example.com and its public key,
+// digests computed with openssl dgst
+const (
+	testCertPEM = `-----BEGIN CERTIFICATE-----
+MIIBizCCATGgAwIBAgIUUFpGmoT8gOWOxyQKepVDtICDOxAwCgYIKoZIzj0EAwIw
+GjEYMBYGA1UEAwwPd3d3LmV4YW1wbGUuY29tMCAXDTI2MTAxODEwMzgzMloYDzIx
+MjYwOTI0MTAzODMyWjAaMRgwFgYDVQQDDA93d3cuZXhhbXBsZS5jb20wWTATBgcq
+hkjOPQIBBggqhkjOPQMBBwNCAARjRm1L/clpdSYkZRqvDZCVjW4jPw2DU9knEvGW
+CmWsJ2VUY8RjATOA+x/NIJ4dKJk/mbU9s0DRCrmSAlue+Typo1MwUTAdBgNVHQ4E
+FgQUVot/aQ0yALVV6yTvy4j4QsoIG5cwHwYDVR0jBBgwFoAUVot/aQ0yALVV6yTv
+y4j4QsoIG5cwDwYDVR0TAQH/BAUwAwEB/zAKBggqhkjOPQQDAgNIADBFAiEAhXSG
+cu6g+EPvKKqYX+8Up/dlEbsy4ecYzqeQWhqaV08CIAtvb28YXh47KBoUlBrZnBG8
+iqfdFW+xCv4jSJHsUVpq
+-----END CERTIFICATE-----
+`
+	testPublicKeyPEM = `-----BEGIN PUBLIC KEY-----
+MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAEY0ZtS/3JaXUmJGUarw2QlY1uIz8N
+g1PZJxLxlgplrCdlVGPEYwEzgPsfzSCeHSiZP5m1PbNA0Qq5kgJbnvk8qQ==
+-----END PUBLIC KEY-----
+`
+	testCertSHA256 = "1eaa92c14af7628b63d5aff00bfd40f3a6b2c0f5636b88d8fa6604b81cdf74e1"
+	testCertSHA512 = "cadfad28fc50d4e8922dee4e9ed2efc6bc89f969ec9050feef7e0d48dcadbe55535beec8155a8dffd3e568ea26b0af9da098d8d0e668097cc19967a3dfe4421d"
+	testSPKISHA256 = "c66619a42055d675cd885a201e9e29b461c3e827b298785d30346d52066a4bbf"
+)
+
+func TestValidateTLSA(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  model.TLSARecord
+		wantErr bool
+	}{
+		{"SHA-256", model.TLSARecord{Usage: 3, Selector: 1, MatchingType: 1, Certificate: testSPKISHA256}, false},
+		{"SHA-512", model.TLSARecord{Usage: 3, Selector: 0, MatchingType: 2, Certificate: testCertSHA512}, false},
+		{"Full data", model.TLSARecord{Usage: 2, Selector: 0, MatchingType: 0, Certificate: "3082018b"}, false},
+		{"Usage out of range", model.TLSARecord{Usage: 4, Selector: 1, MatchingType: 1, Certificate: testSPKISHA256}, true},
+		{"Selector out of range", model.TLSARecord{Usage: 3, Selector: 2, MatchingType: 1, Certificate: testSPKISHA256}, true},
+		{"Matching type out of range", model.TLSARecord{Usage: 3, Selector: 1, MatchingType: 3, Certificate: testSPKISHA256}, true},
+		{"Short SHA-256", model.TLSARecord{Usage: 3, Selector: 1, MatchingType: 1, Certificate: testSPKISHA256[:62]}, true},
+		{"SHA-256 as SHA-512", model.TLSARecord{Usage: 3, Selector: 1, MatchingType: 2, Certificate: testSPKISHA256}, true},
+		{"Not hex", model.TLSARecord{Usage: 3, Selector: 1, MatchingType: 0, Certificate: "zz"}, true},
+		{"Empty", model.TLSARecord{Usage: 3, Selector: 1, MatchingType: 0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTLSA(&tt.record)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTLSAFromPEM(t *testing.T) {
+	tests := []struct {
+		name         string
+		pem          string
+		selector     uint8
+		matchingType uint8
+		want         string
+		wantErr      bool
+	}{
+		{"Certificate SHA-256", testCertPEM, 0, 1, testCertSHA256, false},
+		{"Certificate SHA-512", testCertPEM, 0, 2, testCertSHA512, false},
+		{"Certificate public key SHA-256", testCertPEM, 1, 1, testSPKISHA256, false},
+		{"Public key SHA-256", testPublicKeyPEM, 1, 1, testSPKISHA256, false},
+		{"Public key needs selector 1", testPublicKeyPEM, 0, 1, "", true},
+		{"Unsupported PEM type", strings.ReplaceAll(testPublicKeyPEM, "PUBLIC KEY", "PRIVATE KEY"), 1, 1, "", true},
+		{"No PEM data", "not a certificate", 0, 1, "", true},
+		{"Invalid matching type", testCertPEM, 0, 3, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := tlsaFromPEM(tt.pem, 3, tt.selector, tt.matchingType)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, record.Certificate)
+				assert.Equal(t, uint8(3), record.Usage)
+				assert.Equal(t, tt.selector, record.Selector)
+				assert.Equal(t, tt.matchingType, record.MatchingType)
+			}
+		})
+	}
+
+	// Matching type 0 carries the DER certificate itself
+	record, err := tlsaFromPEM(testCertPEM, 3, 0, 0)
+	if assert.NoError(t, err) {
+		assert.True(t, strings.HasPrefix(record.Certificate, "3082018b"))
+	}
+}
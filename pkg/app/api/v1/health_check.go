@@ -0,0 +1,151 @@
+package v1
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/cylonchau/hermes/pkg/app/api/query"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// defaultHistoryLimit is the number of state changes returned by History
+const defaultHistoryLimit = 100
+
+type HealthCheckRouter struct {
+	DAO *rdb.HealthCheckDAO
+}
+
+func (hr *HealthCheckRouter) List(c *gin.Context) {
+	checks, err := hr.DAO.GetAll(c.Request.Context())
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, checks)
+}
+
+func (hr *HealthCheckRouter) Create(c *gin.Context) {
+	var check model.HealthCheck
+	if err := c.ShouldBindJSON(&check); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validateHealthCheck(&check); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := hr.DAO.Create(c.Request.Context(), &check); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, check)
+}
+
+func (hr *HealthCheckRouter) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	check, err := hr.DAO.GetByID(c.Request.Context(), id)
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+	query.SuccessResponse(c, nil, check)
+}
+
+func (hr *HealthCheckRouter) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	check, err := hr.DAO.GetByID(c.Request.Context(), id)
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&check); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	check.ID = id
+	if err := validateHealthCheck(check); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+
+	if err := hr.DAO.Update(c.Request.Context(), check); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, check)
+}
+
+func (hr *HealthCheckRouter) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	if err := hr.DAO.Delete(c.Request.Context(), id); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
+
+func (hr *HealthCheckRouter) Status(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	statuses, err := hr.DAO.ListStatus(c.Request.Context(), id)
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, statuses)
+}
+
+func (hr *HealthCheckRouter) History(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	events, err := hr.DAO.ListEvents(c.Request.Context(), id, limit)
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, events)
+}
+
+// validateHealthCheck checks the probe settings and the fallback addresses
+func validateHealthCheck(check *model.HealthCheck) error {
+	if check.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch check.Protocol {
+	case "":
+		check.Protocol = model.HealthCheckTCP
+	case model.HealthCheckTCP, model.HealthCheckHTTP, model.HealthCheckDNS:
+	default:
+		return fmt.Errorf("unsupported protocol %q", check.Protocol)
+	}
+	if check.Port <= 0 || check.Port > 65535 {
+		return fmt.Errorf("invalid port %d", check.Port)
+	}
+	if check.Interval < 0 || check.Timeout < 0 || check.Rise < 0 || check.Fall < 0 {
+		return fmt.Errorf("interval, timeout, rise and fall must not be negative")
+	}
+	if check.ExpectStatus != 0 && (check.ExpectStatus < 100 || check.ExpectStatus > 599) {
+		return fmt.Errorf("invalid expect_status %d", check.ExpectStatus)
+	}
+	if check.Fallback != "" {
+		for _, part := range strings.Split(check.Fallback, ",") {
+			if net.ParseIP(strings.TrimSpace(part)) == nil {
+				return fmt.Errorf("invalid fallback address %q", part)
+			}
+		}
+	}
+	return nil
+}
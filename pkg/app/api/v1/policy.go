@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/miekg/dns"
+
+	"github.com/cylonchau/hermes/pkg/app/api/query"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+type PolicyRouter struct {
+	DAO *rdb.RRSetPolicyDAO
+}
+
+func (pr *PolicyRouter) List(c *gin.Context) {
+	zoneID, _ := strconv.ParseInt(c.Query("zone_id"), 10, 64)
+	policies, err := pr.DAO.GetAll(c.Request.Context(), zoneID)
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, policies)
+}
+
+func (pr *PolicyRouter) Create(c *gin.Context) {
+	var policy model.RRSetPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validatePolicy(&policy); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := pr.DAO.Create(c.Request.Context(), &policy); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, policy)
+}
+
+func (pr *PolicyRouter) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	policy, err := pr.DAO.GetByID(c.Request.Context(), id)
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+	query.SuccessResponse(c, nil, policy)
+}
+
+func (pr *PolicyRouter) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	policy, err := pr.DAO.GetByID(c.Request.Context(), id)
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	policy.ID = id
+	if err := validatePolicy(policy); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+
+	if err := pr.DAO.Update(c.Request.Context(), policy); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, policy)
+}
+
+func (pr *PolicyRouter) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	if err := pr.DAO.Delete(c.Request.Context(), id); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
+
+// validatePolicy checks the policy name and the RRset it applies to
+func validatePolicy(policy *model.RRSetPolicy) error {
+	switch policy.Policy {
+	case "":
+		policy.Policy = model.PolicyAll
+	case model.PolicyAll, model.PolicyRoundRobin, model.PolicyWeighted, model.PolicyFixed, model.PolicyNearest:
+	default:
+		return fmt.Errorf("unsupported policy %q", policy.Policy)
+	}
+	policy.Type = strings.ToUpper(policy.Type)
+	if policy.Type != "A" && policy.Type != "AAAA" {
+		return fmt.Errorf("policies apply to A or AAAA record sets, got %q", policy.Type)
+	}
+	if _, ok := dns.IsDomainName(policy.Name); !ok || policy.Name == "" {
+		return fmt.Errorf("invalid name %q", policy.Name)
+	}
+	if policy.Count < 0 {
+		return fmt.Errorf("invalid count %d", policy.Count)
+	}
+	return nil
+}
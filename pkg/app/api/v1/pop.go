@@ -0,0 +1,136 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/app/api/query"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+type PoPRouter struct {
+	DAO *rdb.PoPDAO
+}
+
+func (pr *PoPRouter) List(c *gin.Context) {
+	pops, err := pr.DAO.GetAll(c.Request.Context())
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, pops)
+}
+
+func (pr *PoPRouter) Create(c *gin.Context) {
+	var pop model.PoP
+	if err := c.ShouldBindJSON(&pop); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := validatePoP(&pop); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := pr.DAO.Create(c.Request.Context(), &pop); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, pop)
+}
+
+func (pr *PoPRouter) Get(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	pop, err := pr.DAO.GetByID(c.Request.Context(), id)
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+	query.SuccessResponse(c, nil, pop)
+}
+
+func (pr *PoPRouter) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	pop, err := pr.DAO.GetByID(c.Request.Context(), id)
+	if err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	if err := c.ShouldBindJSON(&pop); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	pop.ID = id
+	if err := validatePoP(pop); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+
+	if err := pr.DAO.Update(c.Request.Context(), pop); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, pop)
+}
+
+func (pr *PoPRouter) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	if err := pr.DAO.Delete(c.Request.Context(), id); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
+
+// validatePoP checks the PoP code and its coordinates
+func validatePoP(pop *model.PoP) error {
+	pop.Code = strings.ToLower(strings.TrimSpace(pop.Code))
+	if pop.Code == "" {
+		return fmt.Errorf("code is required")
+	}
+	return validateCoordinates(&pop.Latitude, &pop.Longitude)
+}
+
+// checkRecordPoP lowercases the PoP code of an address record and answers the
+// request with an error when that PoP does not exist
+func checkRecordPoP(c *gin.Context, pops *rdb.PoPDAO, code *string) bool {
+	*code = strings.ToLower(strings.TrimSpace(*code))
+	if *code == "" {
+		return true
+	}
+	if _, err := pops.GetByCode(c.Request.Context(), *code); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			query.BadRequest(c, fmt.Errorf("unknown pop %q", *code))
+		} else {
+			query.InternalError(c, err)
+		}
+		return false
+	}
+	return true
+}
+
+// validateCoordinates checks that latitude and longitude are both set and in range
+func validateCoordinates(latitude, longitude *float64) error {
+	if latitude == nil && longitude == nil {
+		return nil
+	}
+	if latitude == nil || longitude == nil {
+		return fmt.Errorf("latitude and longitude must be set together")
+	}
+	if *latitude < -90 || *latitude > 90 {
+		return fmt.Errorf("invalid latitude %v", *latitude)
+	}
+	if *longitude < -180 || *longitude > 180 {
+		return fmt.Errorf("invalid longitude %v", *longitude)
+	}
+	return nil
+}
@@ -1,12 +1,16 @@
 package v1
 
 import (
+	"fmt"
+	"net/netip"
 	"strconv"
+	"strings"
 
 	"github.com/cylonchau/hermes/pkg/app/api/query"
 	"github.com/cylonchau/hermes/pkg/dao/rdb"
 	"github.com/cylonchau/hermes/pkg/model"
 	"github.com/gin-gonic/gin"
+	"github.com/miekg/dns"
 )
 
 type ViewRouter struct {
@@ -28,6 +32,14 @@ func (vr *ViewRouter) Create(c *gin.Context) {
 		query.BadRequest(c, err)
 		return
 	}
+	if err := validateView(&view); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := vr.DAO.CheckParent(c.Request.Context(), 0, view.ParentID); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
 	if err := vr.DAO.Create(c.Request.Context(), &view); err != nil {
 		query.InternalError(c, err)
 		return
@@ -55,10 +67,24 @@ func (vr *ViewRouter) Update(c *gin.Context) {
 		return
 	}
 
+	// Rules are replaced as a whole, and kept when the body has none
+	rules := view.Rules
+	view.Rules = nil
 	if err := c.ShouldBindJSON(&view); err != nil {
 		query.BadRequest(c, err)
 		return
 	}
+	if view.Rules == nil {
+		view.Rules = rules
+	}
+	if err := validateView(view); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := vr.DAO.CheckParent(c.Request.Context(), view.ID, view.ParentID); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
 
 	if err := vr.DAO.Update(c.Request.Context(), view); err != nil {
 		query.InternalError(c, err)
@@ -76,3 +102,182 @@ func (vr *ViewRouter) Delete(c *gin.Context) {
 	}
 	query.SuccessResponse(c, nil, nil)
 }
+
+func (vr *ViewRouter) ListDenies(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	denies, err := vr.DAO.GetDenies(c.Request.Context(), id)
+	if err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, denies)
+}
+
+func (vr *ViewRouter) CreateDeny(c *gin.Context) {
+	idStr := c.Param("id")
+	id, _ := strconv.ParseInt(idStr, 10, 64)
+	if _, err := vr.DAO.GetByID(c.Request.Context(), id); err != nil {
+		query.NotFound(c, query.ErrParam)
+		return
+	}
+
+	var deny model.ViewDeny
+	if err := c.ShouldBindJSON(&deny); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	deny.ViewID = id
+	if err := validateViewDeny(&deny); err != nil {
+		query.BadRequest(c, err)
+		return
+	}
+	if err := vr.DAO.CreateDeny(c.Request.Context(), &deny); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, deny)
+}
+
+func (vr *ViewRouter) DeleteDeny(c *gin.Context) {
+	id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+	denyID, _ := strconv.ParseInt(c.Param("deny_id"), 10, 64)
+	if err := vr.DAO.DeleteDeny(c.Request.Context(), id, denyID); err != nil {
+		query.InternalError(c, err)
+		return
+	}
+	query.SuccessResponse(c, nil, nil)
+}
+
+// validateView checks the view and its rules. Views are tried from the
+// highest priority down, ties going to the lower ID, and the first view whose
+// rules hold is used. A legacy category and value is converted to rules.
+func validateView(view *model.View) error {
+	if strings.TrimSpace(view.Name) == "" {
+		return fmt.Errorf("view name is required")
+	}
+	if view.Priority < model.ViewPriorityMin || view.Priority > model.ViewPriorityMax {
+		return fmt.Errorf("priority must be between %d and %d, higher is matched first",
+			model.ViewPriorityMin, model.ViewPriorityMax)
+	}
+	switch view.Match {
+	case "":
+		view.Match = model.ViewMatchAll
+	case model.ViewMatchAll, model.ViewMatchAny:
+	default:
+		return fmt.Errorf("unsupported match %q, use all or any", view.Match)
+	}
+
+	if strings.TrimSpace(view.Value) != "" {
+		if len(view.Rules) > 0 {
+			return fmt.Errorf("category and value are deprecated and cannot be combined with rules")
+		}
+		if view.Category != "acl" && view.Category != "geoip" {
+			return fmt.Errorf("unsupported category %q", view.Category)
+		}
+		view.Match, view.Rules = view.LegacyRules()
+		view.Value = ""
+	}
+
+	for i := range view.Rules {
+		if err := validateViewRule(&view.Rules[i]); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// validateViewRule checks the type and every value of a rule
+func validateViewRule(rule *model.ViewRule) error {
+	switch rule.Type {
+	case model.ViewRuleCIDR, model.ViewRuleContinent, model.ViewRuleCountry, model.ViewRuleRegion,
+		model.ViewRuleASN, model.ViewRuleOrg, model.ViewRuleECS, model.ViewRuleTransport, model.ViewRuleListener,
+		model.ViewRuleACL, model.ViewRuleGeoIP:
+	default:
+		return fmt.Errorf("unsupported rule type %q", rule.Type)
+	}
+	values := rule.Values()
+	if len(values) == 0 && rule.Type != model.ViewRuleECS {
+		return fmt.Errorf("%s rule needs a value", rule.Type)
+	}
+	for _, v := range values {
+		var ok bool
+		switch rule.Type {
+		case model.ViewRuleCIDR, model.ViewRuleECS, model.ViewRuleListener:
+			ok = validAddressOrPrefix(v)
+		case model.ViewRuleContinent:
+			switch strings.ToUpper(v) {
+			case "AF", "AN", "AS", "EU", "NA", "OC", "SA":
+				ok = true
+			}
+		case model.ViewRuleCountry:
+			ok = len(v) == 2 && isAlnum(v)
+		case model.ViewRuleRegion:
+			// Codes from MaxMind, names from CSV range files
+			ok = isAlnum(strings.NewReplacer("-", "", " ", "").Replace(v))
+		case model.ViewRuleASN:
+			asn := v
+			if len(asn) > 2 && strings.EqualFold(asn[:2], "AS") {
+				asn = asn[2:]
+			}
+			n, err := strconv.ParseUint(asn, 10, 32)
+			ok = err == nil && n > 0
+		case model.ViewRuleACL:
+			// Legacy ACLs never matched bare addresses
+			_, err := netip.ParsePrefix(v)
+			ok = err == nil
+		case model.ViewRuleOrg, model.ViewRuleGeoIP:
+			ok = true
+		case model.ViewRuleTransport:
+			ok = strings.EqualFold(v, "udp") || strings.EqualFold(v, "tcp")
+		}
+		if !ok {
+			return fmt.Errorf("invalid %s value %q", rule.Type, v)
+		}
+	}
+	return nil
+}
+
+// validAddressOrPrefix reports whether s is an IP address or a CIDR
+func validAddressOrPrefix(s string) bool {
+	if strings.Contains(s, "/") {
+		_, err := netip.ParsePrefix(s)
+		return err == nil
+	}
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
+
+// isAlnum reports whether s is a non-empty string of ASCII letters and digits
+func isAlnum(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// validateViewDeny checks the name and type hidden by a deny entry, an empty
+// type hides every type of the name
+func validateViewDeny(deny *model.ViewDeny) error {
+	if deny.ZoneID <= 0 {
+		return fmt.Errorf("zone_id is required")
+	}
+	deny.Name = strings.TrimSpace(deny.Name)
+	if _, ok := dns.IsDomainName(deny.Name); !ok || deny.Name == "" {
+		return fmt.Errorf("invalid name %q", deny.Name)
+	}
+	// Matched against query names, which are lower case and fully qualified
+	deny.Name = strings.ToLower(dns.Fqdn(deny.Name))
+	deny.Type = strings.ToUpper(strings.TrimSpace(deny.Type))
+	if deny.Type != "" && deny.Type != "ALIAS" {
+		if _, ok := dns.StringToType[deny.Type]; !ok {
+			return fmt.Errorf("unsupported type %q", deny.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestValidateViewDeny(t *testing.T) {
+	tests := []struct {
+		name     string
+		deny     model.ViewDeny
+		wantName string
+		wantType string
+		wantErr  bool
+	}{
+		{"Fully qualified", model.ViewDeny{ZoneID: 1, Name: "www.test.com.", Type: "A"}, "www.test.com.", "A", false},
+		{"Mixed case without root", model.ViewDeny{ZoneID: 1, Name: " WWW.Test.com ", Type: "aaaa"}, "www.test.com.", "AAAA", false},
+		{"All types", model.ViewDeny{ZoneID: 1, Name: "Mail.test.com"}, "mail.test.com.", "", false},
+		{"ALIAS", model.ViewDeny{ZoneID: 1, Name: "test.com.", Type: "alias"}, "test.com.", "ALIAS", false},
+		{"Missing zone", model.ViewDeny{Name: "www.test.com."}, "", "", true},
+		{"Missing name", model.ViewDeny{ZoneID: 1, Name: " "}, "", "", true},
+		{"Invalid name", model.ViewDeny{ZoneID: 1, Name: "www..test.com."}, "", "", true},
+		{"Unknown type", model.ViewDeny{ZoneID: 1, Name: "www.test.com.", Type: "FOO"}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateViewDeny(&tt.deny)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantName, tt.deny.Name)
+				assert.Equal(t, tt.wantType, tt.deny.Type)
+			}
+		})
+	}
+}
+
+func TestValidateView_Legacy(t *testing.T) {
+	tests := []struct {
+		name     string
+		view     model.View
+		wantRule model.ViewRule
+		wantErr  bool
+	}{
+		{"ACL", model.View{Name: "office", Category: "acl", Value: "10.0.0.0/8"}, model.ViewRule{Type: "acl", Value: "10.0.0.0/8"}, false},
+		{"GeoIP kept as written", model.View{Name: "gd", Category: "geoip", Value: "CN-GD"}, model.ViewRule{Type: "geoip", Value: "CN-GD"}, false},
+		{"ACL host", model.View{Name: "host", Category: "acl", Value: "10.0.0.1"}, model.ViewRule{}, true},
+		{"Unknown category", model.View{Name: "x", Category: "asn", Value: "4134"}, model.ViewRule{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateView(&tt.view)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) && assert.Len(t, tt.view.Rules, 1) {
+				assert.Equal(t, tt.wantRule.Type, tt.view.Rules[0].Type)
+				assert.Equal(t, tt.wantRule.Value, tt.view.Rules[0].Value)
+				assert.Empty(t, tt.view.Value)
+			}
+		})
+	}
+}
@@ -17,6 +17,10 @@ func RegisteredRouter(e *gin.Engine) {
 	zoneDAO := rdb.NewZoneDAO(model.DB)
 	recordDAO := rdb.NewRecordDAO(model.DB)
 	viewDAO := rdb.NewViewDAO(model.DB)
+	policyDAO := rdb.NewRRSetPolicyDAO(model.DB)
+	healthCheckDAO := rdb.NewHealthCheckDAO(model.DB)
+	popDAO := rdb.NewPoPDAO(model.DB)
+	geoipOverrideDAO := rdb.NewGeoIPOverrideDAO(model.DB)
 
 	// API V1 Group
 	v1Group := e.Group("/api/v1")
@@ -50,10 +54,55 @@ func RegisteredRouter(e *gin.Engine) {
 			viewGroup.GET("/:id", viewH.Get)
 			viewGroup.PUT("/:id", viewH.Update)
 			viewGroup.DELETE("/:id", viewH.Delete)
+			viewGroup.GET("/:id/denies", viewH.ListDenies)
+			viewGroup.POST("/:id/denies", viewH.CreateDeny)
+			viewGroup.DELETE("/:id/denies/:deny_id", viewH.DeleteDeny)
+		}
+
+		policyH := &v1.PolicyRouter{DAO: policyDAO}
+		policyGroup := v1Group.Group("/policies")
+		{
+			policyGroup.GET("", policyH.List)
+			policyGroup.POST("", policyH.Create)
+			policyGroup.GET("/:id", policyH.Get)
+			policyGroup.PUT("/:id", policyH.Update)
+			policyGroup.DELETE("/:id", policyH.Delete)
+		}
+
+		popH := &v1.PoPRouter{DAO: popDAO}
+		popGroup := v1Group.Group("/pops")
+		{
+			popGroup.GET("", popH.List)
+			popGroup.POST("", popH.Create)
+			popGroup.GET("/:id", popH.Get)
+			popGroup.PUT("/:id", popH.Update)
+			popGroup.DELETE("/:id", popH.Delete)
+		}
+
+		geoipOverrideH := &v1.GeoIPOverrideRouter{DAO: geoipOverrideDAO}
+		geoipOverrideGroup := v1Group.Group("/geoip/overrides")
+		{
+			geoipOverrideGroup.GET("", geoipOverrideH.List)
+			geoipOverrideGroup.POST("", geoipOverrideH.Create)
+			geoipOverrideGroup.GET("/:id", geoipOverrideH.Get)
+			geoipOverrideGroup.PUT("/:id", geoipOverrideH.Update)
+			geoipOverrideGroup.DELETE("/:id", geoipOverrideH.Delete)
+		}
+
+		healthCheckH := &v1.HealthCheckRouter{DAO: healthCheckDAO}
+		healthCheckGroup := v1Group.Group("/healthchecks")
+		{
+			healthCheckGroup.GET("", healthCheckH.List)
+			healthCheckGroup.POST("", healthCheckH.Create)
+			healthCheckGroup.GET("/:id", healthCheckH.Get)
+			healthCheckGroup.PUT("/:id", healthCheckH.Update)
+			healthCheckGroup.DELETE("/:id", healthCheckH.Delete)
+			healthCheckGroup.GET("/:id/status", healthCheckH.Status)
+			healthCheckGroup.GET("/:id/history", healthCheckH.History)
 		}
 
 		// Specific Record Types
-		aH := &v1.ARecordRouter{DAO: recordDAO}
+		aH := &v1.ARecordRouter{DAO: recordDAO, PoPs: popDAO}
 		aGroup := v1Group.Group("/records/a")
 		{
 			aGroup.GET("", aH.List)
@@ -63,7 +112,7 @@ func RegisteredRouter(e *gin.Engine) {
 			aGroup.DELETE("/:id", aH.Delete)
 		}
 
-		aaaaH := &v1.AAAARecordRouter{DAO: recordDAO}
+		aaaaH := &v1.AAAARecordRouter{DAO: recordDAO, PoPs: popDAO}
 		aaaaGroup := v1Group.Group("/records/aaaa")
 		{
 			aaaaGroup.GET("", aaaaH.List)
@@ -142,5 +191,88 @@ func RegisteredRouter(e *gin.Engine) {
 			caaGroup.PUT("/:id", caaH.Update)
 			caaGroup.DELETE("/:id", caaH.Delete)
 		}
+
+		ptrH := &v1.PTRRecordRouter{DAO: recordDAO}
+		ptrGroup := v1Group.Group("/records/ptr")
+		{
+			ptrGroup.GET("", ptrH.List)
+			ptrGroup.POST("", ptrH.Create)
+			ptrGroup.GET("/report", ptrH.Report)
+			ptrGroup.GET("/:id", ptrH.Get)
+			ptrGroup.PUT("/:id", ptrH.Update)
+			ptrGroup.DELETE("/:id", ptrH.Delete)
+		}
+
+		svcbH := &v1.SVCBRecordRouter{DAO: recordDAO}
+		svcbGroup := v1Group.Group("/records/svcb")
+		{
+			svcbGroup.GET("", svcbH.List)
+			svcbGroup.POST("", svcbH.Create)
+			svcbGroup.GET("/:id", svcbH.Get)
+			svcbGroup.PUT("/:id", svcbH.Update)
+			svcbGroup.DELETE("/:id", svcbH.Delete)
+		}
+
+		httpsH := &v1.HTTPSRecordRouter{DAO: recordDAO}
+		httpsGroup := v1Group.Group("/records/https")
+		{
+			httpsGroup.GET("", httpsH.List)
+			httpsGroup.POST("", httpsH.Create)
+			httpsGroup.GET("/:id", httpsH.Get)
+			httpsGroup.PUT("/:id", httpsH.Update)
+			httpsGroup.DELETE("/:id", httpsH.Delete)
+		}
+
+		tlsaH := &v1.TLSARecordRouter{DAO: recordDAO}
+		tlsaGroup := v1Group.Group("/records/tlsa")
+		{
+			tlsaGroup.GET("", tlsaH.List)
+			tlsaGroup.POST("", tlsaH.Create)
+			tlsaGroup.POST("/compute", tlsaH.Compute)
+			tlsaGroup.GET("/:id", tlsaH.Get)
+			tlsaGroup.PUT("/:id", tlsaH.Update)
+			tlsaGroup.DELETE("/:id", tlsaH.Delete)
+		}
+
+		sshfpH := &v1.SSHFPRecordRouter{DAO: recordDAO}
+		sshfpGroup := v1Group.Group("/records/sshfp")
+		{
+			sshfpGroup.GET("", sshfpH.List)
+			sshfpGroup.POST("", sshfpH.Create)
+			sshfpGroup.POST("/compute", sshfpH.Compute)
+			sshfpGroup.GET("/:id", sshfpH.Get)
+			sshfpGroup.PUT("/:id", sshfpH.Update)
+			sshfpGroup.DELETE("/:id", sshfpH.Delete)
+		}
+
+		genericH := &v1.GenericRecordRouter{DAO: recordDAO}
+		genericGroup := v1Group.Group("/records/generic")
+		{
+			genericGroup.GET("", genericH.List)
+			genericGroup.POST("", genericH.Create)
+			genericGroup.GET("/:id", genericH.Get)
+			genericGroup.PUT("/:id", genericH.Update)
+			genericGroup.DELETE("/:id", genericH.Delete)
+		}
+
+		dnameH := &v1.DNAMERecordRouter{DAO: recordDAO}
+		dnameGroup := v1Group.Group("/records/dname")
+		{
+			dnameGroup.GET("", dnameH.List)
+			dnameGroup.POST("", dnameH.Create)
+			dnameGroup.GET("/:id", dnameH.Get)
+			dnameGroup.PUT("/:id", dnameH.Update)
+			dnameGroup.DELETE("/:id", dnameH.Delete)
+		}
+
+		aliasH := &v1.ALIASRecordRouter{DAO: recordDAO}
+		aliasGroup := v1Group.Group("/records/alias")
+		{
+			aliasGroup.GET("", aliasH.List)
+			aliasGroup.POST("", aliasH.Create)
+			aliasGroup.GET("/:id", aliasH.Get)
+			aliasGroup.PUT("/:id", aliasH.Update)
+			aliasGroup.DELETE("/:id", aliasH.Delete)
+		}
 	}
 }
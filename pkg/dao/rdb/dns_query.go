@@ -2,8 +2,12 @@ package rdb
 
 import (
 	"context"
+	"encoding/binary"
+	"net"
+	"strings"
 
 	"github.com/cylonchau/hermes/pkg/model"
+	"github.com/miekg/dns"
 	"gorm.io/gorm"
 )
 
@@ -16,7 +20,19 @@ type DNSQueryRepository interface {
 	QuerySOARecord(ctx context.Context, zoneName string, viewID int64) (*model.SOARecord, error)
 	QueryNSRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.NSRecord, error)
 	QueryCNAMERecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CNAMERecord, error)
+	QueryDNAMERecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.DNAMERecord, error)
+	QueryALIASRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ALIASRecord, error)
 	QuerySRVRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SRVRecord, error)
+	QueryCAARecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CAARecord, error)
+	QueryPTRRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.PTRRecord, error)
+	QuerySVCBRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SVCBRecord, error)
+	QueryHTTPSRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.HTTPSRecord, error)
+	QueryTLSARecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.TLSARecord, error)
+	QuerySSHFPRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SSHFPRecord, error)
+	QueryGenericRecords(ctx context.Context, zoneName, recordName string, rrType uint16, viewID int64) ([]*model.GenericRecord, error)
+	QueryAutoPTRRecords(ctx context.Context, ip net.IP, viewID int64) ([]*model.PTRRecord, error)
+	QueryRRSetPolicy(ctx context.Context, zoneName, recordName string, qType uint16, viewID int64) (*model.RRSetPolicy, error)
+	NameExists(ctx context.Context, zoneName, recordName string, viewID int64) (bool, error)
 }
 
 // ========== RecordDAO 实现 DNSQueryRepository 接口 ==========
@@ -26,24 +42,24 @@ func (dao *RecordDAO) QueryARecords(ctx context.Context, zoneName, recordName st
 	var aRecords []*model.ARecord
 	baseQuery := dao.db.WithContext(ctx).
 		Model(&model.ARecord{}).
-		Select("`record_a`.*, `record`.ttl").
+		Select("`record_a`.*, `record`.ttl, "+
+			"COALESCE(`record_a`.latitude, `pop`.latitude) AS latitude, COALESCE(`record_a`.longitude, `pop`.longitude) AS longitude").
 		Joins("JOIN `record` ON `record`.id = `record_a`.record_id").
 		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Joins("LEFT JOIN `pop` ON `pop`.code = `record_a`.pop AND `record_a`.pop <> ''").
 		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
 			zoneName, recordName)
 
-	if viewID > 0 {
-		err := baseQuery.Session(&gorm.Session{}).Where("`record`.view_id = ?", viewID).Scan(&aRecords).Error
-		if err != nil {
-			return nil, err
-		}
-		if len(aRecords) > 0 {
-			return aRecords, nil
-		}
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "A", viewID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 回退到默认视图
-	err := baseQuery.Session(&gorm.Session{}).Where("(`record`.view_id IS NULL OR `record`.view_id = 0)").Scan(&aRecords).Error
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Scan(&aRecords).Error
+		return len(aRecords) > 0, err
+	})
 	return aRecords, err
 }
 
@@ -52,24 +68,24 @@ func (dao *RecordDAO) QueryAAAARecords(ctx context.Context, zoneName, recordName
 	var aaaaRecords []*model.AAAARecord
 	baseQuery := dao.db.WithContext(ctx).
 		Model(&model.AAAARecord{}).
-		Select("`record_aaaa`.*, `record`.ttl").
+		Select("`record_aaaa`.*, `record`.ttl, "+
+			"COALESCE(`record_aaaa`.latitude, `pop`.latitude) AS latitude, COALESCE(`record_aaaa`.longitude, `pop`.longitude) AS longitude").
 		Joins("JOIN `record` ON `record`.id = `record_aaaa`.record_id").
 		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Joins("LEFT JOIN `pop` ON `pop`.code = `record_aaaa`.pop AND `record_aaaa`.pop <> ''").
 		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
 			zoneName, recordName)
 
-	if viewID > 0 {
-		err := baseQuery.Session(&gorm.Session{}).Where("`record`.view_id = ?", viewID).Scan(&aaaaRecords).Error
-		if err != nil {
-			return nil, err
-		}
-		if len(aaaaRecords) > 0 {
-			return aaaaRecords, nil
-		}
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "AAAA", viewID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 回退到默认视图
-	err := baseQuery.Session(&gorm.Session{}).Where("(`record`.view_id IS NULL OR `record`.view_id = 0)").Scan(&aaaaRecords).Error
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Scan(&aaaaRecords).Error
+		return len(aaaaRecords) > 0, err
+	})
 	return aaaaRecords, err
 }
 
@@ -84,18 +100,16 @@ func (dao *RecordDAO) QueryMXRecords(ctx context.Context, zoneName, recordName s
 		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
 			zoneName, recordName)
 
-	if viewID > 0 {
-		err := baseQuery.Session(&gorm.Session{}).Where("`record`.view_id = ?", viewID).Order("`record_mx`.priority ASC").Scan(&mxRecords).Error
-		if err != nil {
-			return nil, err
-		}
-		if len(mxRecords) > 0 {
-			return mxRecords, nil
-		}
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "MX", viewID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 回退到默认视图
-	err := baseQuery.Session(&gorm.Session{}).Where("(`record`.view_id IS NULL OR `record`.view_id = 0)").Order("`record_mx`.priority ASC").Scan(&mxRecords).Error
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Order("`record_mx`.priority ASC").Scan(&mxRecords).Error
+		return len(mxRecords) > 0, err
+	})
 	return mxRecords, err
 }
 
@@ -110,18 +124,16 @@ func (dao *RecordDAO) QueryTXTRecords(ctx context.Context, zoneName, recordName
 		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
 			zoneName, recordName)
 
-	if viewID > 0 {
-		err := baseQuery.Session(&gorm.Session{}).Where("`record`.view_id = ?", viewID).Scan(&txtRecords).Error
-		if err != nil {
-			return nil, err
-		}
-		if len(txtRecords) > 0 {
-			return txtRecords, nil
-		}
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "TXT", viewID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 回退到默认视图
-	err := baseQuery.Session(&gorm.Session{}).Where("(`record`.view_id IS NULL OR `record`.view_id = 0)").Scan(&txtRecords).Error
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Scan(&txtRecords).Error
+		return len(txtRecords) > 0, err
+	})
 	return txtRecords, err
 }
 
@@ -136,18 +148,25 @@ func (dao *RecordDAO) QuerySOARecord(ctx context.Context, zoneName string, viewI
 		Where("`zone`.name = ? AND `record`.name IN (?, '@') AND `zone`.is_active = 1 AND `record`.is_active = 1",
 			zoneName, zoneName)
 
-	if viewID > 0 {
-		err := baseQuery.Session(&gorm.Session{}).Where("`record`.view_id = ?", viewID).Order("`record_soa`.id ASC").Scan(&soaRecord).Error
-		if err == nil {
-			return &soaRecord, nil
-		}
+	chain, err := dao.viewChain(ctx, zoneName, zoneName, "SOA", viewID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 回退到默认视图
-	err := baseQuery.Session(&gorm.Session{}).Where("(`record`.view_id IS NULL OR `record`.view_id = 0)").Order("`record_soa`.id ASC").Scan(&soaRecord).Error
+	// 沿视图链回退，最终回退到默认视图
+	var found bool
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		result := tx.Order("`record_soa`.id ASC").Scan(&soaRecord)
+		found = result.RowsAffected > 0
+		return found, result.Error
+	})
 	if err != nil {
 		return nil, err
 	}
+	// 不存在SOA记录
+	if !found {
+		return nil, nil
+	}
 	return &soaRecord, nil
 }
 
@@ -162,18 +181,16 @@ func (dao *RecordDAO) QueryNSRecords(ctx context.Context, zoneName, recordName s
 		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
 			zoneName, recordName)
 
-	if viewID > 0 {
-		err := baseQuery.Session(&gorm.Session{}).Where("`record`.view_id = ?", viewID).Scan(&nsRecords).Error
-		if err != nil {
-			return nil, err
-		}
-		if len(nsRecords) > 0 {
-			return nsRecords, nil
-		}
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "NS", viewID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 回退到默认视图
-	err := baseQuery.Session(&gorm.Session{}).Where("(`record`.view_id IS NULL OR `record`.view_id = 0)").Scan(&nsRecords).Error
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Scan(&nsRecords).Error
+		return len(nsRecords) > 0, err
+	})
 	return nsRecords, err
 }
 
@@ -188,21 +205,67 @@ func (dao *RecordDAO) QueryCNAMERecords(ctx context.Context, zoneName, recordNam
 		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
 			zoneName, recordName)
 
-	if viewID > 0 {
-		err := baseQuery.Session(&gorm.Session{}).Where("`record`.view_id = ?", viewID).Scan(&cnameRecords).Error
-		if err != nil {
-			return nil, err
-		}
-		if len(cnameRecords) > 0 {
-			return cnameRecords, nil
-		}
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "CNAME", viewID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 回退到默认视图
-	err := baseQuery.Session(&gorm.Session{}).Where("(`record`.view_id IS NULL OR `record`.view_id = 0)").Scan(&cnameRecords).Error
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Scan(&cnameRecords).Error
+		return len(cnameRecords) > 0, err
+	})
 	return cnameRecords, err
 }
 
+// QueryDNAMERecords CoreDNS专用DNAME记录查询
+func (dao *RecordDAO) QueryDNAMERecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.DNAMERecord, error) {
+	var dnameRecords []*model.DNAMERecord
+	baseQuery := dao.db.WithContext(ctx).
+		Model(&model.DNAMERecord{}).
+		Select("`record_dname`.*, `record`.ttl").
+		Joins("JOIN `record` ON `record`.id = `record_dname`.record_id").
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
+			zoneName, recordName)
+
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "DNAME", viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Scan(&dnameRecords).Error
+		return len(dnameRecords) > 0, err
+	})
+	return dnameRecords, err
+}
+
+// QueryALIASRecords CoreDNS专用ALIAS记录查询
+func (dao *RecordDAO) QueryALIASRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ALIASRecord, error) {
+	var aliasRecords []*model.ALIASRecord
+	baseQuery := dao.db.WithContext(ctx).
+		Model(&model.ALIASRecord{}).
+		Select("`record_alias`.*, `record`.ttl").
+		Joins("JOIN `record` ON `record`.id = `record_alias`.record_id").
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
+			zoneName, recordName)
+
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "ALIAS", viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Scan(&aliasRecords).Error
+		return len(aliasRecords) > 0, err
+	})
+	return aliasRecords, err
+}
+
 // QuerySRVRecords CoreDNS专用SRV记录查询
 func (dao *RecordDAO) QuerySRVRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SRVRecord, error) {
 	var srvRecords []*model.SRVRecord
@@ -214,17 +277,300 @@ func (dao *RecordDAO) QuerySRVRecords(ctx context.Context, zoneName, recordName
 		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
 			zoneName, recordName)
 
-	if viewID > 0 {
-		err := baseQuery.Session(&gorm.Session{}).Where("`record`.view_id = ?", viewID).Order("`record_srv`.priority ASC, `record_srv`.weight DESC").Scan(&srvRecords).Error
-		if err != nil {
-			return nil, err
-		}
-		if len(srvRecords) > 0 {
-			return srvRecords, nil
-		}
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "SRV", viewID)
+	if err != nil {
+		return nil, err
 	}
 
-	// 回退到默认视图
-	err := baseQuery.Session(&gorm.Session{}).Where("(`record`.view_id IS NULL OR `record`.view_id = 0)").Order("`record_srv`.priority ASC, `record_srv`.weight DESC").Scan(&srvRecords).Error
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Order("`record_srv`.priority ASC, `record_srv`.weight DESC").Scan(&srvRecords).Error
+		return len(srvRecords) > 0, err
+	})
 	return srvRecords, err
 }
+
+// QueryCAARecords CoreDNS专用CAA记录查询
+func (dao *RecordDAO) QueryCAARecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CAARecord, error) {
+	var caaRecords []*model.CAARecord
+	baseQuery := dao.db.WithContext(ctx).
+		Model(&model.CAARecord{}).
+		Select("`record_caa`.*, `record`.ttl").
+		Joins("JOIN `record` ON `record`.id = `record_caa`.record_id").
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
+			zoneName, recordName)
+
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "CAA", viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Order("`record_caa`.id ASC").Scan(&caaRecords).Error
+		return len(caaRecords) > 0, err
+	})
+	return caaRecords, err
+}
+
+// QuerySVCBRecords CoreDNS专用SVCB记录查询
+func (dao *RecordDAO) QuerySVCBRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SVCBRecord, error) {
+	var svcbRecords []*model.SVCBRecord
+	baseQuery := dao.db.WithContext(ctx).
+		Model(&model.SVCBRecord{}).
+		Select("`record_svcb`.*, `record`.ttl").
+		Joins("JOIN `record` ON `record`.id = `record_svcb`.record_id").
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
+			zoneName, recordName)
+
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "SVCB", viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Order("`record_svcb`.priority ASC, `record_svcb`.id ASC").Scan(&svcbRecords).Error
+		return len(svcbRecords) > 0, err
+	})
+	return svcbRecords, err
+}
+
+// QueryHTTPSRecords CoreDNS专用HTTPS记录查询
+func (dao *RecordDAO) QueryHTTPSRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.HTTPSRecord, error) {
+	var httpsRecords []*model.HTTPSRecord
+	baseQuery := dao.db.WithContext(ctx).
+		Model(&model.HTTPSRecord{}).
+		Select("`record_https`.*, `record`.ttl").
+		Joins("JOIN `record` ON `record`.id = `record_https`.record_id").
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
+			zoneName, recordName)
+
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "HTTPS", viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Order("`record_https`.priority ASC, `record_https`.id ASC").Scan(&httpsRecords).Error
+		return len(httpsRecords) > 0, err
+	})
+	return httpsRecords, err
+}
+
+// QueryTLSARecords CoreDNS专用TLSA记录查询
+func (dao *RecordDAO) QueryTLSARecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.TLSARecord, error) {
+	var tlsaRecords []*model.TLSARecord
+	baseQuery := dao.db.WithContext(ctx).
+		Model(&model.TLSARecord{}).
+		Select("`record_tlsa`.*, `record`.ttl").
+		Joins("JOIN `record` ON `record`.id = `record_tlsa`.record_id").
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
+			zoneName, recordName)
+
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "TLSA", viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Order("`record_tlsa`.id ASC").Scan(&tlsaRecords).Error
+		return len(tlsaRecords) > 0, err
+	})
+	return tlsaRecords, err
+}
+
+// QuerySSHFPRecords CoreDNS专用SSHFP记录查询
+func (dao *RecordDAO) QuerySSHFPRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SSHFPRecord, error) {
+	var sshfpRecords []*model.SSHFPRecord
+	baseQuery := dao.db.WithContext(ctx).
+		Model(&model.SSHFPRecord{}).
+		Select("`record_sshfp`.*, `record`.ttl").
+		Joins("JOIN `record` ON `record`.id = `record_sshfp`.record_id").
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
+			zoneName, recordName)
+
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "SSHFP", viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Order("`record_sshfp`.id ASC").Scan(&sshfpRecords).Error
+		return len(sshfpRecords) > 0, err
+	})
+	return sshfpRecords, err
+}
+
+// QueryGenericRecords CoreDNS专用通用记录查询，按记录类型编号匹配
+func (dao *RecordDAO) QueryGenericRecords(ctx context.Context, zoneName, recordName string, rrType uint16, viewID int64) ([]*model.GenericRecord, error) {
+	var genericRecords []*model.GenericRecord
+	baseQuery := dao.db.WithContext(ctx).
+		Model(&model.GenericRecord{}).
+		Select("`record_generic`.*, `record`.ttl").
+		Joins("JOIN `record` ON `record`.id = `record_generic`.record_id").
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`zone`.name = ? AND `record`.name = ? AND `record_generic`.rr_type = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
+			zoneName, recordName, rrType)
+
+	chain, err := dao.viewChain(ctx, zoneName, recordName, dns.TypeToString[rrType], viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Order("`record_generic`.id ASC").Scan(&genericRecords).Error
+		return len(genericRecords) > 0, err
+	})
+	return genericRecords, err
+}
+
+// QueryPTRRecords CoreDNS专用PTR记录查询
+func (dao *RecordDAO) QueryPTRRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.PTRRecord, error) {
+	var ptrRecords []*model.PTRRecord
+	baseQuery := dao.db.WithContext(ctx).
+		Model(&model.PTRRecord{}).
+		Select("`record_ptr`.*, `record`.ttl").
+		Joins("JOIN `record` ON `record`.id = `record_ptr`.record_id").
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1",
+			zoneName, recordName)
+
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "PTR", viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Scan(&ptrRecords).Error
+		return len(ptrRecords) > 0, err
+	})
+	return ptrRecords, err
+}
+
+// QueryAutoPTRRecords CoreDNS专用自动PTR查询，根据开启auto_ptr的zone中的A/AAAA记录生成PTR
+func (dao *RecordDAO) QueryAutoPTRRecords(ctx context.Context, ip net.IP, viewID int64) ([]*model.PTRRecord, error) {
+	table, value := "record_aaaa", interface{}([]byte(ip.To16()))
+	if v4 := ip.To4(); v4 != nil {
+		table, value = "record_a", binary.BigEndian.Uint32(v4)
+	}
+
+	var rows []struct {
+		RecordID int64
+		Name     string
+		Zone     string
+		TTL      uint32
+	}
+	baseQuery := dao.db.WithContext(ctx).
+		Table("`"+table+"`").
+		Select("`record`.id AS record_id, `record`.name AS name, `zone`.name AS zone, `record`.ttl").
+		Joins("JOIN `record` ON `record`.id = `"+table+"`.record_id").
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`"+table+"`.ip = ? AND `zone`.auto_ptr = 1 AND `zone`.is_active = 1 AND `record`.is_active = 1", value)
+
+	chain, err := dao.ancestors(ctx, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 沿视图链回退，最终回退到默认视图
+	err = walkViews(baseQuery, recordViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		err := tx.Scan(&rows).Error
+		return len(rows) > 0, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 记录名可能是相对名称，补全为所在zone下的FQDN
+	ptrRecords := make([]*model.PTRRecord, 0, len(rows))
+	for _, row := range rows {
+		ptrRecords = append(ptrRecords, &model.PTRRecord{
+			RecordID: row.RecordID,
+			PTRDName: qualifyName(row.Name, row.Zone),
+			TTL:      row.TTL,
+		})
+	}
+	return ptrRecords, nil
+}
+
+// QueryRRSetPolicy CoreDNS专用记录集选择策略查询，未配置策略时返回nil
+func (dao *RecordDAO) QueryRRSetPolicy(ctx context.Context, zoneName, recordName string, qType uint16, viewID int64) (*model.RRSetPolicy, error) {
+	var policy model.RRSetPolicy
+	baseQuery := dao.db.WithContext(ctx).
+		Model(&model.RRSetPolicy{}).
+		Select("`rrset_policy`.*").
+		Joins("JOIN `zone` ON `zone`.id = `rrset_policy`.zone_id").
+		Where("`zone`.name = ? AND `rrset_policy`.name = ? AND `rrset_policy`.type = ? AND `zone`.is_active = 1",
+			zoneName, recordName, dns.TypeToString[qType])
+
+	chain, err := dao.ancestors(ctx, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 沿视图链回退，最终回退到默认视图
+	var found bool
+	err = walkViews(baseQuery, policyViewScope, chain, func(tx *gorm.DB) (bool, error) {
+		result := tx.Scan(&policy)
+		found = result.RowsAffected > 0
+		return found, result.Error
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// NameExists CoreDNS专用名称存在性检查，名称自身或其子名称（空非终端）存在即视为存在
+func (dao *RecordDAO) NameExists(ctx context.Context, zoneName, recordName string, viewID int64) (bool, error) {
+	var count int64
+	query := dao.db.WithContext(ctx).
+		Model(&model.Record{}).
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`zone`.name = ? AND (`record`.name = ? OR `record`.name LIKE ? ESCAPE '!') AND `zone`.is_active = 1 AND `record`.is_active = 1",
+			zoneName, recordName, "%."+escapeLike(recordName))
+
+	// 视图链上任一视图（未被屏蔽整个名称时）的记录均可使名称存在
+	chain, err := dao.viewChain(ctx, zoneName, recordName, "", viewID)
+	if err != nil {
+		return false, err
+	}
+	views := make([]int64, 0, len(chain))
+	withDefault := false
+	for _, id := range chain {
+		if id > 0 {
+			views = append(views, id)
+		} else {
+			withDefault = true
+		}
+	}
+	switch {
+	case len(views) == 0:
+		query = query.Where("(`record`.view_id IS NULL OR `record`.view_id = 0)")
+	case len(views) == 1 && withDefault:
+		query = query.Where("(`record`.view_id = ? OR `record`.view_id IS NULL OR `record`.view_id = 0)", views[0])
+	case withDefault:
+		query = query.Where("(`record`.view_id IN ? OR `record`.view_id IS NULL OR `record`.view_id = 0)", views)
+	default:
+		query = query.Where("`record`.view_id IN ?", views)
+	}
+
+	err = query.Count(&count).Error
+	return count > 0, err
+}
+
+// escapeLike 转义LIKE模式中的特殊字符，配合 ESCAPE '!' 使用
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
@@ -3,12 +3,18 @@ package rdb
 import (
 	"context"
 	"encoding/json"
+	"net"
 
 	"github.com/cylonchau/hermes/pkg/dao/memory"
 	"github.com/cylonchau/hermes/pkg/model"
 )
 
-// CachedDNSQueryRepository is a DNS Query Proxy with L1 Cache
+// CachedDNSQueryRepository is a DNS Query Proxy with L1 Cache.
+// Entries are keyed by zone, qtype, name and view and hold the full rows
+// found along the view's parent chain. Nothing cached depends on the client
+// subnet: EDNS Client Subnets are mapped to a view before any lookup, and
+// answers that vary within a view, like the nearest RRset policy, are
+// selected from the cached rows per query and never stored here.
 type CachedDNSQueryRepository struct {
 	rdb   DNSQueryRepository
 	cache *memory.CacheDAO
@@ -214,6 +220,62 @@ func (c *CachedDNSQueryRepository) QueryCNAMERecords(ctx context.Context, zoneNa
 	return res, nil
 }
 
+func (c *CachedDNSQueryRepository) QueryDNAMERecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.DNAMERecord, error) {
+	if c.cache != nil {
+		if bytes, ok := c.cache.Get(zoneName, 39, recordName, viewID); ok {
+			if string(bytes) == "[]" {
+				return nil, nil
+			}
+			var res []*model.DNAMERecord
+			if err := json.Unmarshal(bytes, &res); err == nil {
+				return res, nil
+			}
+		}
+	}
+	res, err := c.rdb.QueryDNAMERecords(ctx, zoneName, recordName, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		if len(res) > 0 {
+			bytes, _ := json.Marshal(res)
+			c.cache.Set(zoneName, 39, recordName, viewID, bytes, int(res[0].TTL))
+		} else {
+			c.cache.Set(zoneName, 39, recordName, viewID, []byte("[]"), 5)
+		}
+	}
+	return res, nil
+}
+
+func (c *CachedDNSQueryRepository) QueryALIASRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ALIASRecord, error) {
+	if c.cache != nil {
+		if bytes, ok := c.cache.Get(zoneName, model.TypeALIAS, recordName, viewID); ok {
+			if string(bytes) == "[]" {
+				return nil, nil
+			}
+			var res []*model.ALIASRecord
+			if err := json.Unmarshal(bytes, &res); err == nil {
+				return res, nil
+			}
+		}
+	}
+	res, err := c.rdb.QueryALIASRecords(ctx, zoneName, recordName, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		if len(res) > 0 {
+			bytes, _ := json.Marshal(res)
+			c.cache.Set(zoneName, model.TypeALIAS, recordName, viewID, bytes, int(res[0].TTL))
+		} else {
+			c.cache.Set(zoneName, model.TypeALIAS, recordName, viewID, []byte("[]"), 5)
+		}
+	}
+	return res, nil
+}
+
 func (c *CachedDNSQueryRepository) QuerySRVRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SRVRecord, error) {
 	if c.cache != nil {
 		if bytes, ok := c.cache.Get(zoneName, 33, recordName, viewID); ok {
@@ -241,3 +303,285 @@ func (c *CachedDNSQueryRepository) QuerySRVRecords(ctx context.Context, zoneName
 	}
 	return res, nil
 }
+
+func (c *CachedDNSQueryRepository) QueryCAARecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CAARecord, error) {
+	if c.cache != nil {
+		if bytes, ok := c.cache.Get(zoneName, 257, recordName, viewID); ok {
+			if string(bytes) == "[]" {
+				return nil, nil
+			}
+			var res []*model.CAARecord
+			if err := json.Unmarshal(bytes, &res); err == nil {
+				return res, nil
+			}
+		}
+	}
+	res, err := c.rdb.QueryCAARecords(ctx, zoneName, recordName, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		if len(res) > 0 {
+			bytes, _ := json.Marshal(res)
+			c.cache.Set(zoneName, 257, recordName, viewID, bytes, int(res[0].TTL))
+		} else {
+			c.cache.Set(zoneName, 257, recordName, viewID, []byte("[]"), 5)
+		}
+	}
+	return res, nil
+}
+
+func (c *CachedDNSQueryRepository) QuerySVCBRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SVCBRecord, error) {
+	if c.cache != nil {
+		if bytes, ok := c.cache.Get(zoneName, 64, recordName, viewID); ok {
+			if string(bytes) == "[]" {
+				return nil, nil
+			}
+			var res []*model.SVCBRecord
+			if err := json.Unmarshal(bytes, &res); err == nil {
+				return res, nil
+			}
+		}
+	}
+	res, err := c.rdb.QuerySVCBRecords(ctx, zoneName, recordName, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		if len(res) > 0 {
+			bytes, _ := json.Marshal(res)
+			c.cache.Set(zoneName, 64, recordName, viewID, bytes, int(res[0].TTL))
+		} else {
+			c.cache.Set(zoneName, 64, recordName, viewID, []byte("[]"), 5)
+		}
+	}
+	return res, nil
+}
+
+func (c *CachedDNSQueryRepository) QueryHTTPSRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.HTTPSRecord, error) {
+	if c.cache != nil {
+		if bytes, ok := c.cache.Get(zoneName, 65, recordName, viewID); ok {
+			if string(bytes) == "[]" {
+				return nil, nil
+			}
+			var res []*model.HTTPSRecord
+			if err := json.Unmarshal(bytes, &res); err == nil {
+				return res, nil
+			}
+		}
+	}
+	res, err := c.rdb.QueryHTTPSRecords(ctx, zoneName, recordName, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		if len(res) > 0 {
+			bytes, _ := json.Marshal(res)
+			c.cache.Set(zoneName, 65, recordName, viewID, bytes, int(res[0].TTL))
+		} else {
+			c.cache.Set(zoneName, 65, recordName, viewID, []byte("[]"), 5)
+		}
+	}
+	return res, nil
+}
+
+func (c *CachedDNSQueryRepository) QueryTLSARecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.TLSARecord, error) {
+	if c.cache != nil {
+		if bytes, ok := c.cache.Get(zoneName, 52, recordName, viewID); ok {
+			if string(bytes) == "[]" {
+				return nil, nil
+			}
+			var res []*model.TLSARecord
+			if err := json.Unmarshal(bytes, &res); err == nil {
+				return res, nil
+			}
+		}
+	}
+	res, err := c.rdb.QueryTLSARecords(ctx, zoneName, recordName, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		if len(res) > 0 {
+			bytes, _ := json.Marshal(res)
+			c.cache.Set(zoneName, 52, recordName, viewID, bytes, int(res[0].TTL))
+		} else {
+			c.cache.Set(zoneName, 52, recordName, viewID, []byte("[]"), 5)
+		}
+	}
+	return res, nil
+}
+
+func (c *CachedDNSQueryRepository) QuerySSHFPRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SSHFPRecord, error) {
+	if c.cache != nil {
+		if bytes, ok := c.cache.Get(zoneName, 44, recordName, viewID); ok {
+			if string(bytes) == "[]" {
+				return nil, nil
+			}
+			var res []*model.SSHFPRecord
+			if err := json.Unmarshal(bytes, &res); err == nil {
+				return res, nil
+			}
+		}
+	}
+	res, err := c.rdb.QuerySSHFPRecords(ctx, zoneName, recordName, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		if len(res) > 0 {
+			bytes, _ := json.Marshal(res)
+			c.cache.Set(zoneName, 44, recordName, viewID, bytes, int(res[0].TTL))
+		} else {
+			c.cache.Set(zoneName, 44, recordName, viewID, []byte("[]"), 5)
+		}
+	}
+	return res, nil
+}
+
+func (c *CachedDNSQueryRepository) QueryGenericRecords(ctx context.Context, zoneName, recordName string, rrType uint16, viewID int64) ([]*model.GenericRecord, error) {
+	if c.cache != nil {
+		if bytes, ok := c.cache.Get(zoneName, rrType, recordName, viewID); ok {
+			if string(bytes) == "[]" {
+				return nil, nil
+			}
+			var res []*model.GenericRecord
+			if err := json.Unmarshal(bytes, &res); err == nil {
+				return res, nil
+			}
+		}
+	}
+	res, err := c.rdb.QueryGenericRecords(ctx, zoneName, recordName, rrType, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		if len(res) > 0 {
+			bytes, _ := json.Marshal(res)
+			c.cache.Set(zoneName, rrType, recordName, viewID, bytes, int(res[0].TTL))
+		} else {
+			c.cache.Set(zoneName, rrType, recordName, viewID, []byte("[]"), 5)
+		}
+	}
+	return res, nil
+}
+
+func (c *CachedDNSQueryRepository) QueryPTRRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.PTRRecord, error) {
+	if c.cache != nil {
+		if bytes, ok := c.cache.Get(zoneName, 12, recordName, viewID); ok {
+			if string(bytes) == "[]" {
+				return nil, nil
+			}
+			var res []*model.PTRRecord
+			if err := json.Unmarshal(bytes, &res); err == nil {
+				return res, nil
+			}
+		}
+	}
+	res, err := c.rdb.QueryPTRRecords(ctx, zoneName, recordName, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		if len(res) > 0 {
+			bytes, _ := json.Marshal(res)
+			c.cache.Set(zoneName, 12, recordName, viewID, bytes, int(res[0].TTL))
+		} else {
+			c.cache.Set(zoneName, 12, recordName, viewID, []byte("[]"), 5)
+		}
+	}
+	return res, nil
+}
+
+func (c *CachedDNSQueryRepository) QueryAutoPTRRecords(ctx context.Context, ip net.IP, viewID int64) ([]*model.PTRRecord, error) {
+	// Generated PTRs are not bound to a single zone, key them by address
+	const autoPTRZone = "auto_ptr"
+	if c.cache != nil {
+		if bytes, ok := c.cache.Get(autoPTRZone, 12, ip.String(), viewID); ok {
+			if string(bytes) == "[]" {
+				return nil, nil
+			}
+			var res []*model.PTRRecord
+			if err := json.Unmarshal(bytes, &res); err == nil {
+				return res, nil
+			}
+		}
+	}
+	res, err := c.rdb.QueryAutoPTRRecords(ctx, ip, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		if len(res) > 0 {
+			bytes, _ := json.Marshal(res)
+			c.cache.Set(autoPTRZone, 12, ip.String(), viewID, bytes, int(res[0].TTL))
+		} else {
+			c.cache.Set(autoPTRZone, 12, ip.String(), viewID, []byte("[]"), 5)
+		}
+	}
+	return res, nil
+}
+
+func (c *CachedDNSQueryRepository) QueryRRSetPolicy(ctx context.Context, zoneName, recordName string, qType uint16, viewID int64) (*model.RRSetPolicy, error) {
+	// Policies share the RRset's qtype, the name prefix keeps them apart from records
+	key := "policy:" + recordName
+	if c.cache != nil {
+		if bytes, ok := c.cache.Get(zoneName, qType, key, viewID); ok {
+			if string(bytes) == "[]" {
+				return nil, nil
+			}
+			var res *model.RRSetPolicy
+			if err := json.Unmarshal(bytes, &res); err == nil {
+				return res, nil
+			}
+		}
+	}
+	res, err := c.rdb.QueryRRSetPolicy(ctx, zoneName, recordName, qType, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		if res != nil {
+			bytes, _ := json.Marshal(res)
+			c.cache.Set(zoneName, qType, key, viewID, bytes, 60)
+		} else {
+			c.cache.Set(zoneName, qType, key, viewID, []byte("[]"), 5)
+		}
+	}
+	return res, nil
+}
+
+func (c *CachedDNSQueryRepository) NameExists(ctx context.Context, zoneName, recordName string, viewID int64) (bool, error) {
+	// QType 0 is reserved for name existence entries
+	if c.cache != nil {
+		if bytes, ok := c.cache.Get(zoneName, 0, recordName, viewID); ok {
+			var res bool
+			if err := json.Unmarshal(bytes, &res); err == nil {
+				return res, nil
+			}
+		}
+	}
+	res, err := c.rdb.NameExists(ctx, zoneName, recordName, viewID)
+	if err != nil {
+		return false, err
+	}
+
+	if c.cache != nil {
+		bytes, _ := json.Marshal(res)
+		if res {
+			c.cache.Set(zoneName, 0, recordName, viewID, bytes, 60)
+		} else {
+			c.cache.Set(zoneName, 0, recordName, viewID, bytes, 5)
+		}
+	}
+	return res, nil
+}
@@ -2,10 +2,12 @@ package rdb
 
 import (
 	"context"
+	"net"
 	"regexp"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,7 +20,7 @@ func TestRecordDAO_Mock_QueryARecords(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "record_id", "ip", "ttl"}).
 		AddRow(1, 1, 16843009, 600)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_a`.*, `record`.ttl FROM `record_a` JOIN `record` ON `record`.id = `record_a`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id WHERE (`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1) AND ((`record`.view_id IS NULL OR `record`.view_id = 0))")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_a`.*, `record`.ttl, COALESCE(`record_a`.latitude, `pop`.latitude) AS latitude, COALESCE(`record_a`.longitude, `pop`.longitude) AS longitude FROM `record_a` JOIN `record` ON `record`.id = `record_a`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id LEFT JOIN `pop` ON `pop`.code = `record_a`.pop AND `record_a`.pop <> '' WHERE (`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1) AND ((`record`.view_id IS NULL OR `record`.view_id = 0))")).
 		WithArgs("example.com", "www").
 		WillReturnRows(rows)
 
@@ -54,16 +56,17 @@ func TestRecordDAO_Mock_QueryARecords_Fallback(t *testing.T) {
 	assert.NoError(t, err)
 	dao := NewRecordDAO(db)
 	ctx := context.Background()
+	expectViewChains(mock, [][2]int64{{10, 0}, {2, 0}})
 
 	// 1. Simulate no records for specific View
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_a`.*, `record`.ttl FROM `record_a` JOIN `record` ON `record`.id = `record_a`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id WHERE (`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1) AND `record`.view_id = ?")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_a`.*, `record`.ttl, COALESCE(`record_a`.latitude, `pop`.latitude) AS latitude, COALESCE(`record_a`.longitude, `pop`.longitude) AS longitude FROM `record_a` JOIN `record` ON `record`.id = `record_a`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id LEFT JOIN `pop` ON `pop`.code = `record_a`.pop AND `record_a`.pop <> '' WHERE (`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1) AND `record`.view_id = ?")).
 		WithArgs("example.com", "www", int64(10)).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "record_id", "ip", "ttl"})) // Return empty
 
 	// 2. Simulate fallback to default view success
 	rows := sqlmock.NewRows([]string{"id", "record_id", "ip", "ttl"}).
 		AddRow(1, 1, 16843009, 600)
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_a`.*, `record`.ttl FROM `record_a` JOIN `record` ON `record`.id = `record_a`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id WHERE (`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1) AND ((`record`.view_id IS NULL OR `record`.view_id = 0))")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_a`.*, `record`.ttl, COALESCE(`record_a`.latitude, `pop`.latitude) AS latitude, COALESCE(`record_a`.longitude, `pop`.longitude) AS longitude FROM `record_a` JOIN `record` ON `record`.id = `record_a`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id LEFT JOIN `pop` ON `pop`.code = `record_a`.pop AND `record_a`.pop <> '' WHERE (`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1) AND ((`record`.view_id IS NULL OR `record`.view_id = 0))")).
 		WithArgs("example.com", "www").
 		WillReturnRows(rows)
 
@@ -72,3 +75,157 @@ func TestRecordDAO_Mock_QueryARecords_Fallback(t *testing.T) {
 	assert.Len(t, res, 1) // Should get default view record
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestRecordDAO_Mock_QuerySOARecord_NotFound(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+	expectViewChains(mock, [][2]int64{{10, 0}, {2, 0}})
+
+	// View has no SOA, fall back to default view which has none either
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_soa`.*, `record`.ttl FROM `record_soa` JOIN `record` ON `record`.id = `record_soa`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id WHERE (`zone`.name = ? AND `record`.name IN (?, '@') AND `zone`.is_active = 1 AND `record`.is_active = 1) AND `record`.view_id = ? ORDER BY `record_soa`.id ASC")).
+		WithArgs("example.com", "example.com", int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "record_id", "primary_ns", "ttl"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_soa`.*, `record`.ttl FROM `record_soa` JOIN `record` ON `record`.id = `record_soa`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id WHERE (`zone`.name = ? AND `record`.name IN (?, '@') AND `zone`.is_active = 1 AND `record`.is_active = 1) AND ((`record`.view_id IS NULL OR `record`.view_id = 0)) ORDER BY `record_soa`.id ASC")).
+		WithArgs("example.com", "example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "record_id", "primary_ns", "ttl"}))
+
+	res, err := dao.QuerySOARecord(ctx, "example.com", 10)
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_NameExists(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+	expectViewChains(mock, [][2]int64{{10, 0}, {2, 0}})
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `record` JOIN `zone` ON `zone`.id = `record`.zone_id WHERE (`zone`.name = ? AND (`record`.name = ? OR `record`.name LIKE ? ESCAPE '!') AND `zone`.is_active = 1 AND `record`.is_active = 1) AND ((`record`.view_id = ? OR `record`.view_id IS NULL OR `record`.view_id = 0))")).
+		WithArgs("example.com.", "_tcp.example.com.", "%.!_tcp.example.com.", int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	exists, err := dao.NameExists(ctx, "example.com.", "_tcp.example.com.", 10)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_QueryCAARecords_Fallback(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+	expectViewChains(mock, [][2]int64{{10, 0}, {2, 0}})
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_caa`.*, `record`.ttl FROM `record_caa` JOIN `record` ON `record`.id = `record_caa`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id WHERE (`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1) AND `record`.view_id = ? ORDER BY `record_caa`.id ASC")).
+		WithArgs("example.com.", "example.com.", int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "record_id", "flag", "tag", "value", "ttl"}))
+
+	rows := sqlmock.NewRows([]string{"id", "record_id", "flag", "tag", "value", "ttl"}).
+		AddRow(1, 1, 0, "issue", "letsencrypt.org", 3600)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_caa`.*, `record`.ttl FROM `record_caa` JOIN `record` ON `record`.id = `record_caa`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id WHERE (`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1) AND ((`record`.view_id IS NULL OR `record`.view_id = 0)) ORDER BY `record_caa`.id ASC")).
+		WithArgs("example.com.", "example.com.").
+		WillReturnRows(rows)
+
+	res, err := dao.QueryCAARecords(ctx, "example.com.", "example.com.", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, "issue", res[0].Tag)
+		assert.Equal(t, uint32(3600), res[0].TTL)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_QueryAutoPTRRecords(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"record_id", "name", "zone", "ttl"}).
+		AddRow(1, "www.example.com.", "example.com.", 600).
+		AddRow(2, "Mail", "example.com.", 300).
+		AddRow(3, "@", "example.com.", 300)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record`.id AS record_id, `record`.name AS name, `zone`.name AS zone, `record`.ttl FROM `record_a` JOIN `record` ON `record`.id = `record_a`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id WHERE (`record_a`.ip = ? AND `zone`.auto_ptr = 1 AND `zone`.is_active = 1 AND `record`.is_active = 1) AND ((`record`.view_id IS NULL OR `record`.view_id = 0))")).
+		WithArgs(uint32(0x01020304)).
+		WillReturnRows(rows)
+
+	res, err := dao.QueryAutoPTRRecords(ctx, net.ParseIP("1.2.3.4"), 0)
+	assert.NoError(t, err)
+	if assert.Len(t, res, 3) {
+		assert.Equal(t, "www.example.com.", res[0].PTRDName)
+		assert.Equal(t, uint32(600), res[0].TTL)
+		// Relative owners are qualified with their zone
+		assert.Equal(t, "mail.example.com.", res[1].PTRDName)
+		assert.Equal(t, "example.com.", res[2].PTRDName)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_QueryRRSetPolicy_Fallback(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+	expectViewChains(mock, [][2]int64{{10, 0}, {2, 0}})
+
+	// No policy in the client's view, the default view policy applies
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `rrset_policy`.* FROM `rrset_policy` JOIN `zone` ON `zone`.id = `rrset_policy`.zone_id WHERE (`zone`.name = ? AND `rrset_policy`.name = ? AND `rrset_policy`.type = ? AND `zone`.is_active = 1) AND `rrset_policy`.view_id = ?")).
+		WithArgs("example.com.", "www.example.com.", "A", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `rrset_policy`.* FROM `rrset_policy` JOIN `zone` ON `zone`.id = `rrset_policy`.zone_id WHERE (`zone`.name = ? AND `rrset_policy`.name = ? AND `rrset_policy`.type = ? AND `zone`.is_active = 1) AND `rrset_policy`.view_id = 0")).
+		WithArgs("example.com.", "www.example.com.", "A").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "policy", "count"}).AddRow(1, "weighted", 1))
+
+	res, err := dao.QueryRRSetPolicy(ctx, "example.com.", "www.example.com.", dns.TypeA, 2)
+	assert.NoError(t, err)
+	if assert.NotNil(t, res) {
+		assert.Equal(t, "weighted", res.Policy)
+		assert.Equal(t, 1, res.Count)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_QueryRRSetPolicy_None(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `rrset_policy`.* FROM `rrset_policy`")).
+		WithArgs("example.com.", "www.example.com.", "AAAA").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	res, err := dao.QueryRRSetPolicy(ctx, "example.com.", "www.example.com.", dns.TypeAAAA, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_QueryARecords_PoPCoordinates(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	// The trailing columns carry the coordinates resolved through the PoP
+	rows := sqlmock.NewRows([]string{"id", "record_id", "ip", "latitude", "longitude", "pop", "ttl", "latitude", "longitude"}).
+		AddRow(1, 1, 16843009, nil, nil, "fra1", 600, 50.11, 8.68)
+
+	mock.ExpectQuery(regexp.QuoteMeta("LEFT JOIN `pop` ON `pop`.code = `record_a`.pop")).
+		WithArgs("example.com.", "www.example.com.").
+		WillReturnRows(rows)
+
+	res, err := dao.QueryARecords(ctx, "example.com.", "www.example.com.", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, res, 1) && assert.NotNil(t, res[0].Latitude) && assert.NotNil(t, res[0].Longitude) {
+		assert.Equal(t, 50.11, *res[0].Latitude)
+		assert.Equal(t, 8.68, *res[0].Longitude)
+		assert.Equal(t, "fra1", res[0].PoP)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,51 @@
+package rdb
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// GeoIPOverrideDAO GeoIP覆盖条目的关系型数据访问层
+type GeoIPOverrideDAO struct {
+	db *gorm.DB
+}
+
+// NewGeoIPOverrideDAO 创建GeoIPOverrideDAO实例
+func NewGeoIPOverrideDAO(db *gorm.DB) *GeoIPOverrideDAO {
+	return &GeoIPOverrideDAO{db: db}
+}
+
+// Create 创建覆盖条目
+func (dao *GeoIPOverrideDAO) Create(ctx context.Context, override *model.GeoIPOverride) error {
+	return dao.db.WithContext(ctx).Create(override).Error
+}
+
+// GetByID 根据ID获取覆盖条目
+func (dao *GeoIPOverrideDAO) GetByID(ctx context.Context, id int64) (*model.GeoIPOverride, error) {
+	var override model.GeoIPOverride
+	err := dao.db.WithContext(ctx).First(&override, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// GetAll 获取所有覆盖条目
+func (dao *GeoIPOverrideDAO) GetAll(ctx context.Context) ([]*model.GeoIPOverride, error) {
+	var overrides []*model.GeoIPOverride
+	err := dao.db.WithContext(ctx).Order("id ASC").Find(&overrides).Error
+	return overrides, err
+}
+
+// Update 更新覆盖条目
+func (dao *GeoIPOverrideDAO) Update(ctx context.Context, override *model.GeoIPOverride) error {
+	return dao.db.WithContext(ctx).Save(override).Error
+}
+
+// Delete 删除覆盖条目
+func (dao *GeoIPOverrideDAO) Delete(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Delete(&model.GeoIPOverride{}, id).Error
+}
@@ -0,0 +1,71 @@
+package rdb
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestGeoIPOverrideDAO_Mock_Create(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewGeoIPOverrideDAO(db)
+	ctx := context.Background()
+
+	override := &model.GeoIPOverride{CIDR: "203.0.113.0/24", Continent: "AS", Country: "CN", Region: "GD", Remark: "office"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `geoip_override`")).
+		WithArgs(override.CIDR, "AS", "CN", "GD", uint32(0), "", nil, nil, "office", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = dao.Create(ctx, override)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), override.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGeoIPOverrideDAO_Mock_GetAll(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewGeoIPOverrideDAO(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"id", "cidr", "country", "asn", "latitude", "longitude"}).
+		AddRow(1, "203.0.113.0/24", "CN", 0, nil, nil).
+		AddRow(2, "2001:db8::/32", "", 64500, 50.11, 8.68)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `geoip_override` ORDER BY id ASC")).
+		WillReturnRows(rows)
+
+	res, err := dao.GetAll(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, res, 2) {
+		assert.Nil(t, res[0].Latitude)
+		assert.Equal(t, uint32(64500), res[1].ASN)
+		assert.Equal(t, 50.11, *res[1].Latitude)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGeoIPOverrideDAO_Mock_Delete(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewGeoIPOverrideDAO(db)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `geoip_override` WHERE `geoip_override`.`id` = ?")).
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, dao.Delete(ctx, 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,152 @@
+package rdb
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// HealthTarget 健康检查的一个探测目标，即关联了健康检查的一条A/AAAA记录
+type HealthTarget struct {
+	CheckID int64
+	IP      net.IP
+}
+
+// HealthCheckDAO 健康检查的关系型数据访问层
+type HealthCheckDAO struct {
+	db *gorm.DB
+}
+
+// NewHealthCheckDAO 创建HealthCheckDAO实例
+func NewHealthCheckDAO(db *gorm.DB) *HealthCheckDAO {
+	return &HealthCheckDAO{db: db}
+}
+
+// Create 创建健康检查
+func (dao *HealthCheckDAO) Create(ctx context.Context, check *model.HealthCheck) error {
+	return dao.db.WithContext(ctx).Create(check).Error
+}
+
+// GetByID 根据ID获取健康检查
+func (dao *HealthCheckDAO) GetByID(ctx context.Context, id int64) (*model.HealthCheck, error) {
+	var check model.HealthCheck
+	err := dao.db.WithContext(ctx).First(&check, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+// GetAll 获取所有健康检查
+func (dao *HealthCheckDAO) GetAll(ctx context.Context) ([]*model.HealthCheck, error) {
+	var checks []*model.HealthCheck
+	err := dao.db.WithContext(ctx).Order("id ASC").Find(&checks).Error
+	return checks, err
+}
+
+// GetActive 获取所有启用的健康检查
+func (dao *HealthCheckDAO) GetActive(ctx context.Context) ([]*model.HealthCheck, error) {
+	var checks []*model.HealthCheck
+	err := dao.db.WithContext(ctx).Where("is_active = ?", true).Order("id ASC").Find(&checks).Error
+	return checks, err
+}
+
+// Update 更新健康检查
+func (dao *HealthCheckDAO) Update(ctx context.Context, check *model.HealthCheck) error {
+	return dao.db.WithContext(ctx).Save(check).Error
+}
+
+// Delete 删除健康检查及其状态与历史
+func (dao *HealthCheckDAO) Delete(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("check_id = ?", id).Delete(&model.HealthStatus{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("check_id = ?", id).Delete(&model.HealthEvent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.HealthCheck{}, id).Error
+	})
+}
+
+// ListTargets 获取所有关联了健康检查的活跃A/AAAA记录
+func (dao *HealthCheckDAO) ListTargets(ctx context.Context) ([]*HealthTarget, error) {
+	var aRows []struct {
+		HealthCheckID int64
+		IP            uint32
+	}
+	err := dao.db.WithContext(ctx).
+		Table("`record_a`").
+		Select("`record_a`.health_check_id, `record_a`.ip").
+		Joins("JOIN `record` ON `record`.id = `record_a`.record_id").
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`record_a`.health_check_id > 0 AND `zone`.is_active = 1 AND `record`.is_active = 1").
+		Scan(&aRows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var aaaaRows []struct {
+		HealthCheckID int64
+		IP            []byte
+	}
+	err = dao.db.WithContext(ctx).
+		Table("`record_aaaa`").
+		Select("`record_aaaa`.health_check_id, `record_aaaa`.ip").
+		Joins("JOIN `record` ON `record`.id = `record_aaaa`.record_id").
+		Joins("JOIN `zone` ON `zone`.id = `record`.zone_id").
+		Where("`record_aaaa`.health_check_id > 0 AND `zone`.is_active = 1 AND `record`.is_active = 1").
+		Scan(&aaaaRows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]*HealthTarget, 0, len(aRows)+len(aaaaRows))
+	for _, row := range aRows {
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, row.IP)
+		targets = append(targets, &HealthTarget{CheckID: row.HealthCheckID, IP: ip})
+	}
+	for _, row := range aaaaRows {
+		targets = append(targets, &HealthTarget{CheckID: row.HealthCheckID, IP: net.IP(row.IP)})
+	}
+	return targets, nil
+}
+
+// SaveStatus 写入探测目标的最新状态并记录一条状态变化历史
+func (dao *HealthCheckDAO) SaveStatus(ctx context.Context, status *model.HealthStatus) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "check_id"}, {Name: "target"}},
+			DoUpdates: clause.AssignmentColumns([]string{"healthy", "message", "changed_at"}),
+		}).Create(status).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&model.HealthEvent{
+			CheckID: status.CheckID,
+			Target:  status.Target,
+			Healthy: status.Healthy,
+			Message: status.Message,
+		}).Error
+	})
+}
+
+// ListStatus 获取健康检查下所有探测目标的当前状态
+func (dao *HealthCheckDAO) ListStatus(ctx context.Context, checkID int64) ([]*model.HealthStatus, error) {
+	var statuses []*model.HealthStatus
+	err := dao.db.WithContext(ctx).Where("check_id = ?", checkID).Order("target ASC").Find(&statuses).Error
+	return statuses, err
+}
+
+// ListEvents 获取健康检查最近的状态变化历史，按时间倒序
+func (dao *HealthCheckDAO) ListEvents(ctx context.Context, checkID int64, limit int) ([]*model.HealthEvent, error) {
+	var events []*model.HealthEvent
+	err := dao.db.WithContext(ctx).Where("check_id = ?", checkID).Order("id DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
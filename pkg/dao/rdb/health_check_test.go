@@ -0,0 +1,76 @@
+package rdb
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestHealthCheckDAO_Mock_ListTargets(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewHealthCheckDAO(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_a`.health_check_id, `record_a`.ip FROM `record_a` JOIN `record` ON `record`.id = `record_a`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id WHERE `record_a`.health_check_id > 0 AND `zone`.is_active = 1 AND `record`.is_active = 1")).
+		WillReturnRows(sqlmock.NewRows([]string{"health_check_id", "ip"}).AddRow(1, 0x0a000001))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_aaaa`.health_check_id, `record_aaaa`.ip FROM `record_aaaa`")).
+		WillReturnRows(sqlmock.NewRows([]string{"health_check_id", "ip"}).AddRow(2, []byte(net.ParseIP("2001:db8::1"))))
+
+	res, err := dao.ListTargets(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, res, 2) {
+		assert.Equal(t, int64(1), res[0].CheckID)
+		assert.Equal(t, "10.0.0.1", res[0].IP.String())
+		assert.Equal(t, int64(2), res[1].CheckID)
+		assert.Equal(t, "2001:db8::1", res[1].IP.String())
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHealthCheckDAO_Mock_SaveStatus(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewHealthCheckDAO(db)
+	ctx := context.Background()
+
+	status := &model.HealthStatus{CheckID: 1, Target: "10.0.0.1", Healthy: false, Message: "connection refused", ChangedAt: time.Now()}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `health_status` (`check_id`,`target`,`healthy`,`message`,`changed_at`) VALUES (?,?,?,?,?) ON DUPLICATE KEY UPDATE `healthy`=VALUES(`healthy`),`message`=VALUES(`message`),`changed_at`=VALUES(`changed_at`)")).
+		WithArgs(status.CheckID, status.Target, status.Healthy, status.Message, status.ChangedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `health_event`")).
+		WithArgs(status.CheckID, status.Target, status.Healthy, status.Message, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, dao.SaveStatus(ctx, status))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHealthCheckDAO_Mock_Delete(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewHealthCheckDAO(db)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `health_status` WHERE check_id = ?")).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `health_event` WHERE check_id = ?")).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `health_check` WHERE `health_check`.`id` = ?")).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, dao.Delete(ctx, 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,61 @@
+package rdb
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// PoPDAO 接入点的关系型数据访问层
+type PoPDAO struct {
+	db *gorm.DB
+}
+
+// NewPoPDAO 创建PoPDAO实例
+func NewPoPDAO(db *gorm.DB) *PoPDAO {
+	return &PoPDAO{db: db}
+}
+
+// Create 创建接入点
+func (dao *PoPDAO) Create(ctx context.Context, pop *model.PoP) error {
+	return dao.db.WithContext(ctx).Create(pop).Error
+}
+
+// GetByID 根据ID获取接入点
+func (dao *PoPDAO) GetByID(ctx context.Context, id int64) (*model.PoP, error) {
+	var pop model.PoP
+	err := dao.db.WithContext(ctx).First(&pop, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pop, nil
+}
+
+// GetByCode 根据接入点代码获取接入点
+func (dao *PoPDAO) GetByCode(ctx context.Context, code string) (*model.PoP, error) {
+	var pop model.PoP
+	err := dao.db.WithContext(ctx).Where("code = ?", code).First(&pop).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pop, nil
+}
+
+// GetAll 获取所有接入点
+func (dao *PoPDAO) GetAll(ctx context.Context) ([]*model.PoP, error) {
+	var pops []*model.PoP
+	err := dao.db.WithContext(ctx).Order("code ASC").Find(&pops).Error
+	return pops, err
+}
+
+// Update 更新接入点
+func (dao *PoPDAO) Update(ctx context.Context, pop *model.PoP) error {
+	return dao.db.WithContext(ctx).Save(pop).Error
+}
+
+// Delete 删除接入点
+func (dao *PoPDAO) Delete(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Delete(&model.PoP{}, id).Error
+}
@@ -0,0 +1,74 @@
+package rdb
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestPoPDAO_Mock_Create(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewPoPDAO(db)
+	ctx := context.Background()
+
+	pop := &model.PoP{Code: "fra1", Name: "Frankfurt", Latitude: 50.11, Longitude: 8.68}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `pop`")).
+		WithArgs(pop.Code, pop.Name, pop.Latitude, pop.Longitude, pop.Remark, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = dao.Create(ctx, pop)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pop.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPoPDAO_Mock_GetAll(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewPoPDAO(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"id", "code", "latitude", "longitude"}).
+		AddRow(1, "fra1", 50.11, 8.68).
+		AddRow(2, "nyc1", 40.71, -74.00)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `pop` ORDER BY code ASC")).
+		WillReturnRows(rows)
+
+	res, err := dao.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPoPDAO_Mock_GetByCode(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewPoPDAO(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `pop` WHERE code = ? ORDER BY `pop`.`id` LIMIT ?")).
+		WithArgs("fra1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "latitude", "longitude"}).AddRow(1, "fra1", 50.11, 8.68))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `pop` WHERE code = ? ORDER BY `pop`.`id` LIMIT ?")).
+		WithArgs("ams1", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code"}))
+
+	pop, err := dao.GetByCode(ctx, "fra1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pop.ID)
+
+	_, err = dao.GetByCode(ctx, "ams1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -11,12 +11,13 @@ import (
 // RecordDAO Record的GORM数据访问层
 
 type RecordDAO struct {
-	db *gorm.DB
+	db     *gorm.DB
+	chains *viewChains // 视图继承链缓存
 }
 
 // NewRecordDAO 创建RecordDAO实例，实现所有Record相关接口
 func NewRecordDAO(db *gorm.DB) *RecordDAO {
-	return &RecordDAO{db: db}
+	return &RecordDAO{db: db, chains: &viewChains{}}
 }
 
 // ========== RecordRepository 接口实现 ==========
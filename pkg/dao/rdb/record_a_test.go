@@ -18,7 +18,7 @@ func TestRecordDAO_Mock_CreateARecord(t *testing.T) {
 	ctx := context.Background()
 
 	baseRecord := &model.Record{ZoneID: 1, Name: "a", Type: "A", IsActive: true}
-	aRecord := &model.ARecord{IP: 16843009} // 1.1.1.1
+	aRecord := &model.ARecord{IP: 16843009, Weight: 2} // 1.1.1.1
 
 	mock.ExpectBegin()
 	// Create base record
@@ -28,7 +28,7 @@ func TestRecordDAO_Mock_CreateARecord(t *testing.T) {
 
 	// Create A record (RecordID is populated from baseRecord.ID)
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record_a`")).
-		WithArgs(1, aRecord.IP, aRecord.Remark).
+		WithArgs(1, aRecord.IP, aRecord.Weight, aRecord.HealthCheckID, aRecord.Latitude, aRecord.Longitude, aRecord.PoP, aRecord.Remark).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -100,7 +100,7 @@ func TestRecordDAO_Mock_ListARecords(t *testing.T) {
 	viewRows := sqlmock.NewRows([]string{"id", "name", "category", "value"}).AddRow(1, "LOCAL", "acl", "127.0.0.1")
 	zoneRows := sqlmock.NewRows([]string{"id", "name"}).AddRow(5, "test.com.")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_a`.`id`,`record_a`.`record_id`,`record_a`.`ip`,`record_a`.`remark`,`record_a`.`ttl` FROM `record_a` JOIN record ON record.id = record_a.record_id WHERE record.view_id = ?")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_a`.`id`,`record_a`.`record_id`,`record_a`.`ip`,`record_a`.`weight`,`record_a`.`health_check_id`,`record_a`.`latitude`,`record_a`.`longitude`,`record_a`.`pop`,`record_a`.`remark`,`record_a`.`ttl` FROM `record_a` JOIN record ON record.id = record_a.record_id WHERE record.view_id = ?")).
 		WithArgs(viewID).
 		WillReturnRows(aRecordRows)
 
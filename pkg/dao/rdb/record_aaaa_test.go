@@ -18,7 +18,7 @@ func TestRecordDAO_Mock_CreateAAAARecord(t *testing.T) {
 	ctx := context.Background()
 
 	baseRecord := &model.Record{ZoneID: 1, Name: "aaaa", Type: "AAAA", IsActive: true}
-	aaaaRecord := &model.AAAARecord{IP: []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}}
+	aaaaRecord := &model.AAAARecord{IP: []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}, Weight: 2}
 
 	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record`")).
@@ -26,7 +26,7 @@ func TestRecordDAO_Mock_CreateAAAARecord(t *testing.T) {
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record_aaaa`")).
-		WithArgs(1, aaaaRecord.IP, aaaaRecord.Remark).
+		WithArgs(1, aaaaRecord.IP, aaaaRecord.Weight, aaaaRecord.HealthCheckID, aaaaRecord.Latitude, aaaaRecord.Longitude, aaaaRecord.PoP, aaaaRecord.Remark).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -0,0 +1,80 @@
+package rdb
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// CreateALIASRecord 创建ALIAS记录
+func (dao *RecordDAO) CreateALIASRecord(ctx context.Context, record *model.Record, aliasRecord *model.ALIASRecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		aliasRecord.RecordID = record.ID
+		return tx.Create(aliasRecord).Error
+	})
+}
+
+// GetALIASRecords 获取ALIAS记录
+func (dao *RecordDAO) GetALIASRecords(ctx context.Context, zoneName, recordName string) ([]*model.ALIASRecord, error) {
+	var aliasRecords []*model.ALIASRecord
+	err := dao.db.WithContext(ctx).
+		Joins("JOIN record ON record.id = record_alias.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.name = ? AND zone.is_active = ? AND record.name = ? AND record.is_active = ?",
+			zoneName, true, recordName, true).
+		Preload("Record").
+		Order("record_alias.id ASC").
+		Find(&aliasRecords).Error
+	return aliasRecords, err
+}
+
+// GetALIASRecordByID 根据记录ID获取ALIAS记录
+func (dao *RecordDAO) GetALIASRecordByID(ctx context.Context, recordID uint) (*model.ALIASRecord, error) {
+	var aliasRecord model.ALIASRecord
+	err := dao.db.WithContext(ctx).
+		Where("record_id = ?", recordID).
+		Preload("Record").
+		First(&aliasRecord).Error
+	if err != nil {
+		return nil, err
+	}
+	return &aliasRecord, nil
+}
+
+// UpdateALIASRecord 更新ALIAS记录
+func (dao *RecordDAO) UpdateALIASRecord(ctx context.Context, record *model.Record, aliasRecord *model.ALIASRecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(record).Error; err != nil {
+			return err
+		}
+		return tx.Save(aliasRecord).Error
+	})
+}
+
+// DeleteALIASRecord 删除ALIAS记录
+func (dao *RecordDAO) DeleteALIASRecord(ctx context.Context, recordID uint) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("record_id = ?", recordID).Delete(&model.ALIASRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Record{}, recordID).Error
+	})
+}
+
+// ListALIASRecords 高级列表查询，支持视图筛选
+func (dao *RecordDAO) ListALIASRecords(ctx context.Context, viewID *int64) ([]model.ALIASRecord, error) {
+	var records []model.ALIASRecord
+	db := dao.db.WithContext(ctx).Preload("Record.Zone").Preload("Record.View")
+
+	if viewID != nil {
+		db = db.Joins("JOIN record ON record.id = record_alias.record_id").Where("record.view_id = ?", *viewID)
+	}
+
+	err := db.Find(&records).Error
+	return records, err
+}
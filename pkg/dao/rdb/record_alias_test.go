@@ -0,0 +1,37 @@
+package rdb
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestRecordDAO_Mock_CreateALIASRecord(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	baseRecord := &model.Record{ZoneID: 1, Name: "@", Type: "ALIAS", IsActive: true}
+	aliasRecord := &model.ALIASRecord{Target: "lb.example.net."}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record`")).
+		WithArgs(baseRecord.ZoneID, baseRecord.Name, baseRecord.Type, baseRecord.TTL, baseRecord.Remark, baseRecord.Tags, baseRecord.Source, baseRecord.IsActive, baseRecord.ViewID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record_alias`")).
+		WithArgs(1, aliasRecord.Target, aliasRecord.Remark).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = dao.CreateALIASRecord(ctx, baseRecord, aliasRecord)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), aliasRecord.RecordID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,93 @@
+package rdb
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// CreateDNAMERecord 创建DNAME记录
+func (dao *RecordDAO) CreateDNAMERecord(ctx context.Context, record *model.Record, dnameRecord *model.DNAMERecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		dnameRecord.RecordID = record.ID
+		return tx.Create(dnameRecord).Error
+	})
+}
+
+// GetDNAMERecords 获取DNAME记录
+func (dao *RecordDAO) GetDNAMERecords(ctx context.Context, zoneName, recordName string) ([]*model.DNAMERecord, error) {
+	var dnameRecords []*model.DNAMERecord
+	err := dao.db.WithContext(ctx).
+		Joins("JOIN record ON record.id = record_dname.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.name = ? AND zone.is_active = ? AND record.name = ? AND record.is_active = ?",
+			zoneName, true, recordName, true).
+		Preload("Record").
+		Order("record_dname.id ASC").
+		Find(&dnameRecords).Error
+	return dnameRecords, err
+}
+
+// GetDNAMERecordByID 根据记录ID获取DNAME记录
+func (dao *RecordDAO) GetDNAMERecordByID(ctx context.Context, recordID uint) (*model.DNAMERecord, error) {
+	var dnameRecord model.DNAMERecord
+	err := dao.db.WithContext(ctx).
+		Where("record_id = ?", recordID).
+		Preload("Record").
+		First(&dnameRecord).Error
+	if err != nil {
+		return nil, err
+	}
+	return &dnameRecord, nil
+}
+
+// UpdateDNAMERecord 更新DNAME记录
+func (dao *RecordDAO) UpdateDNAMERecord(ctx context.Context, record *model.Record, dnameRecord *model.DNAMERecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(record).Error; err != nil {
+			return err
+		}
+		return tx.Save(dnameRecord).Error
+	})
+}
+
+// DeleteDNAMERecord 删除DNAME记录
+func (dao *RecordDAO) DeleteDNAMERecord(ctx context.Context, recordID uint) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("record_id = ?", recordID).Delete(&model.DNAMERecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Record{}, recordID).Error
+	})
+}
+
+// ListDNAMERecords 高级列表查询，支持视图筛选
+func (dao *RecordDAO) ListDNAMERecords(ctx context.Context, viewID *int64) ([]model.DNAMERecord, error) {
+	var records []model.DNAMERecord
+	db := dao.db.WithContext(ctx).Preload("Record.Zone").Preload("Record.View")
+
+	if viewID != nil {
+		db = db.Joins("JOIN record ON record.id = record_dname.record_id").Where("record.view_id = ?", *viewID)
+	}
+
+	err := db.Find(&records).Error
+	return records, err
+}
+
+// GetDNAMEOwners 获取所有DNAME记录的所有者名称，用于构建重定向索引
+func (dao *RecordDAO) GetDNAMEOwners(ctx context.Context) ([]string, error) {
+	var names []string
+	err := dao.db.WithContext(ctx).
+		Model(&model.DNAMERecord{}).
+		Joins("JOIN record ON record.id = record_dname.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.is_active = ? AND record.is_active = ?", true, true).
+		Distinct().
+		Pluck("record.name", &names).Error
+	return names, err
+}
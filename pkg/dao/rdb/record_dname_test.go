@@ -0,0 +1,77 @@
+package rdb
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestRecordDAO_Mock_CreateDNAMERecord(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	baseRecord := &model.Record{ZoneID: 1, Name: "old.example.com.", Type: "DNAME", IsActive: true}
+	dnameRecord := &model.DNAMERecord{Target: "new.example.com."}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record`")).
+		WithArgs(baseRecord.ZoneID, baseRecord.Name, baseRecord.Type, baseRecord.TTL, baseRecord.Remark, baseRecord.Tags, baseRecord.Source, baseRecord.IsActive, baseRecord.ViewID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record_dname`")).
+		WithArgs(1, dnameRecord.Target, dnameRecord.Remark).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = dao.CreateDNAMERecord(ctx, baseRecord, dnameRecord)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), dnameRecord.RecordID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_GetDNAMEOwners(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"name"}).AddRow("old.example.com.")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT `record`.`name` FROM `record_dname` JOIN record ON record.id = record_dname.record_id JOIN zone ON zone.id = record.zone_id WHERE zone.is_active = ? AND record.is_active = ?")).
+		WithArgs(true, true).
+		WillReturnRows(rows)
+
+	names, err := dao.GetDNAMEOwners(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old.example.com."}, names)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_QueryDNAMERecords_Fallback(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+	expectViewChains(mock, [][2]int64{{10, 0}, {2, 0}})
+
+	// Nothing in the view, the default view's DNAME applies
+	mock.ExpectQuery(regexp.QuoteMeta("AND `record`.view_id = ?")).
+		WithArgs("example.com.", "old.example.com.", int64(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("AND ((`record`.view_id IS NULL OR `record`.view_id = 0))")).
+		WithArgs("example.com.", "old.example.com.").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "record_id", "target", "ttl"}).AddRow(1, 1, "new.example.com.", 300))
+
+	res, err := dao.QueryDNAMERecords(ctx, "example.com.", "old.example.com.", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, "new.example.com.", res[0].Target)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
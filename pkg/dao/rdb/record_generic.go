@@ -0,0 +1,80 @@
+package rdb
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// CreateGenericRecord 创建通用记录
+func (dao *RecordDAO) CreateGenericRecord(ctx context.Context, record *model.Record, genericRecord *model.GenericRecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		genericRecord.RecordID = record.ID
+		return tx.Create(genericRecord).Error
+	})
+}
+
+// GetGenericRecords 获取通用记录
+func (dao *RecordDAO) GetGenericRecords(ctx context.Context, zoneName, recordName string) ([]*model.GenericRecord, error) {
+	var genericRecords []*model.GenericRecord
+	err := dao.db.WithContext(ctx).
+		Joins("JOIN record ON record.id = record_generic.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.name = ? AND zone.is_active = ? AND record.name = ? AND record.is_active = ?",
+			zoneName, true, recordName, true).
+		Preload("Record").
+		Order("record_generic.id ASC").
+		Find(&genericRecords).Error
+	return genericRecords, err
+}
+
+// GetGenericRecordByID 根据记录ID获取通用记录
+func (dao *RecordDAO) GetGenericRecordByID(ctx context.Context, recordID uint) (*model.GenericRecord, error) {
+	var genericRecord model.GenericRecord
+	err := dao.db.WithContext(ctx).
+		Where("record_id = ?", recordID).
+		Preload("Record").
+		First(&genericRecord).Error
+	if err != nil {
+		return nil, err
+	}
+	return &genericRecord, nil
+}
+
+// UpdateGenericRecord 更新通用记录
+func (dao *RecordDAO) UpdateGenericRecord(ctx context.Context, record *model.Record, genericRecord *model.GenericRecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(record).Error; err != nil {
+			return err
+		}
+		return tx.Save(genericRecord).Error
+	})
+}
+
+// DeleteGenericRecord 删除通用记录
+func (dao *RecordDAO) DeleteGenericRecord(ctx context.Context, recordID uint) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("record_id = ?", recordID).Delete(&model.GenericRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Record{}, recordID).Error
+	})
+}
+
+// ListGenericRecords 高级列表查询，支持视图筛选
+func (dao *RecordDAO) ListGenericRecords(ctx context.Context, viewID *int64) ([]model.GenericRecord, error) {
+	var records []model.GenericRecord
+	db := dao.db.WithContext(ctx).Preload("Record.Zone").Preload("Record.View")
+
+	if viewID != nil {
+		db = db.Joins("JOIN record ON record.id = record_generic.record_id").Where("record.view_id = ?", *viewID)
+	}
+
+	err := db.Find(&records).Error
+	return records, err
+}
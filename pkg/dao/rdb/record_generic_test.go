@@ -0,0 +1,58 @@
+package rdb
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestRecordDAO_Mock_CreateGenericRecord(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	baseRecord := &model.Record{ZoneID: 1, Name: "_sip.example.com.", Type: "URI", IsActive: true}
+	genericRecord := &model.GenericRecord{RRType: 256, RData: `10 1 "sip:info@example.com"`}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record`")).
+		WithArgs(baseRecord.ZoneID, baseRecord.Name, baseRecord.Type, baseRecord.TTL, baseRecord.Remark, baseRecord.Tags, baseRecord.Source, baseRecord.IsActive, baseRecord.ViewID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record_generic` (`record_id`,`rr_type`,`rdata`,`remark`)")).
+		WithArgs(1, genericRecord.RRType, genericRecord.RData, genericRecord.Remark).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = dao.CreateGenericRecord(ctx, baseRecord, genericRecord)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), genericRecord.RecordID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_QueryGenericRecords(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"id", "record_id", "rr_type", "rdata", "ttl"}).
+		AddRow(1, 1, 256, `10 1 "sip:info@example.com"`, 300)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE (`zone`.name = ? AND `record`.name = ? AND `record_generic`.rr_type = ? AND `zone`.is_active = 1 AND `record`.is_active = 1) AND ((`record`.view_id IS NULL OR `record`.view_id = 0))")).
+		WithArgs("example.com.", "_sip.example.com.", uint16(256)).
+		WillReturnRows(rows)
+
+	res, err := dao.QueryGenericRecords(ctx, "example.com.", "_sip.example.com.", 256, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, uint16(256), res[0].RRType)
+		assert.Equal(t, uint32(300), res[0].TTL)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
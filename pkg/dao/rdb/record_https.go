@@ -0,0 +1,80 @@
+package rdb
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// CreateHTTPSRecord 创建HTTPS记录
+func (dao *RecordDAO) CreateHTTPSRecord(ctx context.Context, record *model.Record, httpsRecord *model.HTTPSRecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		httpsRecord.RecordID = record.ID
+		return tx.Create(httpsRecord).Error
+	})
+}
+
+// GetHTTPSRecords 获取HTTPS记录
+func (dao *RecordDAO) GetHTTPSRecords(ctx context.Context, zoneName, recordName string) ([]*model.HTTPSRecord, error) {
+	var httpsRecords []*model.HTTPSRecord
+	err := dao.db.WithContext(ctx).
+		Joins("JOIN record ON record.id = record_https.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.name = ? AND zone.is_active = ? AND record.name = ? AND record.is_active = ?",
+			zoneName, true, recordName, true).
+		Preload("Record").
+		Order("record_https.id ASC").
+		Find(&httpsRecords).Error
+	return httpsRecords, err
+}
+
+// GetHTTPSRecordByID 根据记录ID获取HTTPS记录
+func (dao *RecordDAO) GetHTTPSRecordByID(ctx context.Context, recordID uint) (*model.HTTPSRecord, error) {
+	var httpsRecord model.HTTPSRecord
+	err := dao.db.WithContext(ctx).
+		Where("record_id = ?", recordID).
+		Preload("Record").
+		First(&httpsRecord).Error
+	if err != nil {
+		return nil, err
+	}
+	return &httpsRecord, nil
+}
+
+// UpdateHTTPSRecord 更新HTTPS记录
+func (dao *RecordDAO) UpdateHTTPSRecord(ctx context.Context, record *model.Record, httpsRecord *model.HTTPSRecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(record).Error; err != nil {
+			return err
+		}
+		return tx.Save(httpsRecord).Error
+	})
+}
+
+// DeleteHTTPSRecord 删除HTTPS记录
+func (dao *RecordDAO) DeleteHTTPSRecord(ctx context.Context, recordID uint) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("record_id = ?", recordID).Delete(&model.HTTPSRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Record{}, recordID).Error
+	})
+}
+
+// ListHTTPSRecords 高级列表查询，支持视图筛选
+func (dao *RecordDAO) ListHTTPSRecords(ctx context.Context, viewID *int64) ([]model.HTTPSRecord, error) {
+	var records []model.HTTPSRecord
+	db := dao.db.WithContext(ctx).Preload("Record.Zone").Preload("Record.View")
+
+	if viewID != nil {
+		db = db.Joins("JOIN record ON record.id = record_https.record_id").Where("record.view_id = ?", *viewID)
+	}
+
+	err := db.Find(&records).Error
+	return records, err
+}
@@ -78,3 +78,16 @@ func (dao *RecordDAO) ListNSRecords(ctx context.Context, viewID *int64) ([]model
 	err := db.Find(&records).Error
 	return records, err
 }
+
+// GetDelegationNames 获取所有区域切割点名称（非区域顶点的NS记录所有者）
+func (dao *RecordDAO) GetDelegationNames(ctx context.Context) ([]string, error) {
+	var names []string
+	err := dao.db.WithContext(ctx).
+		Model(&model.NSRecord{}).
+		Joins("JOIN record ON record.id = record_ns.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.is_active = ? AND record.is_active = ? AND record.name <> zone.name AND record.name <> ?", true, true, "@").
+		Distinct().
+		Pluck("record.name", &names).Error
+	return names, err
+}
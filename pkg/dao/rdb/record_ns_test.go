@@ -96,3 +96,20 @@ func TestRecordDAO_Mock_ListNSRecords(t *testing.T) {
 	assert.Equal(t, "LOCAL", res[0].Record.View.Name)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestRecordDAO_Mock_GetDelegationNames(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"name"}).AddRow("team.example.com.")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT `record`.`name` FROM `record_ns` JOIN record ON record.id = record_ns.record_id JOIN zone ON zone.id = record.zone_id WHERE zone.is_active = ? AND record.is_active = ? AND record.name <> zone.name AND record.name <> ?")).
+		WithArgs(true, true, "@").
+		WillReturnRows(rows)
+
+	names, err := dao.GetDelegationNames(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"team.example.com."}, names)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,289 @@
+package rdb
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// CreatePTRRecord 创建PTR记录
+func (dao *RecordDAO) CreatePTRRecord(ctx context.Context, record *model.Record, ptrRecord *model.PTRRecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		ptrRecord.RecordID = record.ID
+		return tx.Create(ptrRecord).Error
+	})
+}
+
+// GetPTRRecords 获取PTR记录
+func (dao *RecordDAO) GetPTRRecords(ctx context.Context, zoneName, recordName string) ([]*model.PTRRecord, error) {
+	var ptrRecords []*model.PTRRecord
+	err := dao.db.WithContext(ctx).
+		Joins("JOIN record ON record.id = record_ptr.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.name = ? AND zone.is_active = ? AND record.name = ? AND record.is_active = ?",
+			zoneName, true, recordName, true).
+		Preload("Record").
+		Order("record_ptr.id ASC").
+		Find(&ptrRecords).Error
+	return ptrRecords, err
+}
+
+// GetPTRRecordByID 根据记录ID获取PTR记录
+func (dao *RecordDAO) GetPTRRecordByID(ctx context.Context, recordID uint) (*model.PTRRecord, error) {
+	var ptrRecord model.PTRRecord
+	err := dao.db.WithContext(ctx).
+		Where("record_id = ?", recordID).
+		Preload("Record").
+		First(&ptrRecord).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ptrRecord, nil
+}
+
+// UpdatePTRRecord 更新PTR记录
+func (dao *RecordDAO) UpdatePTRRecord(ctx context.Context, record *model.Record, ptrRecord *model.PTRRecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(record).Error; err != nil {
+			return err
+		}
+		return tx.Save(ptrRecord).Error
+	})
+}
+
+// DeletePTRRecord 删除PTR记录
+func (dao *RecordDAO) DeletePTRRecord(ctx context.Context, recordID uint) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("record_id = ?", recordID).Delete(&model.PTRRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Record{}, recordID).Error
+	})
+}
+
+// ListPTRRecords 高级列表查询，支持视图筛选
+func (dao *RecordDAO) ListPTRRecords(ctx context.Context, viewID *int64) ([]model.PTRRecord, error) {
+	var records []model.PTRRecord
+	db := dao.db.WithContext(ctx).Preload("Record.Zone").Preload("Record.View")
+
+	if viewID != nil {
+		db = db.Joins("JOIN record ON record.id = record_ptr.record_id").Where("record.view_id = ?", *viewID)
+	}
+
+	err := db.Find(&records).Error
+	return records, err
+}
+
+// 反向解析检查状态
+const (
+	ReverseStatusMissing  = "missing"  // 缺少PTR记录
+	ReverseStatusMismatch = "mismatch" // PTR记录指向其他域名
+)
+
+// ReverseCheck A/AAAA记录的反向解析检查结果
+type ReverseCheck struct {
+	RecordID    int64    `json:"record_id"`
+	Zone        string   `json:"zone"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	IP          string   `json:"ip"`
+	ReverseName string   `json:"reverse_name"`
+	PTR         []string `json:"ptr"`
+	Status      string   `json:"status"`
+}
+
+// forwardAddress 正向记录查询结果
+type forwardAddress struct {
+	RecordID int64
+	Zone     string
+	Name     string
+	AutoPTR  bool
+	IP       []byte
+}
+
+// GetReverseReport 列出反向解析缺失或指向其他域名的A/AAAA记录
+// 开启自动PTR的zone中的记录，只要没有显式PTR指向其他域名即视为一致
+func (dao *RecordDAO) GetReverseReport(ctx context.Context) ([]*ReverseCheck, error) {
+	var aRows []struct {
+		RecordID int64
+		Zone     string
+		Name     string
+		AutoPTR  bool
+		IP       uint32
+	}
+	err := dao.db.WithContext(ctx).
+		Table("record_a").
+		Select("record.id AS record_id, zone.name AS zone, record.name AS name, zone.auto_ptr AS auto_ptr, record_a.ip AS ip").
+		Joins("JOIN record ON record.id = record_a.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.is_active = ? AND record.is_active = ?", true, true).
+		Scan(&aRows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var aaaaRows []forwardAddress
+	err = dao.db.WithContext(ctx).
+		Table("record_aaaa").
+		Select("record.id AS record_id, zone.name AS zone, record.name AS name, zone.auto_ptr AS auto_ptr, record_aaaa.ip AS ip").
+		Joins("JOIN record ON record.id = record_aaaa.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.is_active = ? AND record.is_active = ?", true, true).
+		Scan(&aaaaRows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var ptrRows []struct {
+		Zone     string
+		Name     string
+		PTRDName string `gorm:"column:ptrdname"`
+	}
+	err = dao.db.WithContext(ctx).
+		Table("record_ptr").
+		Select("zone.name AS zone, record.name AS name, record_ptr.ptrdname AS ptrdname").
+		Joins("JOIN record ON record.id = record_ptr.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.is_active = ? AND record.is_active = ?", true, true).
+		Scan(&ptrRows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 反向名称 -> PTR目标列表，目标按解析器应答时的方式补全为FQDN，不补全zone
+	reverse := make(map[string][]string)
+	for _, row := range ptrRows {
+		name := qualifyName(row.Name, row.Zone)
+		reverse[name] = append(reverse[name], strings.ToLower(dns.Fqdn(row.PTRDName)))
+	}
+
+	forward := make([]forwardAddress, 0, len(aRows)+len(aaaaRows))
+	for _, row := range aRows {
+		ip := make([]byte, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, row.IP)
+		forward = append(forward, forwardAddress{RecordID: row.RecordID, Zone: row.Zone, Name: row.Name, AutoPTR: row.AutoPTR, IP: ip})
+	}
+	forward = append(forward, aaaaRows...)
+
+	var report []*ReverseCheck
+	for _, row := range forward {
+		ip := net.IP(row.IP)
+		rev, err := dns.ReverseAddr(ip.String())
+		if err != nil {
+			continue
+		}
+
+		name := qualifyName(row.Name, row.Zone)
+		ptrs := reverse[rev]
+
+		var status string
+		switch {
+		case len(ptrs) == 0 && !row.AutoPTR:
+			status = ReverseStatusMissing
+		case len(ptrs) > 0 && !containsName(ptrs, name):
+			status = ReverseStatusMismatch
+		default:
+			continue
+		}
+
+		recordType := "AAAA"
+		if ip.To4() != nil {
+			recordType = "A"
+		}
+		report = append(report, &ReverseCheck{
+			RecordID:    row.RecordID,
+			Zone:        row.Zone,
+			Name:        name,
+			Type:        recordType,
+			IP:          ip.String(),
+			ReverseName: rev,
+			PTR:         ptrs,
+			Status:      status,
+		})
+	}
+	return report, nil
+}
+
+// GetAutoPTRNetworks 获取开启自动PTR的zone中A/AAAA记录所在网段的反向名称，
+// IPv4按/24、IPv6按/64划分，用于判断反向查询是否可能自动生成PTR
+func (dao *RecordDAO) GetAutoPTRNetworks(ctx context.Context) ([]string, error) {
+	var v4 []uint32
+	err := dao.db.WithContext(ctx).
+		Table("record_a").
+		Joins("JOIN record ON record.id = record_a.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.auto_ptr = ? AND zone.is_active = ? AND record.is_active = ?", true, true, true).
+		Distinct().
+		Pluck("record_a.ip", &v4).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var v6 [][]byte
+	err = dao.db.WithContext(ctx).
+		Table("record_aaaa").
+		Joins("JOIN record ON record.id = record_aaaa.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.auto_ptr = ? AND zone.is_active = ? AND record.is_active = ?", true, true, true).
+		Distinct().
+		Pluck("record_aaaa.ip", &v6).Error
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var names []string
+	add := func(name string) {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	for _, ip := range v4 {
+		add(fmt.Sprintf("%d.%d.%d.in-addr.arpa.", byte(ip>>8), byte(ip>>16), byte(ip>>24)))
+	}
+	for _, ip := range v6 {
+		// IPv4映射地址不会被IPv6反向查询命中
+		if len(ip) != net.IPv6len || net.IP(ip).To4() != nil {
+			continue
+		}
+		rev, err := dns.ReverseAddr(net.IP(ip).String())
+		if err != nil {
+			continue
+		}
+		// 去掉接口标识的16个半字节标签
+		add(rev[32:])
+	}
+	return names, nil
+}
+
+// qualifyName 将相对名称补全为zone下的FQDN并转为小写
+func qualifyName(name, zone string) string {
+	switch {
+	case name == "@" || name == "":
+		name = zone
+	case !strings.HasSuffix(name, "."):
+		name = name + "." + zone
+	}
+	return strings.ToLower(dns.Fqdn(name))
+}
+
+// containsName 判断名称列表中是否包含指定名称
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
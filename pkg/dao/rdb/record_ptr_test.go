@@ -0,0 +1,107 @@
+package rdb
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestRecordDAO_Mock_CreatePTRRecord(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	baseRecord := &model.Record{ZoneID: 1, Name: "4.3.2.1.in-addr.arpa.", Type: "PTR", IsActive: true}
+	ptrRecord := &model.PTRRecord{PTRDName: "www.example.com."}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record`")).
+		WithArgs(baseRecord.ZoneID, baseRecord.Name, baseRecord.Type, baseRecord.TTL, baseRecord.Remark, baseRecord.Tags, baseRecord.Source, baseRecord.IsActive, baseRecord.ViewID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record_ptr` (`record_id`,`ptrdname`,`remark`)")).
+		WithArgs(1, ptrRecord.PTRDName, ptrRecord.Remark).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = dao.CreatePTRRecord(ctx, baseRecord, ptrRecord)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), ptrRecord.RecordID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_GetReverseReport(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	aRows := sqlmock.NewRows([]string{"record_id", "zone", "name", "auto_ptr", "ip"}).
+		AddRow(1, "example.com.", "www", false, 0x01020304).                // PTR points to www, consistent
+		AddRow(2, "example.com.", "mail", false, 0x01020305).               // no PTR
+		AddRow(3, "example.com.", "api", false, 0x01020306).                // PTR points elsewhere
+		AddRow(4, "auto.example.", "host.auto.example.", true, 0x0a000001). // generated
+		AddRow(6, "example.com.", "ftp", false, 0x01020307)                 // PTR target without the trailing dot, consistent
+	aaaaRows := sqlmock.NewRows([]string{"record_id", "zone", "name", "auto_ptr", "ip"}).
+		AddRow(5, "example.com.", "v6", false, []byte(net.ParseIP("2001:db8::1")))
+	ptrRows := sqlmock.NewRows([]string{"zone", "name", "ptrdname"}).
+		AddRow("3.2.1.in-addr.arpa.", "4", "www.example.com.").
+		AddRow("3.2.1.in-addr.arpa.", "6.3.2.1.in-addr.arpa.", "legacy.example.com.").
+		AddRow("3.2.1.in-addr.arpa.", "7", "FTP.example.com")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT record.id AS record_id, zone.name AS zone, record.name AS name, zone.auto_ptr AS auto_ptr, record_a.ip AS ip FROM `record_a`")).
+		WithArgs(true, true).WillReturnRows(aRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT record.id AS record_id, zone.name AS zone, record.name AS name, zone.auto_ptr AS auto_ptr, record_aaaa.ip AS ip FROM `record_aaaa`")).
+		WithArgs(true, true).WillReturnRows(aaaaRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT zone.name AS zone, record.name AS name, record_ptr.ptrdname AS ptrdname FROM `record_ptr`")).
+		WithArgs(true, true).WillReturnRows(ptrRows)
+
+	report, err := dao.GetReverseReport(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	if assert.Len(t, report, 3) {
+		assert.Equal(t, "mail.example.com.", report[0].Name)
+		assert.Equal(t, ReverseStatusMissing, report[0].Status)
+		assert.Equal(t, "5.3.2.1.in-addr.arpa.", report[0].ReverseName)
+
+		assert.Equal(t, "api.example.com.", report[1].Name)
+		assert.Equal(t, ReverseStatusMismatch, report[1].Status)
+		assert.Equal(t, []string{"legacy.example.com."}, report[1].PTR)
+
+		assert.Equal(t, "AAAA", report[2].Type)
+		assert.Equal(t, ReverseStatusMissing, report[2].Status)
+	}
+}
+
+func TestRecordDAO_Mock_GetAutoPTRNetworks(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT `record_a`.`ip` FROM `record_a` JOIN record ON record.id = record_a.record_id JOIN zone ON zone.id = record.zone_id WHERE zone.auto_ptr = ? AND zone.is_active = ? AND record.is_active = ?")).
+		WithArgs(true, true, true).
+		WillReturnRows(sqlmock.NewRows([]string{"ip"}).AddRow(0x0a000001).AddRow(0x0a000002).AddRow(0xc0000201))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT `record_aaaa`.`ip` FROM `record_aaaa` JOIN record ON record.id = record_aaaa.record_id JOIN zone ON zone.id = record.zone_id WHERE zone.auto_ptr = ? AND zone.is_active = ? AND record.is_active = ?")).
+		WithArgs(true, true, true).
+		WillReturnRows(sqlmock.NewRows([]string{"ip"}).
+			AddRow([]byte(net.ParseIP("2001:db8::1"))).
+			AddRow([]byte(net.ParseIP("::ffff:10.0.0.1"))))
+
+	names, err := dao.GetAutoPTRNetworks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"0.0.10.in-addr.arpa.",
+		"2.0.192.in-addr.arpa.",
+		"0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.",
+	}, names)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
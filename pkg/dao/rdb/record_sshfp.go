@@ -0,0 +1,80 @@
+package rdb
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// CreateSSHFPRecord 创建SSHFP记录
+func (dao *RecordDAO) CreateSSHFPRecord(ctx context.Context, record *model.Record, sshfpRecord *model.SSHFPRecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		sshfpRecord.RecordID = record.ID
+		return tx.Create(sshfpRecord).Error
+	})
+}
+
+// GetSSHFPRecords 获取SSHFP记录
+func (dao *RecordDAO) GetSSHFPRecords(ctx context.Context, zoneName, recordName string) ([]*model.SSHFPRecord, error) {
+	var sshfpRecords []*model.SSHFPRecord
+	err := dao.db.WithContext(ctx).
+		Joins("JOIN record ON record.id = record_sshfp.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.name = ? AND zone.is_active = ? AND record.name = ? AND record.is_active = ?",
+			zoneName, true, recordName, true).
+		Preload("Record").
+		Order("record_sshfp.id ASC").
+		Find(&sshfpRecords).Error
+	return sshfpRecords, err
+}
+
+// GetSSHFPRecordByID 根据记录ID获取SSHFP记录
+func (dao *RecordDAO) GetSSHFPRecordByID(ctx context.Context, recordID uint) (*model.SSHFPRecord, error) {
+	var sshfpRecord model.SSHFPRecord
+	err := dao.db.WithContext(ctx).
+		Where("record_id = ?", recordID).
+		Preload("Record").
+		First(&sshfpRecord).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sshfpRecord, nil
+}
+
+// UpdateSSHFPRecord 更新SSHFP记录
+func (dao *RecordDAO) UpdateSSHFPRecord(ctx context.Context, record *model.Record, sshfpRecord *model.SSHFPRecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(record).Error; err != nil {
+			return err
+		}
+		return tx.Save(sshfpRecord).Error
+	})
+}
+
+// DeleteSSHFPRecord 删除SSHFP记录
+func (dao *RecordDAO) DeleteSSHFPRecord(ctx context.Context, recordID uint) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("record_id = ?", recordID).Delete(&model.SSHFPRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Record{}, recordID).Error
+	})
+}
+
+// ListSSHFPRecords 高级列表查询，支持视图筛选
+func (dao *RecordDAO) ListSSHFPRecords(ctx context.Context, viewID *int64) ([]model.SSHFPRecord, error) {
+	var records []model.SSHFPRecord
+	db := dao.db.WithContext(ctx).Preload("Record.Zone").Preload("Record.View")
+
+	if viewID != nil {
+		db = db.Joins("JOIN record ON record.id = record_sshfp.record_id").Where("record.view_id = ?", *viewID)
+	}
+
+	err := db.Find(&records).Error
+	return records, err
+}
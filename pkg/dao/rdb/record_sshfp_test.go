@@ -0,0 +1,37 @@
+package rdb
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestRecordDAO_Mock_CreateSSHFPRecord(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	baseRecord := &model.Record{ZoneID: 1, Name: "host.example.com.", Type: "SSHFP", IsActive: true}
+	sshfpRecord := &model.SSHFPRecord{Algorithm: 4, Type: 2, FingerPrint: "244236f4073086345acd8effecad97e50c80559de958608c1b985850f4cc97d4"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record`")).
+		WithArgs(baseRecord.ZoneID, baseRecord.Name, baseRecord.Type, baseRecord.TTL, baseRecord.Remark, baseRecord.Tags, baseRecord.Source, baseRecord.IsActive, baseRecord.ViewID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record_sshfp` (`record_id`,`algorithm`,`type`,`fingerprint`,`remark`)")).
+		WithArgs(1, sshfpRecord.Algorithm, sshfpRecord.Type, sshfpRecord.FingerPrint, sshfpRecord.Remark).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = dao.CreateSSHFPRecord(ctx, baseRecord, sshfpRecord)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), sshfpRecord.RecordID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
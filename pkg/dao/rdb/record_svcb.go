@@ -0,0 +1,80 @@
+package rdb
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// CreateSVCBRecord 创建SVCB记录
+func (dao *RecordDAO) CreateSVCBRecord(ctx context.Context, record *model.Record, svcbRecord *model.SVCBRecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		svcbRecord.RecordID = record.ID
+		return tx.Create(svcbRecord).Error
+	})
+}
+
+// GetSVCBRecords 获取SVCB记录
+func (dao *RecordDAO) GetSVCBRecords(ctx context.Context, zoneName, recordName string) ([]*model.SVCBRecord, error) {
+	var svcbRecords []*model.SVCBRecord
+	err := dao.db.WithContext(ctx).
+		Joins("JOIN record ON record.id = record_svcb.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.name = ? AND zone.is_active = ? AND record.name = ? AND record.is_active = ?",
+			zoneName, true, recordName, true).
+		Preload("Record").
+		Order("record_svcb.id ASC").
+		Find(&svcbRecords).Error
+	return svcbRecords, err
+}
+
+// GetSVCBRecordByID 根据记录ID获取SVCB记录
+func (dao *RecordDAO) GetSVCBRecordByID(ctx context.Context, recordID uint) (*model.SVCBRecord, error) {
+	var svcbRecord model.SVCBRecord
+	err := dao.db.WithContext(ctx).
+		Where("record_id = ?", recordID).
+		Preload("Record").
+		First(&svcbRecord).Error
+	if err != nil {
+		return nil, err
+	}
+	return &svcbRecord, nil
+}
+
+// UpdateSVCBRecord 更新SVCB记录
+func (dao *RecordDAO) UpdateSVCBRecord(ctx context.Context, record *model.Record, svcbRecord *model.SVCBRecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(record).Error; err != nil {
+			return err
+		}
+		return tx.Save(svcbRecord).Error
+	})
+}
+
+// DeleteSVCBRecord 删除SVCB记录
+func (dao *RecordDAO) DeleteSVCBRecord(ctx context.Context, recordID uint) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("record_id = ?", recordID).Delete(&model.SVCBRecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Record{}, recordID).Error
+	})
+}
+
+// ListSVCBRecords 高级列表查询，支持视图筛选
+func (dao *RecordDAO) ListSVCBRecords(ctx context.Context, viewID *int64) ([]model.SVCBRecord, error) {
+	var records []model.SVCBRecord
+	db := dao.db.WithContext(ctx).Preload("Record.Zone").Preload("Record.View")
+
+	if viewID != nil {
+		db = db.Joins("JOIN record ON record.id = record_svcb.record_id").Where("record.view_id = ?", *viewID)
+	}
+
+	err := db.Find(&records).Error
+	return records, err
+}
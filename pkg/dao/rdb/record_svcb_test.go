@@ -0,0 +1,65 @@
+package rdb
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestRecordDAO_Mock_CreateSVCBRecord(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	baseRecord := &model.Record{ZoneID: 1, Name: "_8443._foo.api.example.com.", Type: "SVCB", IsActive: true}
+	svcbRecord := &model.SVCBRecord{
+		Priority:  1,
+		Target:    "svc.example.net.",
+		SvcParams: model.SvcParams{ALPN: "bar", Port: 8004},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record`")).
+		WithArgs(baseRecord.ZoneID, baseRecord.Name, baseRecord.Type, baseRecord.TTL, baseRecord.Remark, baseRecord.Tags, baseRecord.Source, baseRecord.IsActive, baseRecord.ViewID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record_svcb` (`record_id`,`priority`,`target`,`alpn`,`no_default_alpn`,`port`,`ipv4hint`,`ipv6hint`,`ech`,`remark`)")).
+		WithArgs(1, svcbRecord.Priority, svcbRecord.Target, "bar", false, uint16(8004), "", "", "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = dao.CreateSVCBRecord(ctx, baseRecord, svcbRecord)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), svcbRecord.RecordID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_QueryHTTPSRecords_Fallback(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+	expectViewChains(mock, [][2]int64{{7, 0}})
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_https`.*, `record`.ttl FROM `record_https`")).
+		WithArgs("example.com.", "example.com.", int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY `record_https`.priority ASC, `record_https`.id ASC")).
+		WithArgs("example.com.", "example.com.").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "record_id", "priority", "target", "alpn", "ttl"}).
+			AddRow(1, 1, 1, ".", "h2,h3", 300))
+
+	res, err := dao.QueryHTTPSRecords(ctx, "example.com.", "example.com.", 7)
+	assert.NoError(t, err)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, "h2,h3", res[0].ALPN)
+		assert.Equal(t, uint32(300), res[0].TTL)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,80 @@
+package rdb
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// CreateTLSARecord 创建TLSA记录
+func (dao *RecordDAO) CreateTLSARecord(ctx context.Context, record *model.Record, tlsaRecord *model.TLSARecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		tlsaRecord.RecordID = record.ID
+		return tx.Create(tlsaRecord).Error
+	})
+}
+
+// GetTLSARecords 获取TLSA记录
+func (dao *RecordDAO) GetTLSARecords(ctx context.Context, zoneName, recordName string) ([]*model.TLSARecord, error) {
+	var tlsaRecords []*model.TLSARecord
+	err := dao.db.WithContext(ctx).
+		Joins("JOIN record ON record.id = record_tlsa.record_id").
+		Joins("JOIN zone ON zone.id = record.zone_id").
+		Where("zone.name = ? AND zone.is_active = ? AND record.name = ? AND record.is_active = ?",
+			zoneName, true, recordName, true).
+		Preload("Record").
+		Order("record_tlsa.id ASC").
+		Find(&tlsaRecords).Error
+	return tlsaRecords, err
+}
+
+// GetTLSARecordByID 根据记录ID获取TLSA记录
+func (dao *RecordDAO) GetTLSARecordByID(ctx context.Context, recordID uint) (*model.TLSARecord, error) {
+	var tlsaRecord model.TLSARecord
+	err := dao.db.WithContext(ctx).
+		Where("record_id = ?", recordID).
+		Preload("Record").
+		First(&tlsaRecord).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tlsaRecord, nil
+}
+
+// UpdateTLSARecord 更新TLSA记录
+func (dao *RecordDAO) UpdateTLSARecord(ctx context.Context, record *model.Record, tlsaRecord *model.TLSARecord) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(record).Error; err != nil {
+			return err
+		}
+		return tx.Save(tlsaRecord).Error
+	})
+}
+
+// DeleteTLSARecord 删除TLSA记录
+func (dao *RecordDAO) DeleteTLSARecord(ctx context.Context, recordID uint) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("record_id = ?", recordID).Delete(&model.TLSARecord{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Record{}, recordID).Error
+	})
+}
+
+// ListTLSARecords 高级列表查询，支持视图筛选
+func (dao *RecordDAO) ListTLSARecords(ctx context.Context, viewID *int64) ([]model.TLSARecord, error) {
+	var records []model.TLSARecord
+	db := dao.db.WithContext(ctx).Preload("Record.Zone").Preload("Record.View")
+
+	if viewID != nil {
+		db = db.Joins("JOIN record ON record.id = record_tlsa.record_id").Where("record.view_id = ?", *viewID)
+	}
+
+	err := db.Find(&records).Error
+	return records, err
+}
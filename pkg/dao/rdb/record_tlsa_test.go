@@ -0,0 +1,37 @@
+package rdb
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestRecordDAO_Mock_CreateTLSARecord(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	baseRecord := &model.Record{ZoneID: 1, Name: "_25._tcp.mail.example.com.", Type: "TLSA", IsActive: true}
+	tlsaRecord := &model.TLSARecord{Usage: 3, Selector: 1, MatchingType: 1, Certificate: "2fa298cacf0fe340542ad61a491b776cd8ef56429130a13538a5e8ce07e70c33"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record`")).
+		WithArgs(baseRecord.ZoneID, baseRecord.Name, baseRecord.Type, baseRecord.TTL, baseRecord.Remark, baseRecord.Tags, baseRecord.Source, baseRecord.IsActive, baseRecord.ViewID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `record_tlsa` (`record_id`,`usage`,`selector`,`matching_type`,`certificate`,`remark`)")).
+		WithArgs(1, tlsaRecord.Usage, tlsaRecord.Selector, tlsaRecord.MatchingType, tlsaRecord.Certificate, tlsaRecord.Remark).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = dao.CreateTLSARecord(ctx, baseRecord, tlsaRecord)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), tlsaRecord.RecordID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
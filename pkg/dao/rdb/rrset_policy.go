@@ -0,0 +1,55 @@
+package rdb
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// RRSetPolicyDAO 记录集选择策略的关系型数据访问层
+type RRSetPolicyDAO struct {
+	db *gorm.DB
+}
+
+// NewRRSetPolicyDAO 创建RRSetPolicyDAO实例
+func NewRRSetPolicyDAO(db *gorm.DB) *RRSetPolicyDAO {
+	return &RRSetPolicyDAO{db: db}
+}
+
+// Create 创建记录集选择策略
+func (dao *RRSetPolicyDAO) Create(ctx context.Context, policy *model.RRSetPolicy) error {
+	return dao.db.WithContext(ctx).Create(policy).Error
+}
+
+// GetByID 根据ID获取记录集选择策略
+func (dao *RRSetPolicyDAO) GetByID(ctx context.Context, id int64) (*model.RRSetPolicy, error) {
+	var policy model.RRSetPolicy
+	err := dao.db.WithContext(ctx).First(&policy, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetAll 获取所有记录集选择策略，zoneID大于0时只返回该zone的策略
+func (dao *RRSetPolicyDAO) GetAll(ctx context.Context, zoneID int64) ([]*model.RRSetPolicy, error) {
+	var policies []*model.RRSetPolicy
+	query := dao.db.WithContext(ctx)
+	if zoneID > 0 {
+		query = query.Where("zone_id = ?", zoneID)
+	}
+	err := query.Order("id ASC").Find(&policies).Error
+	return policies, err
+}
+
+// Update 更新记录集选择策略
+func (dao *RRSetPolicyDAO) Update(ctx context.Context, policy *model.RRSetPolicy) error {
+	return dao.db.WithContext(ctx).Save(policy).Error
+}
+
+// Delete 删除记录集选择策略
+func (dao *RRSetPolicyDAO) Delete(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Delete(&model.RRSetPolicy{}, id).Error
+}
@@ -0,0 +1,53 @@
+package rdb
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestRRSetPolicyDAO_Mock_Create(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRRSetPolicyDAO(db)
+	ctx := context.Background()
+
+	policy := &model.RRSetPolicy{ZoneID: 1, Name: "www.example.com.", Type: "A", Policy: model.PolicyWeighted, Count: 2}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `rrset_policy`")).
+		WithArgs(policy.ZoneID, policy.Name, policy.Type, policy.ViewID, policy.Policy, policy.Count, policy.Remark, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = dao.Create(ctx, policy)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), policy.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRRSetPolicyDAO_Mock_GetAll(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRRSetPolicyDAO(db)
+	ctx := context.Background()
+
+	rows := sqlmock.NewRows([]string{"id", "zone_id", "name", "type", "view_id", "policy", "count"}).
+		AddRow(1, 1, "www.example.com.", "A", 0, "round_robin", 0)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `rrset_policy` WHERE zone_id = ? ORDER BY id ASC")).
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	res, err := dao.GetAll(ctx, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, model.PolicyRoundRobin, res[0].Policy)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
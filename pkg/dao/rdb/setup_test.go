@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"regexp"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"gorm.io/driver/mysql"
@@ -44,3 +45,19 @@ func mustSetupMockDB() (*gorm.DB, sqlmock.Sqlmock) {
 func createTestContext() context.Context {
 	return context.Background()
 }
+
+// expectViewChains expects the view chain snapshot load. views holds
+// {id, parent_id} pairs, denying the views that have deny entries.
+func expectViewChains(mock sqlmock.Sqlmock, views [][2]int64, denying ...int64) {
+	viewRows := sqlmock.NewRows([]string{"id", "parent_id"})
+	for _, v := range views {
+		viewRows.AddRow(v[0], v[1])
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, parent_id FROM `view`")).WillReturnRows(viewRows)
+
+	denyRows := sqlmock.NewRows([]string{"view_id"})
+	for _, id := range denying {
+		denyRows.AddRow(id)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT `view_id` FROM `view_deny`")).WillReturnRows(denyRows)
+}
@@ -2,6 +2,8 @@ package rdb
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"gorm.io/gorm"
 
@@ -18,44 +20,108 @@ func NewViewDAO(db *gorm.DB) *ViewDAO {
 	return &ViewDAO{db: db}
 }
 
-// Create 创建View
+// Create 创建View及其条件
 func (dao *ViewDAO) Create(ctx context.Context, view *model.View) error {
 	return dao.db.WithContext(ctx).Create(view).Error
 }
 
-// GetByID 根据ID获取View
+// GetByID 根据ID获取View及其条件
 func (dao *ViewDAO) GetByID(ctx context.Context, id int64) (*model.View, error) {
 	var view model.View
-	err := dao.db.WithContext(ctx).First(&view, id).Error
+	err := dao.db.WithContext(ctx).Preload("Rules").First(&view, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &view, nil
 }
 
-// GetByName 根据名称获取View
+// GetByName 根据名称获取View及其条件
 func (dao *ViewDAO) GetByName(ctx context.Context, name string) (*model.View, error) {
 	var view model.View
-	err := dao.db.WithContext(ctx).Where("name = ?", name).First(&view).Error
+	err := dao.db.WithContext(ctx).Preload("Rules").Where("name = ?", name).First(&view).Error
 	if err != nil {
 		return nil, err
 	}
 	return &view, nil
 }
 
-// GetAll 获取所有View
+// GetAll 按匹配顺序获取所有View及其条件
 func (dao *ViewDAO) GetAll(ctx context.Context) ([]*model.View, error) {
 	var views []*model.View
-	err := dao.db.WithContext(ctx).Find(&views).Error
+	err := dao.db.WithContext(ctx).Preload("Rules").Order("priority DESC, id ASC").Find(&views).Error
 	return views, err
 }
 
-// Update 更新View
+// Update 更新View，并用view.Rules替换原有条件
 func (dao *ViewDAO) Update(ctx context.Context, view *model.View) error {
-	return dao.db.WithContext(ctx).Save(view).Error
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Rules").Save(view).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("view_id = ?", view.ID).Delete(&model.ViewRule{}).Error; err != nil {
+			return err
+		}
+		if len(view.Rules) == 0 {
+			return nil
+		}
+		for i := range view.Rules {
+			view.Rules[i].ID = 0
+			view.Rules[i].ViewID = view.ID
+		}
+		return tx.Create(&view.Rules).Error
+	})
 }
 
-// Delete 删除View
+// Delete 删除View及其条件与屏蔽条目，子视图改为直接回退到默认视图
 func (dao *ViewDAO) Delete(ctx context.Context, id int64) error {
-	return dao.db.WithContext(ctx).Delete(&model.View{}, id).Error
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("view_id = ?", id).Delete(&model.ViewRule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("view_id = ?", id).Delete(&model.ViewDeny{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.View{}).Where("parent_id = ?", id).Update("parent_id", 0).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.View{}, id).Error
+	})
+}
+
+// CheckParent 检查将parentID设为viewID的父视图是否合法：父视图必须存在，且不能形成环
+func (dao *ViewDAO) CheckParent(ctx context.Context, viewID, parentID int64) error {
+	for depth, id := 0, parentID; id > 0; depth++ {
+		if id == viewID {
+			return fmt.Errorf("parent view %d would create a cycle", parentID)
+		}
+		if depth >= maxViewDepth {
+			return fmt.Errorf("view chain is deeper than %d", maxViewDepth)
+		}
+		var parent model.View
+		if err := dao.db.WithContext(ctx).Select("id, parent_id").First(&parent, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("parent view %d does not exist", id)
+			}
+			return err
+		}
+		id = parent.ParentID
+	}
+	return nil
+}
+
+// GetDenies 获取视图的所有屏蔽条目
+func (dao *ViewDAO) GetDenies(ctx context.Context, viewID int64) ([]*model.ViewDeny, error) {
+	var denies []*model.ViewDeny
+	err := dao.db.WithContext(ctx).Where("view_id = ?", viewID).Order("id ASC").Find(&denies).Error
+	return denies, err
+}
+
+// CreateDeny 创建屏蔽条目
+func (dao *ViewDAO) CreateDeny(ctx context.Context, deny *model.ViewDeny) error {
+	return dao.db.WithContext(ctx).Create(deny).Error
+}
+
+// DeleteDeny 删除视图的屏蔽条目
+func (dao *ViewDAO) DeleteDeny(ctx context.Context, viewID, id int64) error {
+	return dao.db.WithContext(ctx).Where("view_id = ?", viewID).Delete(&model.ViewDeny{}, id).Error
 }
@@ -0,0 +1,163 @@
+package rdb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+const (
+	// viewChainTTL 视图继承链缓存的有效期
+	viewChainTTL = 30 * time.Second
+	// maxViewDepth 视图继承链的最大深度，防止父视图配置成环
+	maxViewDepth = 16
+)
+
+// viewScope 将查询限定到单个视图的条件，默认视图兼容view_id为NULL的旧数据
+type viewScope struct {
+	match    string
+	fallback string
+}
+
+var (
+	recordViewScope = viewScope{"`record`.view_id = ?", "(`record`.view_id IS NULL OR `record`.view_id = 0)"}
+	policyViewScope = viewScope{"`rrset_policy`.view_id = ?", "`rrset_policy`.view_id = 0"}
+)
+
+// where 在base上追加视图条件，返回新的会话
+func (s viewScope) where(base *gorm.DB, viewID int64) *gorm.DB {
+	tx := base.Session(&gorm.Session{})
+	if viewID > 0 {
+		return tx.Where(s.match, viewID)
+	}
+	return tx.Where(s.fallback)
+}
+
+// walkViews 沿视图链依次查询，直到某一视图返回结果；scan返回是否找到记录
+func walkViews(base *gorm.DB, scope viewScope, chain []int64, scan func(tx *gorm.DB) (bool, error)) error {
+	for _, viewID := range chain {
+		found, err := scan(scope.where(base, viewID))
+		if err != nil || found {
+			return err
+		}
+	}
+	return nil
+}
+
+// viewChains 视图继承链缓存，到期后整体从view与view_deny表重新加载
+type viewChains struct {
+	mu      sync.Mutex
+	chains  map[int64][]int64
+	denying map[int64]bool // 配置了屏蔽条目的视图
+	expires time.Time
+}
+
+// get 返回视图及其祖先视图（以默认视图0结尾），以及链上是否有视图配置了屏蔽条目
+func (vc *viewChains) get(ctx context.Context, db *gorm.DB, viewID int64) ([]int64, bool, error) {
+	if viewID <= 0 {
+		return []int64{0}, false, nil
+	}
+
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	if time.Now().After(vc.expires) {
+		if err := vc.load(ctx, db); err != nil {
+			return nil, false, err
+		}
+	}
+
+	chain, ok := vc.chains[viewID]
+	if !ok {
+		// 视图已删除或尚未加载，直接回退到默认视图
+		chain = []int64{viewID, 0}
+	}
+	for _, id := range chain {
+		if vc.denying[id] {
+			return chain, true, nil
+		}
+	}
+	return chain, false, nil
+}
+
+// load 重新加载所有视图的父子关系与屏蔽条目
+func (vc *viewChains) load(ctx context.Context, db *gorm.DB) error {
+	var views []struct {
+		ID       int64
+		ParentID int64
+	}
+	if err := db.WithContext(ctx).Model(&model.View{}).Select("id, parent_id").Scan(&views).Error; err != nil {
+		return err
+	}
+	var denying []int64
+	if err := db.WithContext(ctx).Model(&model.ViewDeny{}).Distinct().Pluck("view_id", &denying).Error; err != nil {
+		return err
+	}
+
+	parents := make(map[int64]int64, len(views))
+	for _, v := range views {
+		parents[v.ID] = v.ParentID
+	}
+	vc.chains = make(map[int64][]int64, len(views))
+	for _, v := range views {
+		chain := []int64{v.ID}
+		for parent := parents[v.ID]; parent > 0 && len(chain) < maxViewDepth; parent = parents[parent] {
+			if containsID(chain, parent) {
+				break
+			}
+			chain = append(chain, parent)
+		}
+		vc.chains[v.ID] = append(chain, 0)
+	}
+	vc.denying = make(map[int64]bool, len(denying))
+	for _, id := range denying {
+		vc.denying[id] = true
+	}
+	vc.expires = time.Now().Add(viewChainTTL)
+	return nil
+}
+
+// containsID 判断ids中是否包含id
+func containsID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// viewChain 返回对(名称, 类型)可见的视图链：遇到屏蔽了该名称的视图后不再继续回退，
+// rrType为空时只考虑屏蔽整个名称的条目
+func (dao *RecordDAO) viewChain(ctx context.Context, zoneName, recordName, rrType string, viewID int64) ([]int64, error) {
+	chain, denying, err := dao.chains.get(ctx, dao.db, viewID)
+	if err != nil || !denying {
+		return chain, err
+	}
+
+	var denied []int64
+	err = dao.db.WithContext(ctx).
+		Model(&model.ViewDeny{}).
+		Joins("JOIN `zone` ON `zone`.id = `view_deny`.zone_id").
+		Where("`zone`.name = ? AND `view_deny`.name = ? AND `view_deny`.type IN (?, '') AND `view_deny`.view_id IN ?",
+			zoneName, recordName, rrType, chain).
+		Pluck("`view_deny`.view_id", &denied).Error
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range chain {
+		if containsID(denied, id) {
+			return chain[:i+1], nil
+		}
+	}
+	return chain, nil
+}
+
+// ancestors 返回视图及其祖先视图，以默认视图0结尾，不考虑屏蔽条目
+func (dao *RecordDAO) ancestors(ctx context.Context, viewID int64) ([]int64, error) {
+	chain, _, err := dao.chains.get(ctx, dao.db, viewID)
+	return chain, err
+}
@@ -0,0 +1,125 @@
+package rdb
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+const aRecordQuery = "SELECT `record_a`.*, `record`.ttl, COALESCE(`record_a`.latitude, `pop`.latitude) AS latitude, COALESCE(`record_a`.longitude, `pop`.longitude) AS longitude FROM `record_a` JOIN `record` ON `record`.id = `record_a`.record_id JOIN `zone` ON `zone`.id = `record`.zone_id LEFT JOIN `pop` ON `pop`.code = `record_a`.pop AND `record_a`.pop <> '' WHERE (`zone`.name = ? AND `record`.name = ? AND `zone`.is_active = 1 AND `record`.is_active = 1)"
+
+const denyQuery = "SELECT `view_deny`.view_id FROM `view_deny` JOIN `zone` ON `zone`.id = `view_deny`.zone_id WHERE `zone`.name = ? AND `view_deny`.name = ? AND `view_deny`.type IN (?, '') AND `view_deny`.view_id IN (?,?,?)"
+
+func TestRecordDAO_Mock_ViewChain_Parent(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	// cn-telecom (3) -> cn (2) -> default
+	expectViewChains(mock, [][2]int64{{2, 0}, {3, 2}})
+	mock.ExpectQuery(regexp.QuoteMeta(aRecordQuery+" AND `record`.view_id = ?")).
+		WithArgs("example.com.", "www.example.com.", int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(aRecordQuery+" AND `record`.view_id = ?")).
+		WithArgs("example.com.", "www.example.com.", int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "record_id", "ip", "ttl"}).AddRow(1, 1, 16843009, 600))
+
+	res, err := dao.QueryARecords(ctx, "example.com.", "www.example.com.", 3)
+	assert.NoError(t, err)
+	if assert.Len(t, res, 1) {
+		assert.Equal(t, uint32(16843009), res[0].IP)
+	}
+
+	// The chain is cached, a second lookup walks it without reloading
+	mock.ExpectQuery(regexp.QuoteMeta(aRecordQuery+" AND `record`.view_id = ?")).
+		WithArgs("example.com.", "api.example.com.", int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(aRecordQuery+" AND `record`.view_id = ?")).
+		WithArgs("example.com.", "api.example.com.", int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(aRecordQuery+" AND ((`record`.view_id IS NULL OR `record`.view_id = 0))")).
+		WithArgs("example.com.", "api.example.com.").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	res, err = dao.QueryARecords(ctx, "example.com.", "api.example.com.", 3)
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_ViewChain_Deny(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	// cn-telecom (3) hides www that cn (2) and default have
+	expectViewChains(mock, [][2]int64{{2, 0}, {3, 2}}, 3)
+	mock.ExpectQuery(regexp.QuoteMeta(denyQuery)).
+		WithArgs("example.com.", "www.example.com.", "A", int64(3), int64(2), int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"view_id"}).AddRow(3))
+	mock.ExpectQuery(regexp.QuoteMeta(aRecordQuery+" AND `record`.view_id = ?")).
+		WithArgs("example.com.", "www.example.com.", int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	res, err := dao.QueryARecords(ctx, "example.com.", "www.example.com.", 3)
+	assert.NoError(t, err)
+	assert.Empty(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordDAO_Mock_ViewChain_NameExists(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewRecordDAO(db)
+	ctx := context.Background()
+
+	expectViewChains(mock, [][2]int64{{2, 0}, {3, 2}}, 2)
+	mock.ExpectQuery(regexp.QuoteMeta(denyQuery)).
+		WithArgs("example.com.", "old.example.com.", "", int64(3), int64(2), int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"view_id"}).AddRow(2))
+	// The default view is cut off by the deny entry in view 2
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `record` JOIN `zone` ON `zone`.id = `record`.zone_id WHERE (`zone`.name = ? AND (`record`.name = ? OR `record`.name LIKE ? ESCAPE '!') AND `zone`.is_active = 1 AND `record`.is_active = 1) AND `record`.view_id IN (?,?)")).
+		WithArgs("example.com.", "old.example.com.", "%.old.example.com.", int64(3), int64(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	exists, err := dao.NameExists(ctx, "example.com.", "old.example.com.", 3)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestViewChains_Load(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	ctx := context.Background()
+	vc := &viewChains{}
+
+	// 1 and 2 name each other as parent
+	expectViewChains(mock, [][2]int64{{1, 2}, {2, 1}, {3, 0}}, 2)
+
+	chain, denying, err := vc.get(ctx, db, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 0}, chain)
+	assert.True(t, denying)
+
+	chain, denying, err = vc.get(ctx, db, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 0}, chain)
+	assert.False(t, denying)
+
+	// Unknown views fall back to the default view
+	chain, _, err = vc.get(ctx, db, 9)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{9, 0}, chain)
+
+	// The default view needs no lookup
+	chain, _, err = vc.get(ctx, db, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{0}, chain)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -18,16 +18,30 @@ func TestViewDAO_Mock_Create(t *testing.T) {
 	dao := NewViewDAO(db)
 	ctx := context.Background()
 
-	view := &model.View{Name: "default", Category: "acl", Value: "1.1.1.1", Priority: 10}
+	view := &model.View{
+		Name:     "default",
+		Match:    model.ViewMatchAll,
+		Priority: 10,
+		Category: "acl",
+		Rules: []model.ViewRule{
+			{Type: model.ViewRuleCIDR, Value: "1.1.1.1/32"},
+			{Type: model.ViewRuleTransport, Value: "tcp", Negate: true},
+		},
+	}
 
 	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `view`")).
-		WithArgs(view.Name, view.Category, view.Value, view.Priority, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(view.Name, view.ParentID, view.Match, view.Priority, view.Category, "", sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `view_rule`")).
+		WithArgs(int64(1), "cidr", "1.1.1.1/32", false, sqlmock.AnyArg(), sqlmock.AnyArg(),
+			int64(1), "transport", "tcp", true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 2))
 	mock.ExpectCommit()
 
 	err = dao.Create(ctx, view)
 	assert.NoError(t, err)
+	assert.Equal(t, int64(1), view.Rules[0].ViewID)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -37,16 +51,22 @@ func TestViewDAO_Mock_GetByID(t *testing.T) {
 	dao := NewViewDAO(db)
 	ctx := context.Background()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "category", "value", "priority", "created_at", "updated_at"}).
-		AddRow(1, "default", "acl", "", 0, time.Now(), time.Now())
+	rows := sqlmock.NewRows([]string{"id", "name", "match", "category", "value", "priority", "created_at", "updated_at"}).
+		AddRow(1, "default", "all", "acl", "", 0, time.Now(), time.Now())
 
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view` WHERE `view`.`id` = ? ORDER BY `view`.`id` LIMIT ?")).
 		WithArgs(1, 1).
 		WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view_rule` WHERE `view_rule`.`view_id` = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "view_id", "type", "value", "negate"}).
+			AddRow(1, 1, "cidr", "10.0.0.0/8", false))
 
 	res, err := dao.GetByID(ctx, 1)
 	assert.NoError(t, err)
 	assert.Equal(t, "default", res.Name)
+	assert.Len(t, res.Rules, 1)
+	assert.Equal(t, "10.0.0.0/8", res.Rules[0].Value)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -62,6 +82,9 @@ func TestViewDAO_Mock_GetByName(t *testing.T) {
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view` WHERE name = ? ORDER BY `view`.`id` LIMIT ?")).
 		WithArgs("default", 1).
 		WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view_rule` WHERE `view_rule`.`view_id` = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "view_id"}))
 
 	res, err := dao.GetByName(ctx, "default")
 	assert.NoError(t, err)
@@ -79,8 +102,11 @@ func TestViewDAO_Mock_GetAll(t *testing.T) {
 		AddRow(1, "default", "acl").
 		AddRow(2, "another", "geoip")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view`")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view` ORDER BY priority DESC, id ASC")).
 		WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view_rule` WHERE `view_rule`.`view_id` IN (?,?)")).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "view_id"}))
 
 	res, err := dao.GetAll(ctx)
 	assert.NoError(t, err)
@@ -94,15 +120,24 @@ func TestViewDAO_Mock_Update(t *testing.T) {
 	dao := NewViewDAO(db)
 	ctx := context.Background()
 
-	view := &model.View{ID: 1, Name: "default", Category: "geoip"}
+	view := &model.View{ID: 1, Name: "default", Match: model.ViewMatchAny, Rules: []model.ViewRule{
+		{ID: 7, ViewID: 1, Type: model.ViewRuleCountry, Value: "CN"},
+	}}
 
 	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta("UPDATE `view`")).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `view_rule` WHERE view_id = ?")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `view_rule`")).
+		WithArgs(int64(1), "country", "CN", false, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(8, 1))
 	mock.ExpectCommit()
 
 	err = dao.Update(ctx, view)
 	assert.NoError(t, err)
+	assert.Equal(t, int64(8), view.Rules[0].ID)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -113,6 +148,15 @@ func TestViewDAO_Mock_Delete(t *testing.T) {
 	ctx := context.Background()
 
 	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `view_rule` WHERE view_id = ?")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `view_deny` WHERE view_id = ?")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `view` SET `parent_id`=?,`updated_at`=? WHERE parent_id = ?")).
+		WithArgs(0, sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `view` WHERE `view`.`id` = ?")).
 		WithArgs(1).
 		WillReturnResult(sqlmock.NewResult(1, 1))
@@ -122,3 +166,65 @@ func TestViewDAO_Mock_Delete(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestViewDAO_Mock_CheckParent(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewViewDAO(db)
+	ctx := context.Background()
+
+	parentQuery := regexp.QuoteMeta("SELECT id, parent_id FROM `view` WHERE `view`.`id` = ? ORDER BY `view`.`id` LIMIT ?")
+
+	// 3 -> 2 -> default is a valid parent for view 4
+	mock.ExpectQuery(parentQuery).WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id"}).AddRow(3, 2))
+	mock.ExpectQuery(parentQuery).WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id"}).AddRow(2, 0))
+	assert.NoError(t, dao.CheckParent(ctx, 4, 3))
+
+	// Making 2 a child of 3 would loop
+	mock.ExpectQuery(parentQuery).WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id"}).AddRow(3, 2))
+	assert.ErrorContains(t, dao.CheckParent(ctx, 2, 3), "cycle")
+
+	// Missing parent
+	mock.ExpectQuery(parentQuery).WithArgs(9, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id"}))
+	assert.ErrorContains(t, dao.CheckParent(ctx, 4, 9), "does not exist")
+
+	assert.ErrorContains(t, dao.CheckParent(ctx, 4, 4), "cycle")
+	assert.NoError(t, dao.CheckParent(ctx, 4, 0))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestViewDAO_Mock_Denies(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	dao := NewViewDAO(db)
+	ctx := context.Background()
+
+	deny := &model.ViewDeny{ViewID: 3, ZoneID: 1, Name: "www.example.com.", Type: "A"}
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `view_deny`")).
+		WithArgs(int64(3), int64(1), "www.example.com.", "A", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, dao.CreateDeny(ctx, deny))
+	assert.Equal(t, int64(5), deny.ID)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view_deny` WHERE view_id = ? ORDER BY id ASC")).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "view_id", "zone_id", "name", "type"}).
+			AddRow(5, 3, 1, "www.example.com.", "A"))
+	denies, err := dao.GetDenies(ctx, 3)
+	assert.NoError(t, err)
+	assert.Len(t, denies, 1)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `view_deny` WHERE view_id = ? AND `view_deny`.`id` = ?")).
+		WithArgs(3, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, dao.DeleteDeny(ctx, 3, 5))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -26,7 +26,7 @@ func TestZoneDAO_Mock_Create(t *testing.T) {
 
 	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `zone`")).
-		WithArgs(zone.Name, zone.Serial, zone.Description, zone.Remark, zone.Contact, zone.Email, zone.IsActive).
+		WithArgs(zone.Name, zone.Serial, zone.Description, zone.Remark, zone.Contact, zone.Email, zone.IsActive, zone.AutoPTR).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -0,0 +1,235 @@
+package health
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/logger"
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// tick is how often due probes are started
+const tick = time.Second
+
+// Store loads checks and targets and persists state changes.
+// It is satisfied by rdb.HealthCheckDAO.
+type Store interface {
+	GetActive(ctx context.Context) ([]*model.HealthCheck, error)
+	ListTargets(ctx context.Context) ([]*rdb.HealthTarget, error)
+	SaveStatus(ctx context.Context, status *model.HealthStatus) error
+}
+
+// targetKey identifies a probed address, shared by all records of a check
+// pointing at the same IP
+type targetKey struct {
+	checkID int64
+	ip      string
+}
+
+// target is the probe state of one address
+type target struct {
+	check *model.HealthCheck
+	ip    net.IP
+
+	known     bool // false until the first probe finished
+	healthy   bool
+	successes int
+	failures  int
+	next      time.Time
+	running   bool
+}
+
+// Checker probes health-checked A/AAAA records in the background and keeps
+// their state in memory. Addresses are healthy until a probe proves otherwise.
+// The resolver reads the state on every query, so state changes need no cache
+// invalidation.
+type Checker struct {
+	store Store
+
+	mu        sync.RWMutex
+	targets   map[targetKey]*target
+	fallbacks map[int64][]net.IP
+}
+
+// NewChecker creates a checker backed by the given store
+func NewChecker(store Store) *Checker {
+	return &Checker{
+		store:     store,
+		targets:   make(map[targetKey]*target),
+		fallbacks: make(map[int64][]net.IP),
+	}
+}
+
+// Healthy reports whether ip is healthy under the given check
+func (c *Checker) Healthy(checkID int64, ip net.IP) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	t, ok := c.targets[targetKey{checkID, ip.String()}]
+	if !ok || !t.known {
+		return true
+	}
+	return t.healthy
+}
+
+// Fallback returns the addresses served when every member of the check is down
+func (c *Checker) Fallback(checkID int64) []net.IP {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.fallbacks[checkID]
+}
+
+// Refresh reloads checks and targets, keeping the state of known addresses
+func (c *Checker) Refresh(ctx context.Context) error {
+	checks, err := c.store.GetActive(ctx)
+	if err != nil {
+		return err
+	}
+	records, err := c.store.ListTargets(ctx)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int64]*model.HealthCheck, len(checks))
+	fallbacks := make(map[int64][]net.IP, len(checks))
+	for _, check := range checks {
+		byID[check.ID] = check
+		fallbacks[check.ID] = parseFallback(check.Fallback)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	targets := make(map[targetKey]*target)
+	for _, record := range records {
+		check, ok := byID[record.CheckID]
+		if !ok {
+			continue
+		}
+		key := targetKey{record.CheckID, record.IP.String()}
+		t, ok := targets[key]
+		if !ok {
+			if t, ok = c.targets[key]; !ok {
+				t = &target{ip: record.IP}
+			}
+			t.check = check
+			targets[key] = t
+		}
+	}
+	c.targets = targets
+	c.fallbacks = fallbacks
+	return nil
+}
+
+// Run refreshes targets every refresh interval and probes them until ctx is cancelled
+func (c *Checker) Run(ctx context.Context, refresh time.Duration) {
+	if err := c.Refresh(ctx); err != nil {
+		logger.Warn("Failed to load health checks", logger.Err(err))
+	}
+
+	probes := time.NewTicker(tick)
+	defer probes.Stop()
+	reload := time.NewTicker(refresh)
+	defer reload.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reload.C:
+			if err := c.Refresh(ctx); err != nil {
+				logger.Warn("Failed to refresh health checks", logger.Err(err))
+			}
+		case now := <-probes.C:
+			c.probeDue(ctx, now)
+		}
+	}
+}
+
+// probeDue starts a probe for every target whose interval has elapsed
+func (c *Checker) probeDue(ctx context.Context, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, t := range c.targets {
+		if t.running || now.Before(t.next) {
+			continue
+		}
+		t.running = true
+		t.next = now.Add(interval(t.check))
+		go func(t *target, check *model.HealthCheck) {
+			c.record(ctx, t, probe(ctx, check, t.ip))
+		}(t, t.check)
+	}
+}
+
+// record applies a probe result to the target and saves state changes
+func (c *Checker) record(ctx context.Context, t *target, result error) {
+	c.mu.Lock()
+	t.running = false
+	changed := c.update(t, result)
+	status := &model.HealthStatus{
+		CheckID:   t.check.ID,
+		Target:    t.ip.String(),
+		Healthy:   t.healthy,
+		ChangedAt: time.Now(),
+	}
+	c.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if result != nil {
+		status.Message = result.Error()
+	}
+	logger.Info("Health state changed",
+		logger.Int64("check_id", status.CheckID),
+		logger.String("target", status.Target),
+		logger.Bool("healthy", status.Healthy))
+
+	if err := c.store.SaveStatus(ctx, status); err != nil {
+		logger.Warn("Failed to save health status", logger.Err(err))
+	}
+}
+
+// update counts consecutive results and reports whether the state flipped.
+// The first result always sets the state.
+func (c *Checker) update(t *target, result error) bool {
+	rise, fall := t.check.Rise, t.check.Fall
+	if rise <= 0 {
+		rise = defaultRise
+	}
+	if fall <= 0 {
+		fall = defaultFall
+	}
+
+	if result == nil {
+		t.successes++
+		t.failures = 0
+		if !t.known || (!t.healthy && t.successes >= rise) {
+			t.known, t.healthy = true, true
+			return true
+		}
+		return false
+	}
+
+	t.failures++
+	t.successes = 0
+	if !t.known || (t.healthy && t.failures >= fall) {
+		t.known, t.healthy = true, false
+		return true
+	}
+	return false
+}
+
+// parseFallback parses a comma separated list of addresses, skipping invalid entries
+func parseFallback(s string) []net.IP {
+	var ips []net.IP
+	for _, part := range strings.Split(s, ",") {
+		if ip := net.ParseIP(strings.TrimSpace(part)); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
@@ -0,0 +1,177 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+type fakeStore struct {
+	checks  []*model.HealthCheck
+	targets []*rdb.HealthTarget
+
+	mu       sync.Mutex
+	statuses []*model.HealthStatus
+}
+
+func (s *fakeStore) GetActive(ctx context.Context) ([]*model.HealthCheck, error) {
+	return s.checks, nil
+}
+
+func (s *fakeStore) ListTargets(ctx context.Context) ([]*rdb.HealthTarget, error) {
+	return s.targets, nil
+}
+
+func (s *fakeStore) SaveStatus(ctx context.Context, status *model.HealthStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses = append(s.statuses, status)
+	return nil
+}
+
+func (s *fakeStore) saved() []*model.HealthStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*model.HealthStatus(nil), s.statuses...)
+}
+
+// hostPort splits a listener address into its IP and port
+func hostPort(t *testing.T, addr string) (net.IP, int) {
+	host, portStr, err := net.SplitHostPort(addr)
+	assert.NoError(t, err)
+	port, _ := strconv.Atoi(portStr)
+	return net.ParseIP(host), port
+}
+
+func TestProbe(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("TCP", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		ip, port := hostPort(t, ln.Addr().String())
+		check := &model.HealthCheck{Protocol: model.HealthCheckTCP, Port: port, Timeout: 1}
+
+		assert.NoError(t, probe(ctx, check, ip))
+		ln.Close()
+		assert.Error(t, probe(ctx, check, ip))
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/healthz" && r.Host == "app.example.com" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+		ip, port := hostPort(t, srv.Listener.Addr().String())
+
+		check := &model.HealthCheck{Protocol: model.HealthCheckHTTP, Port: port, Host: "app.example.com", Path: "/healthz", ExpectStatus: http.StatusNoContent, Timeout: 1}
+		assert.NoError(t, probe(ctx, check, ip))
+
+		check.Path = "/"
+		assert.Error(t, probe(ctx, check, ip))
+	})
+
+	t.Run("DNS", func(t *testing.T) {
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		srv := &dns.Server{PacketConn: pc, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+			if r.Question[0].Name != "example.com." {
+				m.Rcode = dns.RcodeRefused
+			}
+			_ = w.WriteMsg(m)
+		})}
+		go srv.ActivateAndServe()
+		defer srv.Shutdown()
+		ip, port := hostPort(t, pc.LocalAddr().String())
+
+		check := &model.HealthCheck{Protocol: model.HealthCheckDNS, Port: port, QName: "example.com", Timeout: 1}
+		assert.NoError(t, probe(ctx, check, ip))
+
+		check.QName = "other.com"
+		assert.Error(t, probe(ctx, check, ip))
+	})
+}
+
+func TestChecker_StateChanges(t *testing.T) {
+	ctx := context.Background()
+	ip := net.ParseIP("192.0.2.10")
+	store := &fakeStore{
+		checks:  []*model.HealthCheck{{ID: 1, Rise: 2, Fall: 2, Fallback: "198.51.100.1, bogus, 2001:db8::1"}},
+		targets: []*rdb.HealthTarget{{CheckID: 1, IP: ip}},
+	}
+	c := NewChecker(store)
+	assert.NoError(t, c.Refresh(ctx))
+
+	// Unprobed and unknown addresses are healthy
+	assert.True(t, c.Healthy(1, ip))
+	assert.True(t, c.Healthy(2, ip))
+	assert.Equal(t, []net.IP{net.ParseIP("198.51.100.1"), net.ParseIP("2001:db8::1")}, c.Fallback(1))
+
+	tg := c.targets[targetKey{1, ip.String()}]
+	down := errors.New("connection refused")
+
+	// The first result sets the state immediately
+	c.record(ctx, tg, down)
+	assert.False(t, c.Healthy(1, ip))
+	assert.Len(t, store.saved(), 1)
+
+	// One success is not enough to rise
+	c.record(ctx, tg, nil)
+	assert.False(t, c.Healthy(1, ip))
+	c.record(ctx, tg, nil)
+	assert.True(t, c.Healthy(1, ip))
+
+	// One failure is not enough to fall
+	c.record(ctx, tg, down)
+	assert.True(t, c.Healthy(1, ip))
+	c.record(ctx, tg, down)
+	assert.False(t, c.Healthy(1, ip))
+
+	statuses := store.saved()
+	if assert.Len(t, statuses, 3) {
+		assert.False(t, statuses[2].Healthy)
+		assert.Equal(t, "192.0.2.10", statuses[2].Target)
+		assert.Equal(t, "connection refused", statuses[2].Message)
+	}
+
+	// State survives a refresh
+	assert.NoError(t, c.Refresh(ctx))
+	assert.False(t, c.Healthy(1, ip))
+}
+
+func TestChecker_Run(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	ip, port := hostPort(t, ln.Addr().String())
+	ln.Close()
+
+	store := &fakeStore{
+		checks:  []*model.HealthCheck{{ID: 1, Protocol: model.HealthCheckTCP, Port: port, Timeout: 1}},
+		targets: []*rdb.HealthTarget{{CheckID: 1, IP: ip}},
+	}
+	c := NewChecker(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx, time.Minute)
+
+	assert.Eventually(t, func() bool { return !c.Healthy(1, ip) }, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// Defaults for check settings left empty
+const (
+	defaultInterval = 10 * time.Second
+	defaultTimeout  = 3 * time.Second
+	defaultRise     = 2
+	defaultFall     = 3
+)
+
+// probe runs the check against ip and returns nil when the target is healthy
+func probe(ctx context.Context, check *model.HealthCheck, ip net.IP) error {
+	addr := net.JoinHostPort(ip.String(), strconv.Itoa(check.Port))
+	ctx, cancel := context.WithTimeout(ctx, timeout(check))
+	defer cancel()
+
+	switch check.Protocol {
+	case model.HealthCheckHTTP:
+		return probeHTTP(ctx, check, addr)
+	case model.HealthCheckDNS:
+		return probeDNS(ctx, check, addr)
+	default:
+		return probeTCP(ctx, addr)
+	}
+}
+
+// probeTCP succeeds when a TCP connection can be established
+func probeTCP(ctx context.Context, addr string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// probeHTTP succeeds when a GET returns the expected status, redirects are not followed
+func probeHTTP(ctx context.Context, check *model.HealthCheck, addr string) error {
+	path := check.Path
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+path, nil)
+	if err != nil {
+		return err
+	}
+	if check.Host != "" {
+		req.Host = check.Host
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	expect := check.ExpectStatus
+	if expect == 0 {
+		expect = http.StatusOK
+	}
+	if resp.StatusCode != expect {
+		return fmt.Errorf("unexpected status %d, want %d", resp.StatusCode, expect)
+	}
+	return nil
+}
+
+// probeDNS succeeds when the server answers a UDP query with anything but
+// SERVFAIL or REFUSED
+func probeDNS(ctx context.Context, check *model.HealthCheck, addr string) error {
+	qName := check.QName
+	if qName == "" {
+		qName = "."
+	}
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(qName), dns.TypeSOA)
+
+	client := &dns.Client{Net: "udp", Timeout: timeout(check)}
+	resp, _, err := client.ExchangeContext(ctx, m, addr)
+	if err != nil {
+		return err
+	}
+	if resp.Rcode == dns.RcodeServerFailure || resp.Rcode == dns.RcodeRefused {
+		return fmt.Errorf("unexpected rcode %s", dns.RcodeToString[resp.Rcode])
+	}
+	return nil
+}
+
+func timeout(check *model.HealthCheck) time.Duration {
+	if check.Timeout > 0 {
+		return time.Duration(check.Timeout) * time.Second
+	}
+	return defaultTimeout
+}
+
+func interval(check *model.HealthCheck) time.Duration {
+	if check.Interval > 0 {
+		return time.Duration(check.Interval) * time.Second
+	}
+	return defaultInterval
+}
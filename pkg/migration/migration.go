@@ -11,7 +11,10 @@ import (
 func Migrate(driver string) error {
 	fmt.Printf("Starting migration for driver: %s\n", driver)
 	s := store.GetInstance()
-	return s.AutoMigrate(model.Models...)
+	if err := s.AutoMigrate(model.Models...); err != nil {
+		return err
+	}
+	return migrateViewRules(s.GetDB())
 }
 
 // Upgrade 执行数据库架构升级
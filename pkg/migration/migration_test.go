@@ -2,14 +2,38 @@ package migration
 
 import (
 	"context"
+	"regexp"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/cylonchau/hermes/pkg/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
+// setupMockDB initializes a mocked gorm.DB using sqlmock
+func setupMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	db, sqlMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	assert.NoError(t, err)
+	return gormDB, sqlMock
+}
+
+// expectNoLegacyViews expects the legacy view scan to find nothing
+func expectNoLegacyViews(sqlMock sqlmock.Sqlmock) {
+	sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view` WHERE value IS NOT NULL AND value <> ''")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+}
+
 // MockStore is a mock implementation of store.Store
 type MockStore struct {
 	mock.Mock
@@ -57,6 +81,9 @@ func TestMigrate_Mock(t *testing.T) {
 
 	// Expect AutoMigrate to be called with any slice of interfaces.
 	mockStore.On("AutoMigrate", mock.Anything).Return(nil)
+	db, sqlMock := setupMockDB(t)
+	mockStore.On("GetDB").Return(db)
+	expectNoLegacyViews(sqlMock)
 
 	// Perform Migration
 	err := Migrate("mysql")
@@ -72,6 +99,9 @@ func TestUpgrade_Mock(t *testing.T) {
 	defer store.ResetInstance(nil)
 
 	mockStore.On("AutoMigrate", mock.Anything).Return(nil)
+	db, sqlMock := setupMockDB(t)
+	mockStore.On("GetDB").Return(db)
+	expectNoLegacyViews(sqlMock)
 
 	err := Upgrade("postgres")
 	assert.NoError(t, err)
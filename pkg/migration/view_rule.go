@@ -0,0 +1,36 @@
+package migration
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// migrateViewRules 将旧版Category/Value格式的视图转换为view_rule条件，
+// 转换后清空Value，重复执行不会产生重复条件
+func migrateViewRules(db *gorm.DB) error {
+	var views []model.View
+	if err := db.Where("value IS NOT NULL AND value <> ''").Find(&views).Error; err != nil {
+		return err
+	}
+	for i := range views {
+		view := &views[i]
+		match, rules := view.LegacyRules()
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if len(rules) > 0 {
+				if err := tx.Create(&rules).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Model(&model.View{}).Where("id = ?", view.ID).
+				Updates(map[string]interface{}{"match": match, "value": ""}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migrate rules of view %q: %w", view.Name, err)
+		}
+		fmt.Printf("Migrated view %q to %d rule(s)\n", view.Name, len(rules))
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package migration
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateViewRules(t *testing.T) {
+	db, sqlMock := setupMockDB(t)
+
+	sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view` WHERE value IS NOT NULL AND value <> ''")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "category", "value", "priority"}).
+			AddRow(1, "office", "acl", "10.0.0.0/8, 192.168.0.0/16", 10).
+			AddRow(2, "guangdong", "geoip", "CN-GD", 5).
+			AddRow(3, "gd", "geoip", "GD", 1))
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO `view_rule`")).
+		WithArgs(int64(1), "acl", "10.0.0.0/8, 192.168.0.0/16", false, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE `view` SET `match`=?,`value`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs("all", "", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO `view_rule`")).
+		WithArgs(int64(2), "geoip", "CN-GD", false, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE `view` SET `match`=?,`value`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs("all", "", sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+
+	// Legacy rules keep the legacy matching, the value is stored as written
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO `view_rule`")).
+		WithArgs(int64(3), "geoip", "GD", false, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE `view` SET `match`=?,`value`=?,`updated_at`=? WHERE id = ?")).
+		WithArgs("all", "", sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+
+	assert.NoError(t, migrateViewRules(db))
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestMigrateViewRules_Rollback(t *testing.T) {
+	db, sqlMock := setupMockDB(t)
+
+	sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view` WHERE value IS NOT NULL AND value <> ''")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "category", "value"}).
+			AddRow(1, "office", "acl", "10.0.0.0/8"))
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO `view_rule`")).
+		WillReturnError(errors.New("insert failed"))
+	sqlMock.ExpectRollback()
+
+	err := migrateViewRules(db)
+	assert.ErrorContains(t, err, `view "office"`)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+// GeoIPOverride GeoIP覆盖条目：将CIDR固定到指定位置，优先于GeoIP数据库，
+// 多个条目包含同一地址时前缀最长者生效
+type GeoIPOverride struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	CIDR         string    `gorm:"column:cidr;type:varchar(64);not null;uniqueIndex;comment:覆盖的网段，如 203.0.113.0/24" json:"cidr"`
+	Continent    string    `gorm:"type:varchar(2);comment:大洲代码，如 AS" json:"continent"`
+	Country      string    `gorm:"type:varchar(2);comment:国家代码，如 CN" json:"country"`
+	Region       string    `gorm:"type:varchar(64);comment:地区代码，如 GD" json:"region"`
+	ASN          uint32    `gorm:"column:asn;not null;default:0;comment:自治系统号，0表示不覆盖" json:"asn"`
+	Organization string    `gorm:"type:varchar(255);comment:自治系统组织名称" json:"organization"`
+	Latitude     *float64  `gorm:"type:double;comment:纬度" json:"latitude,omitempty"`
+	Longitude    *float64  `gorm:"type:double;comment:经度" json:"longitude,omitempty"`
+	Remark       string    `gorm:"type:varchar(256);comment:备注;" json:"remark"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (GeoIPOverride) TableName() string {
+	return "geoip_override"
+}
+
+func init() {
+	RegisterModel(&GeoIPOverride{})
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+)
+
+// 健康检查探测方式
+const (
+	HealthCheckTCP  = "tcp"  // TCP建连
+	HealthCheckHTTP = "http" // HTTP GET并校验状态码
+	HealthCheckDNS  = "dns"  // UDP DNS查询
+)
+
+// HealthCheck 健康检查定义，A/AAAA记录通过health_check_id关联
+type HealthCheck struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name         string    `gorm:"type:varchar(255);not null;uniqueIndex;comment:健康检查名称" json:"name"`
+	Protocol     string    `gorm:"type:varchar(10);not null;default:'tcp';comment:探测方式: tcp, http 或 dns" json:"protocol"`
+	Port         int       `gorm:"type:int;not null;comment:探测端口" json:"port"`
+	Host         string    `gorm:"type:varchar(255);comment:HTTP探测的Host头" json:"host"`
+	Path         string    `gorm:"type:varchar(255);default:'/';comment:HTTP探测路径" json:"path"`
+	ExpectStatus int       `gorm:"type:int;not null;default:200;comment:HTTP探测期望的状态码" json:"expect_status"`
+	QName        string    `gorm:"type:varchar(255);comment:DNS探测的查询名称，为空时查询根区SOA" json:"qname"`
+	Interval     int       `gorm:"type:int;not null;default:10;comment:探测间隔（单位秒）" json:"interval"`
+	Timeout      int       `gorm:"type:int;not null;default:3;comment:探测超时（单位秒）" json:"timeout"`
+	Rise         int       `gorm:"type:int;not null;default:2;comment:连续成功多少次后标记为健康" json:"rise"`
+	Fall         int       `gorm:"type:int;not null;default:3;comment:连续失败多少次后标记为不健康" json:"fall"`
+	Fallback     string    `gorm:"type:text;comment:全部成员不健康时返回的备用地址，逗号分隔" json:"fallback"`
+	IsActive     bool      `gorm:"default:true;comment:是否启用" json:"is_active"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (HealthCheck) TableName() string {
+	return "health_check"
+}
+
+// HealthStatus 每个探测目标的当前健康状态
+type HealthStatus struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	CheckID   int64     `gorm:"type:bigint;not null;uniqueIndex:idx_health_status;comment:关联health_check表的id" json:"check_id"`
+	Target    string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_health_status;comment:探测的IP地址" json:"target"`
+	Healthy   bool      `gorm:"not null;comment:是否健康" json:"healthy"`
+	Message   string    `gorm:"type:text;comment:最近一次探测失败的原因" json:"message"`
+	ChangedAt time.Time `gorm:"comment:状态最近一次变化的时间" json:"changed_at"`
+}
+
+func (HealthStatus) TableName() string {
+	return "health_status"
+}
+
+// HealthEvent 健康状态变化历史
+type HealthEvent struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	CheckID   int64     `gorm:"type:bigint;not null;index;comment:关联health_check表的id" json:"check_id"`
+	Target    string    `gorm:"type:varchar(64);not null;comment:探测的IP地址" json:"target"`
+	Healthy   bool      `gorm:"not null;comment:变化后的状态" json:"healthy"`
+	Message   string    `gorm:"type:text;comment:探测结果说明" json:"message"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (HealthEvent) TableName() string {
+	return "health_event"
+}
+
+func init() {
+	RegisterModel(&HealthCheck{})
+	RegisterModel(&HealthStatus{})
+	RegisterModel(&HealthEvent{})
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// PoP 接入点，A/AAAA记录可通过接入点代码引用其坐标
+type PoP struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code      string    `gorm:"type:varchar(32);not null;uniqueIndex;comment:接入点代码，如 fra1" json:"code"`
+	Name      string    `gorm:"type:varchar(255);comment:接入点名称" json:"name"`
+	Latitude  float64   `gorm:"type:double;not null;comment:纬度" json:"latitude"`
+	Longitude float64   `gorm:"type:double;not null;comment:经度" json:"longitude"`
+	Remark    string    `gorm:"type:varchar(256);comment:备注;" json:"remark"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (PoP) TableName() string {
+	return "pop"
+}
+
+func init() {
+	RegisterModel(&PoP{})
+}
@@ -19,11 +19,20 @@ type Record struct {
 	ARecord     *ARecord     `gorm:"foreignKey:RecordID" json:"a_record,omitempty"`
 	AAAARecord  *AAAARecord  `gorm:"foreignKey:RecordID" json:"aaaa_record,omitempty"`
 	CNAMERecord *CNAMERecord `gorm:"foreignKey:RecordID" json:"cname_record,omitempty"`
+	DNAMERecord *DNAMERecord `gorm:"foreignKey:RecordID" json:"dname_record,omitempty"`
+	ALIASRecord *ALIASRecord `gorm:"foreignKey:RecordID" json:"alias_record,omitempty"`
 	MXRecord    *MXRecord    `gorm:"foreignKey:RecordID" json:"mx_record,omitempty"`
 	TXTRecord   *TXTRecord   `gorm:"foreignKey:RecordID" json:"txt_record,omitempty"`
 	SRVRecord   *SRVRecord   `gorm:"foreignKey:RecordID" json:"srv_record,omitempty"`
 	SOARecord   *SOARecord   `gorm:"foreignKey:RecordID" json:"soa_record,omitempty"`
 	NSRecord    *NSRecord    `gorm:"foreignKey:RecordID" json:"ns_record,omitempty"`
+	PTRRecord   *PTRRecord   `gorm:"foreignKey:RecordID" json:"ptr_record,omitempty"`
+	SVCBRecord  *SVCBRecord  `gorm:"foreignKey:RecordID" json:"svcb_record,omitempty"`
+	HTTPSRecord *HTTPSRecord `gorm:"foreignKey:RecordID" json:"https_record,omitempty"`
+	TLSARecord  *TLSARecord  `gorm:"foreignKey:RecordID" json:"tlsa_record,omitempty"`
+	SSHFPRecord *SSHFPRecord `gorm:"foreignKey:RecordID" json:"sshfp_record,omitempty"`
+	// 没有专用表的记录类型
+	GenericRecord *GenericRecord `gorm:"foreignKey:RecordID" json:"generic_record,omitempty"`
 }
 
 func (Record) TableName() string {
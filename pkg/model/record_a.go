@@ -1,10 +1,15 @@
 package model
 
 type ARecord struct {
-	ID       int64  `gorm:"type:bigint;primaryKey;autoIncrement;comment:主键id;" json:"id"`
-	RecordID int64  `gorm:"type:bigint;unique;not null;comment:关联record表的id;" json:"record_id"` // 关联的record_id
-	IP       uint32 `gorm:"type:uint32;not null;index;comment:IPv4地址;" json:"ip"`               // IPv4地址
-	Remark   string `gorm:"type:varchar(256);comment:备注;" json:"remark"`                        // 备注
+	ID            int64    `gorm:"type:bigint;primaryKey;autoIncrement;comment:主键id;" json:"id"`
+	RecordID      int64    `gorm:"type:bigint;unique;not null;comment:关联record表的id;" json:"record_id"`                             // 关联的record_id
+	IP            uint32   `gorm:"type:uint32;not null;index;comment:IPv4地址;" json:"ip"`                                           // IPv4地址
+	Weight        uint32   `gorm:"type:int;not null;default:1;comment:权重，用于加权选择;" json:"weight"`                                   // 权重
+	HealthCheckID int64    `gorm:"type:bigint;not null;default:0;index;comment:关联health_check表的id，0表示不检查;" json:"health_check_id"` // 健康检查
+	Latitude      *float64 `gorm:"type:double;comment:纬度，用于就近应答;" json:"latitude"`                                                 // 纬度
+	Longitude     *float64 `gorm:"type:double;comment:经度，用于就近应答;" json:"longitude"`                                                // 经度
+	PoP           string   `gorm:"column:pop;type:varchar(32);index;comment:接入点代码，未设置经纬度时使用pop表中的坐标;" json:"pop"`                  // 接入点代码
+	Remark        string   `gorm:"type:varchar(256);comment:备注;" json:"remark"`                                                    // 备注
 
 	// 关联关系
 	Record Record `gorm:"foreignKey:RecordID;constraint:OnDelete:CASCADE" json:"record,omitempty"`
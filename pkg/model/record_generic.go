@@ -0,0 +1,24 @@
+package model
+
+// 通用记录表 (RFC 3597)，存放没有专用表的任意记录类型
+type GenericRecord struct {
+	ID       int64  `gorm:"type:bigint;primaryKey;autoIncrement;comment:主键id;" json:"id"`
+	RecordID int64  `gorm:"type:bigint;unique;not null;comment:关联record表的id;" json:"record_id"`
+	RRType   uint16 `gorm:"column:rr_type;type:int;not null;index;comment:记录类型编号;" json:"rr_type"`                    // 记录类型编号
+	RData    string `gorm:"column:rdata;type:text;not null;comment:RDATA表示格式，或RFC 3597格式(\\# 长度 十六进制);" json:"rdata"` // RDATA表示格式
+	Remark   string `gorm:"type:text;comment:备注;" json:"remark"`                                                      // 备注
+
+	// 关联关系
+	Record Record `gorm:"foreignKey:RecordID;constraint:OnDelete:CASCADE" json:"record,omitempty"`
+
+	// 不映射为表字段，用于 Join 查询结果映射，避免嵌套对象解析
+	TTL uint32 `gorm:"->" json:"ttl"`
+}
+
+func (GenericRecord) TableName() string {
+	return "record_generic"
+}
+
+func init() {
+	RegisterModel(&GenericRecord{})
+}
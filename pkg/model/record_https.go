@@ -0,0 +1,25 @@
+package model
+
+// HTTPS记录表 (HTTPS服务绑定)
+type HTTPSRecord struct {
+	ID        int64  `gorm:"type:bigint;primaryKey;autoIncrement;comment:主键id;" json:"id"`
+	RecordID  int64  `gorm:"type:bigint;unique;not null;comment:关联record表的id;" json:"record_id"`
+	Priority  uint16 `gorm:"type:smallint;not null;index;comment:优先级，0表示别名模式;" json:"priority"` // 优先级，0表示别名模式
+	Target    string `gorm:"type:varchar(255);not null;comment:目标名称;" json:"target"`            // 目标名称，"."表示记录所有者本身
+	SvcParams `gorm:"embedded"`
+	Remark    string `gorm:"type:text;comment:备注;" json:"remark"` // 备注
+
+	// 关联关系
+	Record Record `gorm:"foreignKey:RecordID;constraint:OnDelete:CASCADE" json:"record,omitempty"`
+
+	// 不映射为表字段，用于 Join 查询结果映射，避免嵌套对象解析
+	TTL uint32 `gorm:"->" json:"ttl"`
+}
+
+func (HTTPSRecord) TableName() string {
+	return "record_https"
+}
+
+func init() {
+	RegisterModel(&HTTPSRecord{})
+}
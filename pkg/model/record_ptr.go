@@ -0,0 +1,23 @@
+package model
+
+// PTR记录表 (反向解析)
+type PTRRecord struct {
+	ID       int64  `gorm:"type:bigint;primaryKey;autoIncrement;comment:主键id;" json:"id"`
+	RecordID int64  `gorm:"type:bigint;unique;not null;comment:关联record表的id;" json:"record_id"`
+	PTRDName string `gorm:"column:ptrdname;type:varchar(255);not null;index;comment:指向的域名;" json:"ptrdname"` // 指向的域名
+	Remark   string `gorm:"type:text;comment:备注;" json:"remark"`                                             // 备注
+
+	// 关联关系
+	Record Record `gorm:"foreignKey:RecordID;constraint:OnDelete:CASCADE" json:"record,omitempty"`
+
+	// 不映射为表字段，用于 Join 查询结果映射，避免嵌套对象解析
+	TTL uint32 `gorm:"->" json:"ttl"`
+}
+
+func (PTRRecord) TableName() string {
+	return "record_ptr"
+}
+
+func init() {
+	RegisterModel(&PTRRecord{})
+}
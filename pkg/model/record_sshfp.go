@@ -0,0 +1,25 @@
+package model
+
+// SSHFP记录表 (SSH主机密钥指纹)
+type SSHFPRecord struct {
+	ID          int64  `gorm:"type:bigint;primaryKey;autoIncrement;comment:主键id;" json:"id"`
+	RecordID    int64  `gorm:"type:bigint;unique;not null;comment:关联record表的id;" json:"record_id"`
+	Algorithm   uint8  `gorm:"type:tinyint;not null;comment:密钥算法(1 RSA,2 DSA,3 ECDSA,4 Ed25519,6 Ed448);" json:"algorithm"` // 密钥算法
+	Type        uint8  `gorm:"type:tinyint;not null;comment:指纹类型(1 SHA-1,2 SHA-256);" json:"type"`                          // 指纹类型
+	FingerPrint string `gorm:"column:fingerprint;type:varchar(128);not null;comment:十六进制指纹;" json:"fingerprint"`            // 十六进制指纹
+	Remark      string `gorm:"type:text;comment:备注;" json:"remark"`                                                         // 备注
+
+	// 关联关系
+	Record Record `gorm:"foreignKey:RecordID;constraint:OnDelete:CASCADE" json:"record,omitempty"`
+
+	// 不映射为表字段，用于 Join 查询结果映射，避免嵌套对象解析
+	TTL uint32 `gorm:"->" json:"ttl"`
+}
+
+func (SSHFPRecord) TableName() string {
+	return "record_sshfp"
+}
+
+func init() {
+	RegisterModel(&SSHFPRecord{})
+}
@@ -0,0 +1,35 @@
+package model
+
+// SvcParams SVCB/HTTPS记录的服务参数 (RFC 9460)，多个值用逗号分隔
+type SvcParams struct {
+	ALPN          string `gorm:"column:alpn;type:varchar(255);comment:应用层协议列表，用逗号分隔;" json:"alpn"`             // 应用层协议列表，如 h2,h3
+	NoDefaultALPN bool   `gorm:"column:no_default_alpn;default:false;comment:不使用默认协议;" json:"no_default_alpn"` // 不使用默认协议
+	Port          uint16 `gorm:"type:smallint;comment:服务端口，0表示不设置;" json:"port"`                               // 服务端口，0表示不设置
+	IPv4Hint      string `gorm:"column:ipv4hint;type:varchar(255);comment:IPv4地址提示，用逗号分隔;" json:"ipv4hint"`    // IPv4地址提示
+	IPv6Hint      string `gorm:"column:ipv6hint;type:varchar(512);comment:IPv6地址提示，用逗号分隔;" json:"ipv6hint"`    // IPv6地址提示
+	ECH           string `gorm:"column:ech;type:text;comment:Base64编码的ECHConfigList;" json:"ech"`              // Base64编码的ECHConfigList
+}
+
+// SVCB记录表 (通用服务绑定)
+type SVCBRecord struct {
+	ID        int64  `gorm:"type:bigint;primaryKey;autoIncrement;comment:主键id;" json:"id"`
+	RecordID  int64  `gorm:"type:bigint;unique;not null;comment:关联record表的id;" json:"record_id"`
+	Priority  uint16 `gorm:"type:smallint;not null;index;comment:优先级，0表示别名模式;" json:"priority"` // 优先级，0表示别名模式
+	Target    string `gorm:"type:varchar(255);not null;comment:目标名称;" json:"target"`            // 目标名称，"."表示记录所有者本身
+	SvcParams `gorm:"embedded"`
+	Remark    string `gorm:"type:text;comment:备注;" json:"remark"` // 备注
+
+	// 关联关系
+	Record Record `gorm:"foreignKey:RecordID;constraint:OnDelete:CASCADE" json:"record,omitempty"`
+
+	// 不映射为表字段，用于 Join 查询结果映射，避免嵌套对象解析
+	TTL uint32 `gorm:"->" json:"ttl"`
+}
+
+func (SVCBRecord) TableName() string {
+	return "record_svcb"
+}
+
+func init() {
+	RegisterModel(&SVCBRecord{})
+}
@@ -0,0 +1,26 @@
+package model
+
+// TLSA记录表 (DANE证书关联)
+type TLSARecord struct {
+	ID           int64  `gorm:"type:bigint;primaryKey;autoIncrement;comment:主键id;" json:"id"`
+	RecordID     int64  `gorm:"type:bigint;unique;not null;comment:关联record表的id;" json:"record_id"`
+	Usage        uint8  `gorm:"type:tinyint;not null;comment:证书用途(0-3);" json:"usage"`                             // 证书用途(0-3)
+	Selector     uint8  `gorm:"type:tinyint;not null;comment:选择器(0完整证书,1公钥);" json:"selector"`                     // 选择器(0完整证书,1公钥)
+	MatchingType uint8  `gorm:"type:tinyint;not null;comment:匹配类型(0原始,1 SHA-256,2 SHA-512);" json:"matching_type"` // 匹配类型(0原始,1 SHA-256,2 SHA-512)
+	Certificate  string `gorm:"type:text;not null;comment:十六进制关联数据;" json:"certificate"`                           // 十六进制关联数据
+	Remark       string `gorm:"type:text;comment:备注;" json:"remark"`                                               // 备注
+
+	// 关联关系
+	Record Record `gorm:"foreignKey:RecordID;constraint:OnDelete:CASCADE" json:"record,omitempty"`
+
+	// 不映射为表字段，用于 Join 查询结果映射，避免嵌套对象解析
+	TTL uint32 `gorm:"->" json:"ttl"`
+}
+
+func (TLSARecord) TableName() string {
+	return "record_tlsa"
+}
+
+func init() {
+	RegisterModel(&TLSARecord{})
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// 记录集应答选择策略
+const (
+	PolicyAll        = "all"         // 返回全部记录
+	PolicyRoundRobin = "round_robin" // 每次应答轮转记录顺序
+	PolicyWeighted   = "weighted"    // 按权重随机选出Count条记录
+	PolicyFixed      = "fixed"       // 按权重从高到低的固定顺序
+	PolicyNearest    = "nearest"     // 按与客户端的距离从近到远排序
+)
+
+// RRSetPolicy 记录集（zone + 名称 + 类型 + 视图）的应答选择策略
+type RRSetPolicy struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	ZoneID    int64     `gorm:"type:bigint;not null;uniqueIndex:idx_rrset_policy;comment:关联zone表的id;" json:"zone_id"`
+	Name      string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_rrset_policy;comment:记录名称;" json:"name"`
+	Type      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_rrset_policy;comment:记录类型(A或AAAA);" json:"type"`
+	ViewID    int64     `gorm:"type:bigint;not null;default:0;uniqueIndex:idx_rrset_policy;comment:关联view表的id，0为默认视图;" json:"view_id"`
+	Policy    string    `gorm:"type:varchar(20);not null;default:'all';comment:策略: all, round_robin, weighted, fixed 或 nearest" json:"policy"`
+	Count     int       `gorm:"type:int;not null;default:0;comment:最多返回的记录数，0表示不限制" json:"count"`
+	Remark    string    `gorm:"type:varchar(256);comment:备注;" json:"remark"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (RRSetPolicy) TableName() string {
+	return "rrset_policy"
+}
+
+func init() {
+	RegisterModel(&RRSetPolicy{})
+}
@@ -1,23 +1,121 @@
 package model
 
 import (
+	"strings"
 	"time"
 )
 
+// 视图条件的组合方式
+const (
+	ViewMatchAll = "all" // 所有条件都满足（AND）
+	ViewMatchAny = "any" // 任一条件满足（OR）
+)
+
+// 视图条件类型，Value为逗号分隔的列表，命中其中任意一项即满足该条件
+const (
+	ViewRuleCIDR      = "cidr"      // 客户端地址（ECS生效时为客户端子网）属于任一CIDR
+	ViewRuleContinent = "continent" // GeoIP大洲代码: AF, AN, AS, EU, NA, OC 或 SA
+	ViewRuleCountry   = "country"   // GeoIP国家代码，如 CN
+	ViewRuleRegion    = "region"    // GeoIP地区代码，如 GD 或 CN-GD；CSV数据源为地区名称，如 Guangdong
+	ViewRuleASN       = "asn"       // 客户端地址所属的自治系统号，如 4134 或 AS4134
+	ViewRuleOrg       = "org"       // 自治系统组织名称包含任一关键字（不区分大小写）
+	ViewRuleECS       = "ecs"       // 查询携带的EDNS Client Subnet属于任一CIDR，为空表示携带即可
+	ViewRuleTransport = "transport" // 查询的传输协议: udp 或 tcp
+	ViewRuleListener  = "listener"  // 接收查询的本地监听地址（IP或CIDR）
+)
+
+// 旧版视图条件类型，由Category/Value转换而来，保留旧版的匹配方式
+const (
+	ViewRuleACL   = "acl"   // 客户端地址属于任一CIDR，单个IP不生效
+	ViewRuleGeoIP = "geoip" // 整个Value与国家代码、国家-地区代码或地区代码完全相同（区分大小写）
+)
+
+// 视图优先级取值范围
+const (
+	ViewPriorityMin = 0
+	ViewPriorityMax = 65535
+)
+
+// View 视图。按Priority从大到小依次匹配，Priority相同时ID小者优先，
+// 第一个满足条件的视图生效；没有视图命中时使用默认视图(view_id为0)。
+// 视图中查不到的记录依次回退到父视图，最终回退到默认视图。
 type View struct {
+	ID       int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name     string `gorm:"not null;uniqueIndex" json:"name"`
+	ParentID int64  `gorm:"type:bigint;not null;default:0;index;comment:父视图id，0表示直接回退到默认视图" json:"parent_id"`
+	Match    string `gorm:"type:varchar(10);not null;default:'all';comment:条件组合方式: all(AND) 或 any(OR)" json:"match"`
+	Priority int    `gorm:"type:int;not null;default:0;index;comment:匹配优先级(越大越优先，相同时ID小者优先)" json:"priority"`
+	// Category 和 Value 为旧版单条件格式，迁移时转换为view_rule后清空Value
+	Category  string     `gorm:"type:varchar(20);not null;default:'acl';comment:已废弃，旧版类型: acl 或 geoip" json:"category,omitempty"`
+	Value     string     `gorm:"type:text;comment:已废弃，旧版匹配规则内容(CIDR列表或GeoIP标签)" json:"value,omitempty"`
+	Rules     []ViewRule `gorm:"foreignKey:ViewID" json:"rules"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (View) TableName() string {
+	return "view"
+}
+
+// LegacyRules 将旧版Category/Value转换为保留旧版匹配方式的条件及其组合方式，Value为空时返回nil
+func (v *View) LegacyRules() (string, []ViewRule) {
+	if strings.TrimSpace(v.Value) == "" {
+		return ViewMatchAll, nil
+	}
+	switch v.Category {
+	case "acl":
+		return ViewMatchAll, []ViewRule{{ViewID: v.ID, Type: ViewRuleACL, Value: v.Value}}
+	case "geoip":
+		return ViewMatchAll, []ViewRule{{ViewID: v.ID, Type: ViewRuleGeoIP, Value: v.Value}}
+	}
+	return ViewMatchAll, nil
+}
+
+// ViewRule 视图的匹配条件
+type ViewRule struct {
 	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name      string    `gorm:"not null;uniqueIndex" json:"name"`
-	Category  string    `gorm:"type:varchar(20);not null;default:'acl';comment:类型: acl 或 geoip" json:"category"`
-	Value     string    `gorm:"type:text;comment:匹配规则内容(CIDR列表或GeoIP标签)" json:"value"`
-	Priority  int       `gorm:"type:int;not null;default:0;index;comment:匹配优先级(越小越优先)" json:"priority"`
+	ViewID    int64     `gorm:"type:bigint;not null;index;comment:关联view表的id" json:"view_id"`
+	Type      string    `gorm:"type:varchar(20);not null;comment:条件类型: cidr, continent, country, region, asn, org, ecs, transport 或 listener，旧版为 acl 或 geoip" json:"type"`
+	Value     string    `gorm:"type:text;comment:条件值，逗号分隔，命中任一项即满足" json:"value"`
+	Negate    bool      `gorm:"not null;default:false;comment:是否取反" json:"negate"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
-func (View) TableName() string {
-	return "view"
+func (ViewRule) TableName() string {
+	return "view_rule"
+}
+
+// Values 返回去除空白后的条件值列表
+func (r *ViewRule) Values() []string {
+	var values []string
+	for _, v := range strings.Split(r.Value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// ViewDeny 视图屏蔽条目：在该视图及其子视图中隐藏从父视图继承的名称，
+// 视图自身的记录不受影响
+type ViewDeny struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	ViewID    int64     `gorm:"type:bigint;not null;uniqueIndex:idx_view_deny;comment:关联view表的id" json:"view_id"`
+	ZoneID    int64     `gorm:"type:bigint;not null;uniqueIndex:idx_view_deny;comment:关联zone表的id" json:"zone_id"`
+	Name      string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_view_deny;comment:记录名称" json:"name"`
+	Type      string    `gorm:"type:varchar(50);not null;default:'';uniqueIndex:idx_view_deny;comment:记录类型，为空表示屏蔽该名称的所有类型" json:"type"`
+	Remark    string    `gorm:"type:varchar(256);comment:备注" json:"remark"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (ViewDeny) TableName() string {
+	return "view_deny"
 }
 
 func init() {
 	RegisterModel(&View{})
+	RegisterModel(&ViewRule{})
+	RegisterModel(&ViewDeny{})
 }
@@ -9,6 +9,7 @@ type Zone struct {
 	Contact     string `gorm:"type:varchar(255);comment:联系人;" json:"contact"`                       // 联系人
 	Email       string `gorm:"type:varchar(255);comment:联系邮箱;" json:"email"`                        // 联系邮箱
 	IsActive    bool   `gorm:"default:true;comment:该zone是否活跃;" json:"is_active"`                    // 该zone是否活跃
+	AutoPTR     bool   `gorm:"default:false;comment:是否根据A/AAAA记录自动生成PTR;" json:"auto_ptr"`          // 是否根据A/AAAA记录自动生成PTR
 
 	// 关联关系
 	Records []Record `gorm:"foreignKey:ZoneID;constraint:OnDelete:CASCADE" json:"records,omitempty"`
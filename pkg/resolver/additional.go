@@ -0,0 +1,66 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+)
+
+// additional appends A/AAAA records of in-zone MX, NS, SRV and SVCB/HTTPS
+// targets in the answer to the Additional section, looked up in the same view
+func (r *Resolver) additional(ctx context.Context, viewID int64, m *dns.Msg) error {
+	seen := make(map[string]struct{})
+	for _, rr := range m.Answer {
+		var target string
+		switch v := rr.(type) {
+		case *dns.MX:
+			target = v.Mx
+		case *dns.NS:
+			target = v.Ns
+		case *dns.SRV:
+			target = v.Target
+		case *dns.SVCB:
+			target = svcbTarget(v)
+		case *dns.HTTPS:
+			target = svcbTarget(&v.SVCB)
+		default:
+			continue
+		}
+
+		// "." means the service is explicitly not available
+		target = strings.ToLower(target)
+		if target == "." {
+			continue
+		}
+		if _, ok := seen[target]; ok {
+			continue
+		}
+		seen[target] = struct{}{}
+
+		rrs, err := r.addresses(ctx, target, viewID)
+		if err != nil {
+			return err
+		}
+		m.Extra = append(m.Extra, rrs...)
+	}
+	return nil
+}
+
+// trimAdditional drops trailing Additional records that do not fit the
+// client's buffer, so the answer is never truncated because of them
+func trimAdditional(state request.Request, m *dns.Msg) {
+	size := state.Size()
+	// Reserve room for the OPT record added when the response is written
+	if opt := state.Req.IsEdns0(); opt != nil {
+		size -= dns.Len(opt)
+	}
+
+	compress := m.Compress
+	m.Compress = true
+	for len(m.Extra) > 0 && m.Len() > size {
+		m.Extra = m.Extra[:len(m.Extra)-1]
+	}
+	m.Compress = compress
+}
@@ -0,0 +1,76 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestResolver_Resolve_Additional(t *testing.T) {
+	mockRepo := &MockDNSQueryRepository{
+		QueryMXRecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.MXRecord, error) {
+			return []*model.MXRecord{
+				{Host: "mx1.test.com.", Priority: 10, TTL: 600},
+				{Host: "mx.provider.net.", Priority: 20, TTL: 600},
+			}, nil
+		},
+		QuerySRVRecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SRVRecord, error) {
+			// Enough targets to overflow a 512 byte response
+			var res []*model.SRVRecord
+			for i := 0; i < 8; i++ {
+				res = append(res, &model.SRVRecord{Priority: 10, Weight: 10, Port: 5060, Target: fmt.Sprintf("sip%d.test.com.", i), TTL: 600})
+			}
+			return res, nil
+		},
+		QueryARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			if recordName == "mx1.test.com." && viewID != 10 {
+				return nil, nil
+			}
+			return []*model.ARecord{{IP: 0x0a000001, TTL: 600}, {IP: 0x0a000002, TTL: 600}}, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+
+	t.Run("MX targets in the same view", func(t *testing.T) {
+		m := new(dns.Msg)
+		m.Answer, _ = r.answer(context.Background(), "test.com.", "test.com.", dns.TypeMX, 10)
+		assert.NoError(t, r.additional(context.Background(), 10, m))
+		// Only the in-zone exchanger gets addresses
+		if assert.Len(t, m.Extra, 2) {
+			assert.Equal(t, "mx1.test.com.", m.Extra[0].Header().Name)
+		}
+
+		m = new(dns.Msg)
+		m.Answer, _ = r.answer(context.Background(), "test.com.", "test.com.", dns.TypeMX, 0)
+		assert.NoError(t, r.additional(context.Background(), 0, m))
+		assert.Empty(t, m.Extra)
+	})
+
+	t.Run("Additional data never truncates the answer", func(t *testing.T) {
+		req := new(dns.Msg)
+		req.SetQuestion("_sip._udp.test.com.", dns.TypeSRV)
+		req.SetEdns0(dns.MinMsgSize, false)
+		state := request.Request{W: mockW, Req: req}
+
+		msg, err := r.Resolve(context.Background(), state)
+		assert.NoError(t, err)
+		assert.Len(t, msg.Answer, 8)
+		assert.NotEmpty(t, msg.Extra)
+		assert.Less(t, len(msg.Extra), 16)
+
+		// The answer survives writing without setting TC
+		msg.SetEdns0(dns.MinMsgSize, false)
+		msg.Truncate(state.Size())
+		assert.False(t, msg.Truncated)
+		assert.Len(t, msg.Answer, 8)
+	})
+}
@@ -0,0 +1,61 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/miekg/dns"
+
+	"github.com/cylonchau/hermes/pkg/logger"
+)
+
+// aliasDepthKey counts nested ALIAS expansions in the context
+type aliasDepthKey struct{}
+
+// alias flattens an ALIAS owned by name into the A or AAAA RRset of its
+// target. In-zone targets are resolved from our own data in the same view,
+// external ones through the upstream when one is configured.
+func (r *Resolver) alias(ctx context.Context, zone, name string, qType uint16, viewID int64) ([]dns.RR, error) {
+	if qType != dns.TypeA && qType != dns.TypeAAAA {
+		return nil, nil
+	}
+	records, err := r.dao.QueryALIASRecords(ctx, zone, name, viewID)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	depth, _ := ctx.Value(aliasDepthKey{}).(int)
+	if depth >= maxCNAMEChain {
+		logger.Warn("ALIAS chain too long", logger.String("name", name))
+		return nil, nil
+	}
+	ctx = context.WithValue(ctx, aliasDepthKey{}, depth+1)
+	// The target's RRset policy is not applied to the flattened addresses
+	ctx = withPolicy(ctx, false)
+
+	target := strings.ToLower(dns.Fqdn(records[0].Target))
+	var rrs []dns.RR
+	if targetZone, ok := r.zones.Match(ctx, target); ok {
+		rrs, err = r.answer(ctx, targetZone, target, qType, viewID)
+		// The child zone of a delegated target is asked like an external zone
+		var delegated *referralError
+		if errors.As(err, &delegated) {
+			rrs, err = r.external(ctx, target, qType)
+		}
+	} else {
+		rrs, err = r.external(ctx, target, qType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the addresses are returned, the chain leading to them stays hidden
+	var res []dns.RR
+	for _, rr := range rrs {
+		if rr.Header().Rrtype == qType {
+			res = append(res, dns.Copy(rr))
+		}
+	}
+	return withOwner(res, name), nil
+}
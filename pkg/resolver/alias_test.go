@@ -0,0 +1,127 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// fakeUpstream answers every lookup with a fixed A record and counts calls
+type fakeUpstream struct {
+	calls int
+}
+
+func (f *fakeUpstream) Lookup(ctx context.Context, state request.Request, name string, typ uint16) (*dns.Msg, error) {
+	f.calls++
+	m := new(dns.Msg)
+	m.SetQuestion(name, typ)
+	if typ == dns.TypeA {
+		rr, _ := dns.NewRR(name + " 120 IN A 203.0.113.7")
+		m.Answer = append(m.Answer, rr)
+	}
+	return m, nil
+}
+
+func TestResolver_Resolve_ALIAS(t *testing.T) {
+	mockRepo := &MockDNSQueryRepository{
+		QueryALIASRecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ALIASRecord, error) {
+			switch recordName {
+			case "test.com.":
+				return []*model.ALIASRecord{{Target: "lb.test.com.", TTL: 3600}}, nil
+			case "ext.test.com.":
+				return []*model.ALIASRecord{{Target: "app.saas.example.", TTL: 3600}}, nil
+			case "loop.test.com.":
+				return []*model.ALIASRecord{{Target: "loop.test.com.", TTL: 3600}}, nil
+			}
+			return nil, nil
+		},
+		QueryCNAMERecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CNAMERecord, error) {
+			if recordName == "lb.test.com." {
+				return []*model.CNAMERecord{{Target: "pool.test.com.", TTL: 60}}, nil
+			}
+			return nil, nil
+		},
+		QueryARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			if recordName == "pool.test.com." {
+				return []*model.ARecord{{IP: 0x0a000001, TTL: 300}, {IP: 0x0a000002, TTL: 300}}, nil
+			}
+			return nil, nil
+		},
+		QueryAAAARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.AAAARecord, error) {
+			if recordName == "pool.test.com." {
+				return []*model.AAAARecord{{IP: net.ParseIP("2001:db8::1"), TTL: 300}}, nil
+			}
+			return nil, nil
+		},
+		QuerySOARecordFn: func(ctx context.Context, zoneName string, viewID int64) (*model.SOARecord, error) {
+			return &model.SOARecord{PrimaryNS: "ns1.test.com.", MBox: "admin.test.com.", MinTTL: 60, TTL: 3600}, nil
+		},
+		NameExistsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) (bool, error) {
+			return true, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+	resolve := func(name string, qType uint16) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion(name, qType)
+		msg, err := r.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.NoError(t, err)
+		return msg
+	}
+
+	t.Run("Apex flattened from in-zone data", func(t *testing.T) {
+		msg := resolve("test.com.", dns.TypeA)
+		if assert.Len(t, msg.Answer, 2) {
+			for _, rr := range msg.Answer {
+				assert.Equal(t, "test.com.", rr.Header().Name)
+				assert.Equal(t, dns.TypeA, rr.Header().Rrtype)
+				assert.Equal(t, uint32(300), rr.Header().Ttl)
+			}
+		}
+
+		msg = resolve("test.com.", dns.TypeAAAA)
+		if assert.Len(t, msg.Answer, 1) {
+			assert.Equal(t, "test.com.", msg.Answer[0].Header().Name)
+		}
+
+		// Other types at the owner are unaffected
+		msg = resolve("test.com.", dns.TypeMX)
+		assert.Empty(t, msg.Answer)
+		assert.Equal(t, dns.RcodeSuccess, msg.Rcode)
+	})
+
+	t.Run("External target without upstream", func(t *testing.T) {
+		msg := resolve("ext.test.com.", dns.TypeA)
+		assert.Empty(t, msg.Answer)
+	})
+
+	t.Run("External target through the upstream", func(t *testing.T) {
+		up := &fakeUpstream{}
+		r.SetUpstream(up)
+		defer r.SetUpstream(nil)
+
+		for i := 0; i < 2; i++ {
+			msg := resolve("ext.test.com.", dns.TypeA)
+			if assert.Len(t, msg.Answer, 1) {
+				assert.Equal(t, "ext.test.com.", msg.Answer[0].Header().Name)
+				assert.Equal(t, "203.0.113.7", msg.Answer[0].(*dns.A).A.String())
+				assert.LessOrEqual(t, msg.Answer[0].Header().Ttl, uint32(120))
+			}
+		}
+		// The second answer came from the cache
+		assert.Equal(t, 1, up.calls)
+	})
+
+	t.Run("Self-referencing ALIAS terminates", func(t *testing.T) {
+		msg := resolve("loop.test.com.", dns.TypeA)
+		assert.Empty(t, msg.Answer)
+	})
+}
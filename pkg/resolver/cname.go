@@ -0,0 +1,128 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/miekg/dns"
+
+	"github.com/cylonchau/hermes/pkg/logger"
+)
+
+// maxCNAMEChain limits how many CNAMEs are followed for a single query
+const maxCNAMEChain = 8
+
+// answer retrieves the qType RRset of name, following in-zone CNAMEs (RFC 1034 3.6.2)
+// and DNAME redirections (RFC 6672) within the same view. Targets outside served
+// zones are resolved through the upstream when one is configured, otherwise the
+// chain is returned as far as it could be followed. Names at or below a zone
+// cut end the chain with a referralError.
+func (r *Resolver) answer(ctx context.Context, zone, name string, qType uint16, viewID int64) ([]dns.RR, error) {
+	var chain []dns.RR
+	visited := map[string]struct{}{strings.ToLower(name): {}}
+
+	for i := 0; i <= maxCNAMEChain; i++ {
+		// The parent only holds glue for names at or below a zone cut
+		ns, err := r.delegation(ctx, zone, name, qType, viewID)
+		if err != nil {
+			return nil, err
+		}
+		if len(ns) > 0 {
+			return chain, &referralError{ns: ns}
+		}
+
+		// A DNAME above name redirects it before any of its own data is considered
+		dname, err := r.dname(ctx, zone, name, viewID)
+		if err != nil {
+			return nil, err
+		}
+
+		var cname *dns.CNAME
+		if dname != nil {
+			chain = append(chain, dname)
+			if cname = synthesize(dname, name); cname == nil {
+				return chain, errDNAMEOverflow
+			}
+		} else {
+			var rrs []dns.RR
+			rrs, cname, err = r.find(ctx, zone, name, qType, viewID)
+			if err != nil {
+				return nil, err
+			}
+			if len(rrs) > 0 {
+				return append(chain, rrs...), nil
+			}
+		}
+		if cname == nil || i == maxCNAMEChain {
+			break
+		}
+		chain = append(chain, cname)
+
+		target := strings.ToLower(cname.Target)
+		if _, loop := visited[target]; loop {
+			logger.Warn("CNAME loop detected", logger.String("name", name), logger.String("target", target))
+			break
+		}
+		visited[target] = struct{}{}
+
+		targetZone, ok := r.zones.Match(ctx, target)
+		if !ok {
+			rrs, err := r.external(ctx, target, qType)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, rrs...)
+			break
+		}
+		zone, name = targetZone, target
+	}
+
+	return chain, nil
+}
+
+// find looks up the qType RRset of name. When there is none it returns the
+// CNAME owned by name instead. Names that do not exist are answered from the
+// covering wildcard with owner names rewritten to name.
+func (r *Resolver) find(ctx context.Context, zone, name string, qType uint16, viewID int64) ([]dns.RR, *dns.CNAME, error) {
+	source := name
+	for {
+		rrs, err := r.lookup(ctx, zone, source, qType, viewID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rrs) > 0 {
+			return withOwner(rrs, name), nil, nil
+		}
+
+		// An ALIAS answers address queries in place of the missing RRset
+		rrs, err = r.alias(ctx, zone, source, qType, viewID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rrs) > 0 {
+			return withOwner(rrs, name), nil, nil
+		}
+
+		if qType != dns.TypeCNAME {
+			cnames, err := r.lookup(ctx, zone, source, dns.TypeCNAME, viewID)
+			if err != nil {
+				return nil, nil, err
+			}
+			// A name can only own a single CNAME
+			if len(cnames) > 0 {
+				if cname, ok := withOwner(cnames, name)[0].(*dns.CNAME); ok {
+					return nil, cname, nil
+				}
+			}
+		}
+
+		// Wildcard has already been tried
+		if source != name {
+			return nil, nil, nil
+		}
+		source, err = r.wildcardSource(ctx, zone, name, viewID)
+		if err != nil || source == "" {
+			return nil, nil, err
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestResolver_Resolve_CNAME(t *testing.T) {
+	cnames := map[string]string{
+		"www.test.com.":   "web.test.com.",
+		"web.test.com.":   "edge.other.com.",
+		"ext.test.com.":   "cdn.example.net.",
+		"loop1.test.com.": "loop2.test.com.",
+		"loop2.test.com.": "loop1.test.com.",
+	}
+	mockRepo := &MockDNSQueryRepository{
+		QueryCNAMERecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CNAMERecord, error) {
+			if target, ok := cnames[recordName]; ok {
+				return []*model.CNAMERecord{{Target: target, TTL: 300}}, nil
+			}
+			return nil, nil
+		},
+		QueryARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			if zoneName == "other.com." && recordName == "edge.other.com." {
+				return []*model.ARecord{{IP: 0x01020304, TTL: 60}}, nil
+			}
+			return nil, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.", "other.com.")
+
+	ctx := context.Background()
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+	resolve := func(qName string, qType uint16) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion(qName, qType)
+		msg, err := r.Resolve(ctx, request.Request{W: mockW, Req: req})
+		assert.NoError(t, err)
+		return msg
+	}
+
+	t.Run("Chain across served zones", func(t *testing.T) {
+		msg := resolve("www.test.com.", dns.TypeA)
+		assert.Len(t, msg.Answer, 3)
+		assert.Equal(t, "web.test.com.", msg.Answer[0].(*dns.CNAME).Target)
+		assert.Equal(t, "edge.other.com.", msg.Answer[1].(*dns.CNAME).Target)
+		assert.Equal(t, "edge.other.com.", msg.Answer[2].Header().Name)
+		assert.Equal(t, "1.2.3.4", msg.Answer[2].(*dns.A).A.String())
+	})
+
+	t.Run("Out-of-zone target", func(t *testing.T) {
+		msg := resolve("ext.test.com.", dns.TypeAAAA)
+		assert.Len(t, msg.Answer, 1)
+		assert.Equal(t, "cdn.example.net.", msg.Answer[0].(*dns.CNAME).Target)
+	})
+
+	t.Run("Out-of-zone target through the upstream", func(t *testing.T) {
+		up := &fakeUpstream{}
+		r.SetUpstream(up)
+		defer r.SetUpstream(nil)
+
+		for i := 0; i < 2; i++ {
+			msg := resolve("ext.test.com.", dns.TypeA)
+			if assert.Len(t, msg.Answer, 2) {
+				assert.Equal(t, "cdn.example.net.", msg.Answer[0].(*dns.CNAME).Target)
+				assert.Equal(t, "cdn.example.net.", msg.Answer[1].Header().Name)
+				assert.Equal(t, "203.0.113.7", msg.Answer[1].(*dns.A).A.String())
+			}
+		}
+		assert.Equal(t, 1, up.calls)
+
+		// No data upstream leaves the CNAME on its own
+		msg := resolve("ext.test.com.", dns.TypeAAAA)
+		assert.Len(t, msg.Answer, 1)
+	})
+
+	t.Run("Loop detection", func(t *testing.T) {
+		msg := resolve("loop1.test.com.", dns.TypeA)
+		assert.Len(t, msg.Answer, 2)
+	})
+
+	t.Run("Direct CNAME query is not chased", func(t *testing.T) {
+		msg := resolve("www.test.com.", dns.TypeCNAME)
+		assert.Len(t, msg.Answer, 1)
+	})
+}
+
+func TestResolver_Resolve_CNAMEMaxChain(t *testing.T) {
+	// Every name points to the next one: c0 -> c1 -> c2 -> ...
+	mockRepo := &MockDNSQueryRepository{
+		QueryCNAMERecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CNAMERecord, error) {
+			return []*model.CNAMERecord{{Target: "c" + recordName, TTL: 300}}, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+
+	req := new(dns.Msg)
+	req.SetQuestion("c.test.com.", dns.TypeA)
+	msg, err := r.Resolve(context.Background(), request.Request{W: &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}, Req: req})
+	assert.NoError(t, err)
+	assert.Len(t, msg.Answer, maxCNAMEChain)
+}
@@ -0,0 +1,83 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/miekg/dns"
+
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// referralError is returned by answer when a name it reaches lies at or
+// below a zone cut. The chain up to that name is answered together with a
+// referral to the child zone.
+type referralError struct {
+	ns []dns.RR
+}
+
+func (e *referralError) Error() string {
+	return "name is delegated"
+}
+
+// delegation returns the NS RRset of the zone cut at or above name, if any.
+// The NS RRset at the cut itself is authoritative for the parent only when
+// asking for DS, which therefore is not referred.
+func (r *Resolver) delegation(ctx context.Context, zone, name string, qType uint16, viewID int64) ([]dns.RR, error) {
+	cut, ok := r.cuts.Highest(ctx, name, zone)
+	if !ok || (cut == name && qType == dns.TypeDS) {
+		return nil, nil
+	}
+	return r.lookup(ctx, zone, cut, dns.TypeNS, viewID)
+}
+
+// referral turns m into a non-authoritative referral to the child zone with
+// glue addresses of in-zone name servers in the Additional section
+func (r *Resolver) referral(ctx context.Context, ns []dns.RR, viewID int64, m *dns.Msg) (*dns.Msg, error) {
+	m.Authoritative = false
+	m.Ns = append(m.Ns, ns...)
+
+	for _, rr := range ns {
+		nsRR, ok := rr.(*dns.NS)
+		if !ok {
+			continue
+		}
+		glue, err := r.addresses(ctx, nsRR.Ns, viewID)
+		if err != nil {
+			return nil, err
+		}
+		m.Extra = append(m.Extra, glue...)
+	}
+	return m, nil
+}
+
+// addresses returns the A and AAAA records of target when it lies inside a served zone
+func (r *Resolver) addresses(ctx context.Context, target string, viewID int64) ([]dns.RR, error) {
+	zone, ok := r.zones.Match(ctx, target)
+	if !ok {
+		return nil, nil
+	}
+	target = dns.Fqdn(target)
+
+	rrs, err := r.lookup(ctx, zone, target, dns.TypeA, viewID)
+	if err != nil {
+		return nil, err
+	}
+	aaaa, err := r.lookup(ctx, zone, target, dns.TypeAAAA, viewID)
+	if err != nil {
+		return nil, err
+	}
+	return append(rrs, aaaa...), nil
+}
+
+// loadDelegations loads owner names of NS records below zone apexes
+func (r *Resolver) loadDelegations(ctx context.Context) ([]string, error) {
+	db := r.db
+	if db == nil {
+		db = model.DB
+	}
+	if db == nil {
+		return nil, ErrNotReady
+	}
+	return rdb.NewRecordDAO(db).GetDelegationNames(ctx)
+}
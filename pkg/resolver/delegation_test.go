@@ -0,0 +1,116 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestResolver_Resolve_Referral(t *testing.T) {
+	mockRepo := &MockDNSQueryRepository{
+		QueryNSRecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.NSRecord, error) {
+			if recordName == "team.test.com." {
+				return []*model.NSRecord{
+					{NameServer: "ns1.team.test.com.", TTL: 86400},
+					{NameServer: "ns.external.net.", TTL: 86400},
+				}, nil
+			}
+			return nil, nil
+		},
+		QueryARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			switch recordName {
+			case "ns1.team.test.com.":
+				return []*model.ARecord{{IP: 0x0a000035, TTL: 86400}}, nil
+			case "www.test.com.":
+				return []*model.ARecord{{IP: 0x01020304, TTL: 600}}, nil
+			case "host.team.test.com.":
+				// Stale parent data below the cut
+				return []*model.ARecord{{IP: 0x0a0000ff, TTL: 600}}, nil
+			}
+			return nil, nil
+		},
+		QueryCNAMERecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CNAMERecord, error) {
+			if recordName == "app.test.com." {
+				return []*model.CNAMERecord{{Target: "host.team.test.com.", TTL: 300}}, nil
+			}
+			return nil, nil
+		},
+		QueryALIASRecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ALIASRecord, error) {
+			if recordName == "flat.test.com." {
+				return []*model.ALIASRecord{{Target: "host.team.test.com.", TTL: 300}}, nil
+			}
+			return nil, nil
+		},
+		QueryAAAARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.AAAARecord, error) {
+			if recordName == "ns1.team.test.com." {
+				return []*model.AAAARecord{{IP: net.ParseIP("2001:db8::35"), TTL: 86400}}, nil
+			}
+			return nil, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+	r.Delegations().Set([]string{"team.test.com."})
+
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+	resolve := func(qName string, qType uint16) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion(qName, qType)
+		msg, err := r.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.NoError(t, err)
+		return msg
+	}
+
+	for _, qName := range []string{"host.team.test.com.", "team.test.com."} {
+		t.Run("Referral for "+qName, func(t *testing.T) {
+			msg := resolve(qName, dns.TypeA)
+			assert.False(t, msg.Authoritative)
+			assert.Equal(t, dns.RcodeSuccess, msg.Rcode)
+			assert.Empty(t, msg.Answer)
+			assert.Len(t, msg.Ns, 2)
+			// Glue only for the in-zone name server
+			if assert.Len(t, msg.Extra, 2) {
+				assert.Equal(t, "ns1.team.test.com.", msg.Extra[0].Header().Name)
+				assert.Equal(t, dns.TypeA, msg.Extra[0].Header().Rrtype)
+				assert.Equal(t, dns.TypeAAAA, msg.Extra[1].Header().Rrtype)
+			}
+		})
+	}
+
+	t.Run("Names above the cut are answered", func(t *testing.T) {
+		msg := resolve("www.test.com.", dns.TypeA)
+		assert.True(t, msg.Authoritative)
+		assert.Len(t, msg.Answer, 1)
+	})
+
+	t.Run("CNAME into the child zone", func(t *testing.T) {
+		msg := resolve("app.test.com.", dns.TypeA)
+		assert.False(t, msg.Authoritative)
+		assert.Equal(t, dns.RcodeSuccess, msg.Rcode)
+		if assert.Len(t, msg.Answer, 1) {
+			assert.Equal(t, "host.team.test.com.", msg.Answer[0].(*dns.CNAME).Target)
+		}
+		assert.Len(t, msg.Ns, 2)
+		assert.Len(t, msg.Extra, 2)
+	})
+
+	t.Run("ALIAS into the child zone skips parent data", func(t *testing.T) {
+		rrs, err := r.answer(context.Background(), "test.com.", "flat.test.com.", dns.TypeA, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, rrs)
+	})
+
+	t.Run("DS at the cut is not referred", func(t *testing.T) {
+		mockRepo.QuerySOARecordFn = func(ctx context.Context, zoneName string, viewID int64) (*model.SOARecord, error) {
+			return &model.SOARecord{PrimaryNS: "ns1.test.com.", MBox: "admin.test.com.", TTL: 3600, MinTTL: 300}, nil
+		}
+		msg := resolve("team.test.com.", dns.TypeDS)
+		assert.True(t, msg.Authoritative)
+		assert.Empty(t, msg.Extra)
+	})
+}
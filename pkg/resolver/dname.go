@@ -0,0 +1,78 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/miekg/dns"
+
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// errDNAMEOverflow is returned when a DNAME substitution would produce a name
+// longer than allowed, which is answered with YXDOMAIN (RFC 6672 2.2)
+var errDNAMEOverflow = errors.New("DNAME substitution overflows the name")
+
+// dname returns the DNAME owned by the topmost ancestor of name inside zone.
+// Descending from the apex the first DNAME found applies, anything below its
+// owner, a nested DNAME included, is occluded (RFC 6672 3.2, RFC 1034 4.3.2).
+// A DNAME only redirects names below its owner, never the owner itself.
+func (r *Resolver) dname(ctx context.Context, zone, name string, viewID int64) (*dns.DNAME, error) {
+	owner, ok := r.dnameOwner(ctx, zone, name)
+	if !ok {
+		return nil, nil
+	}
+
+	rrs, err := r.lookup(ctx, zone, owner, dns.TypeDNAME, viewID)
+	if err != nil || len(rrs) == 0 {
+		return nil, err
+	}
+	dname, _ := rrs[0].(*dns.DNAME)
+	return dname, nil
+}
+
+// dnameOwner returns the topmost indexed DNAME owner between the zone apex
+// and the parent of name
+func (r *Resolver) dnameOwner(ctx context.Context, zone, name string) (string, bool) {
+	name = strings.ToLower(dns.Fqdn(name))
+	zone = strings.ToLower(dns.Fqdn(zone))
+	if name == zone {
+		return "", false
+	}
+	if owner, ok := r.dnames.Match(ctx, zone); ok && owner == zone {
+		return zone, true
+	}
+	owner, ok := r.dnames.Highest(ctx, name, zone)
+	if !ok || owner == name {
+		return "", false
+	}
+	return owner, true
+}
+
+// synthesize builds the CNAME for name under a DNAME owner by replacing the
+// owner suffix with the DNAME target. It returns nil if the result is too long.
+func synthesize(dname *dns.DNAME, name string) *dns.CNAME {
+	prefix := name[:len(name)-len(dname.Hdr.Name)]
+	target := prefix + dns.Fqdn(dname.Target)
+	if _, ok := dns.IsDomainName(target); !ok {
+		return nil
+	}
+	return &dns.CNAME{
+		Hdr:    dns.RR_Header{Name: name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: dname.Hdr.Ttl},
+		Target: target,
+	}
+}
+
+// loadDNAMEs loads owner names of DNAME records
+func (r *Resolver) loadDNAMEs(ctx context.Context) ([]string, error) {
+	db := r.db
+	if db == nil {
+		db = model.DB
+	}
+	if db == nil {
+		return nil, ErrNotReady
+	}
+	return rdb.NewRecordDAO(db).GetDNAMEOwners(ctx)
+}
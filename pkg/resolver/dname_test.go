@@ -0,0 +1,110 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestResolver_Resolve_DNAME(t *testing.T) {
+	mockRepo := &MockDNSQueryRepository{
+		QueryDNAMERecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.DNAMERecord, error) {
+			switch recordName {
+			case "old.test.com.":
+				// The view overrides the default redirection
+				if viewID == 10 {
+					return []*model.DNAMERecord{{Target: "v10.test.com.", TTL: 300}}, nil
+				}
+				return []*model.DNAMERecord{{Target: "new.test.com", TTL: 300}}, nil
+			case "ext.test.com.":
+				return []*model.DNAMERecord{{Target: "example.net.", TTL: 300}}, nil
+			case "sub.old.test.com.":
+				// Occluded by the DNAME at old.test.com.
+				return []*model.DNAMERecord{{Target: "other.test.com.", TTL: 300}}, nil
+			}
+			return nil, nil
+		},
+		QueryARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			switch recordName {
+			case "www.new.test.com.", "www.v10.test.com.", "old.test.com.", "www.sub.new.test.com.":
+				return []*model.ARecord{{IP: 0x0a000001, TTL: 600}}, nil
+			}
+			return nil, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+	r.DNAMEs().Set([]string{"old.test.com.", "ext.test.com.", "sub.old.test.com."})
+
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+	resolve := func(name string, qType uint16) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion(name, qType)
+		msg, err := r.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.NoError(t, err)
+		return msg
+	}
+
+	t.Run("Names below the owner are redirected", func(t *testing.T) {
+		msg := resolve("www.old.test.com.", dns.TypeA)
+		if assert.Len(t, msg.Answer, 3) {
+			assert.Equal(t, dns.TypeDNAME, msg.Answer[0].Header().Rrtype)
+			cname := msg.Answer[1].(*dns.CNAME)
+			assert.Equal(t, "www.old.test.com.", cname.Hdr.Name)
+			assert.Equal(t, "www.new.test.com.", cname.Target)
+			assert.Equal(t, uint32(300), cname.Hdr.Ttl)
+			assert.Equal(t, "www.new.test.com.", msg.Answer[2].Header().Name)
+		}
+		assert.Equal(t, dns.RcodeSuccess, msg.Rcode)
+	})
+
+	t.Run("Topmost of nested owners applies", func(t *testing.T) {
+		msg := resolve("www.sub.old.test.com.", dns.TypeA)
+		if assert.Len(t, msg.Answer, 3) {
+			assert.Equal(t, "old.test.com.", msg.Answer[0].Header().Name)
+			assert.Equal(t, "www.sub.new.test.com.", msg.Answer[1].(*dns.CNAME).Target)
+		}
+	})
+
+	t.Run("Owner keeps its own data", func(t *testing.T) {
+		msg := resolve("old.test.com.", dns.TypeA)
+		if assert.Len(t, msg.Answer, 1) {
+			assert.Equal(t, dns.TypeA, msg.Answer[0].Header().Rrtype)
+		}
+
+		msg = resolve("old.test.com.", dns.TypeDNAME)
+		if assert.Len(t, msg.Answer, 1) {
+			assert.Equal(t, "new.test.com.", msg.Answer[0].(*dns.DNAME).Target)
+		}
+	})
+
+	t.Run("Redirection follows the view", func(t *testing.T) {
+		rrs, err := r.answer(context.Background(), "test.com.", "www.old.test.com.", dns.TypeA, 10)
+		assert.NoError(t, err)
+		if assert.Len(t, rrs, 3) {
+			assert.Equal(t, "www.v10.test.com.", rrs[1].(*dns.CNAME).Target)
+		}
+	})
+
+	t.Run("Out-of-zone target is left to the client", func(t *testing.T) {
+		msg := resolve("a.b.ext.test.com.", dns.TypeAAAA)
+		if assert.Len(t, msg.Answer, 2) {
+			assert.Equal(t, "a.b.example.net.", msg.Answer[1].(*dns.CNAME).Target)
+		}
+	})
+
+	t.Run("Overlong substitution is YXDOMAIN", func(t *testing.T) {
+		mockRepo.QueryDNAMERecordsFn = func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.DNAMERecord, error) {
+			return []*model.DNAMERecord{{Target: strings.Repeat("x", 63) + "." + strings.Repeat("y", 63) + "." + strings.Repeat("z", 63) + ".", TTL: 300}}, nil
+		}
+		msg := resolve(strings.Repeat("a", 63)+"."+strings.Repeat("b", 40)+".old.test.com.", dns.TypeA)
+		assert.Equal(t, dns.RcodeYXDomain, msg.Rcode)
+		assert.Len(t, msg.Answer, 1)
+	})
+}
@@ -0,0 +1,145 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+)
+
+// errBadSubnet is returned for a malformed EDNS Client Subnet option
+var errBadSubnet = errors.New("malformed client subnet option")
+
+// ecsState holds the resolvers allowed to send EDNS Client Subnet, empty means any
+type ecsState struct {
+	trusted []netip.Prefix
+}
+
+// clientKey carries the address used for view, GeoIP and location matching
+type clientKey struct{}
+
+// SetECS enables EDNS Client Subnet (RFC 7871) for queries from the trusted
+// networks, or from anywhere when none are given
+func (r *Resolver) SetECS(trusted []netip.Prefix) {
+	r.ecs.Store(&ecsState{trusted: trusted})
+}
+
+// DisableECS ignores the EDNS Client Subnet option of queries
+func (r *Resolver) DisableECS() {
+	r.ecs.Store(nil)
+}
+
+// clientSubnet returns the ECS option of the request when ECS is enabled, and
+// whether its subnet may stand in for the client address
+func (r *Resolver) clientSubnet(state request.Request) (*dns.EDNS0_SUBNET, bool, error) {
+	es := r.ecs.Load()
+	if es == nil {
+		return nil, false, nil
+	}
+	opt := state.Req.IsEdns0()
+	if opt == nil {
+		return nil, false, nil
+	}
+	var ecs *dns.EDNS0_SUBNET
+	for _, o := range opt.Option {
+		if v, ok := o.(*dns.EDNS0_SUBNET); ok {
+			ecs = v
+			break
+		}
+	}
+	if ecs == nil {
+		return nil, false, nil
+	}
+
+	switch {
+	case ecs.Family == 1 && ecs.SourceNetmask <= 32:
+	case ecs.Family == 2 && ecs.SourceNetmask <= 128:
+	default:
+		return nil, false, errBadSubnet
+	}
+	if ecs.Address == nil {
+		return nil, false, errBadSubnet
+	}
+
+	// A source prefix of 0 means the client opted out
+	if ecs.SourceNetmask == 0 {
+		return ecs, false, nil
+	}
+	return ecs, es.trusts(state.IP()), nil
+}
+
+// trusts reports whether ip may send a client subnet
+func (es *ecsState) trusts(ip string) bool {
+	if len(es.trusted) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range es.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// subnetAddress returns the network address of the client subnet
+func subnetAddress(ecs *dns.EDNS0_SUBNET) string {
+	bits := 32
+	if ecs.Family == 2 {
+		bits = 128
+	}
+	ip := ecs.Address
+	if bits == 32 {
+		ip = ip.To4()
+	} else {
+		ip = ip.To16()
+	}
+	if ip == nil {
+		return ""
+	}
+	return ip.Mask(net.CIDRMask(int(ecs.SourceNetmask), bits)).String()
+}
+
+// subnetPrefix returns the client subnet as a prefix
+func subnetPrefix(ecs *dns.EDNS0_SUBNET) netip.Prefix {
+	addr, err := netip.ParseAddr(subnetAddress(ecs))
+	if err != nil {
+		return netip.Prefix{}
+	}
+	prefix, err := addr.Unmap().Prefix(int(ecs.SourceNetmask))
+	if err != nil {
+		return netip.Prefix{}
+	}
+	return prefix
+}
+
+// echoSubnet copies the client subnet into the response with the given scope
+func echoSubnet(state request.Request, m *dns.Msg, ecs *dns.EDNS0_SUBNET, scope uint8) {
+	opt := m.IsEdns0()
+	if opt == nil {
+		reqOpt := state.Req.IsEdns0()
+		m.SetEdns0(reqOpt.UDPSize(), reqOpt.Do())
+		opt = m.IsEdns0()
+	}
+	opt.Option = append(opt.Option, &dns.EDNS0_SUBNET{
+		Code:          dns.EDNS0SUBNET,
+		Family:        ecs.Family,
+		SourceNetmask: ecs.SourceNetmask,
+		SourceScope:   scope,
+		Address:       ecs.Address,
+	})
+}
+
+// clientIP returns the address used for matching, the client subnet when
+// ECS applies and the source address otherwise
+func clientIP(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientKey{}).(string)
+	return ip, ok
+}
@@ -0,0 +1,123 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestResolver_Resolve_ECS(t *testing.T) {
+	mockRepo := &MockDNSQueryRepository{
+		QueryARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			if viewID == 10 {
+				return []*model.ARecord{{IP: 0x0a0a0a0a, TTL: 60}}, nil
+			}
+			return []*model.ARecord{{IP: 0x01010101, TTL: 60}}, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+	// Clients in 203.0.113.0/24 belong to view 10
+	r.Views().Set([]model.View{{ID: 10, Name: "office", Category: "acl", Value: "203.0.113.0/24", Priority: 10}})
+
+	resolve := func(remote string, ecs *dns.EDNS0_SUBNET) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion("www.test.com.", dns.TypeA)
+		if ecs != nil {
+			req.SetEdns0(4096, false)
+			req.IsEdns0().Option = append(req.IsEdns0().Option, ecs)
+		}
+		w := &MockResponseWriter{RemoteIP: net.ParseIP(remote)}
+		msg, err := r.Resolve(context.Background(), request.Request{W: w, Req: req})
+		assert.NoError(t, err)
+		return msg
+	}
+	subnet := func(ip string, bits uint8) *dns.EDNS0_SUBNET {
+		return &dns.EDNS0_SUBNET{Code: dns.EDNS0SUBNET, Family: 1, SourceNetmask: bits, Address: net.ParseIP(ip).To4()}
+	}
+	answer := func(m *dns.Msg) string {
+		if assert.Len(t, m.Answer, 1) {
+			return m.Answer[0].(*dns.A).A.String()
+		}
+		return ""
+	}
+	echoed := func(m *dns.Msg) *dns.EDNS0_SUBNET {
+		if opt := m.IsEdns0(); opt != nil {
+			for _, o := range opt.Option {
+				if v, ok := o.(*dns.EDNS0_SUBNET); ok {
+					return v
+				}
+			}
+		}
+		return nil
+	}
+
+	t.Run("Disabled ignores the option", func(t *testing.T) {
+		msg := resolve("198.51.100.53", subnet("203.0.113.7", 24))
+		assert.Equal(t, "1.1.1.1", answer(msg))
+		assert.Nil(t, echoed(msg))
+	})
+
+	r.SetECS(nil)
+
+	t.Run("Subnet selects the view and is echoed with its scope", func(t *testing.T) {
+		msg := resolve("198.51.100.53", subnet("203.0.113.7", 24))
+		assert.Equal(t, "10.10.10.10", answer(msg))
+		if ecs := echoed(msg); assert.NotNil(t, ecs) {
+			assert.Equal(t, uint8(24), ecs.SourceNetmask)
+			assert.Equal(t, uint8(24), ecs.SourceScope)
+			assert.Equal(t, "203.0.113.7", ecs.Address.String())
+		}
+	})
+
+	t.Run("Opt-out uses the source address with scope 0", func(t *testing.T) {
+		msg := resolve("198.51.100.53", subnet("0.0.0.0", 0))
+		assert.Equal(t, "1.1.1.1", answer(msg))
+		if ecs := echoed(msg); assert.NotNil(t, ecs) {
+			assert.Equal(t, uint8(0), ecs.SourceScope)
+		}
+	})
+
+	t.Run("Malformed option is a format error", func(t *testing.T) {
+		msg := resolve("198.51.100.53", subnet("203.0.113.7", 33))
+		assert.Equal(t, dns.RcodeFormatError, msg.Rcode)
+
+		// Names that are not served fall through instead
+		req := new(dns.Msg)
+		req.SetQuestion("www.example.org.", dns.TypeA)
+		req.SetEdns0(4096, false)
+		req.IsEdns0().Option = append(req.IsEdns0().Option, subnet("203.0.113.7", 33))
+		w := &MockResponseWriter{RemoteIP: net.ParseIP("198.51.100.53")}
+		msg, err := r.Resolve(context.Background(), request.Request{W: w, Req: req})
+		assert.ErrorIs(t, err, ErrNotAuthoritative)
+		assert.Nil(t, msg)
+	})
+
+	r.SetECS([]netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")})
+
+	t.Run("Untrusted resolver", func(t *testing.T) {
+		msg := resolve("198.51.100.53", subnet("203.0.113.7", 24))
+		assert.Equal(t, "1.1.1.1", answer(msg))
+		if ecs := echoed(msg); assert.NotNil(t, ecs) {
+			assert.Equal(t, uint8(0), ecs.SourceScope)
+		}
+	})
+
+	t.Run("Trusted resolver", func(t *testing.T) {
+		msg := resolve("192.0.2.53", subnet("203.0.113.7", 24))
+		assert.Equal(t, "10.10.10.10", answer(msg))
+	})
+
+	r.DisableECS()
+}
+
+func TestSubnetAddress(t *testing.T) {
+	assert.Equal(t, "203.0.113.0", subnetAddress(&dns.EDNS0_SUBNET{Family: 1, SourceNetmask: 24, Address: net.ParseIP("203.0.113.77")}))
+	assert.Equal(t, "2001:db8::", subnetAddress(&dns.EDNS0_SUBNET{Family: 2, SourceNetmask: 56, Address: net.ParseIP("2001:db8::1")}))
+}
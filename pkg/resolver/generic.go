@@ -0,0 +1,32 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/miekg/dns"
+
+	"github.com/cylonchau/hermes/pkg/logger"
+)
+
+// generic serves types without a dedicated table from their stored RDATA,
+// kept in presentation format or in the RFC 3597 "\# len hex" form
+func (r *Resolver) generic(ctx context.Context, zone, name string, qType uint16, viewID int64) ([]dns.RR, error) {
+	records, err := r.dao.QueryGenericRecords(ctx, zone, name, qType, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rrs []dns.RR
+	for _, rec := range records {
+		rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", name, rec.TTL, dns.Type(qType), rec.RData))
+		if err != nil || rr == nil {
+			// RDATA is validated on write, so this only happens for rows edited by hand
+			logger.Warn("Skipping unparsable generic record",
+				logger.String("zone", zone), logger.String("qname", name), logger.Err(err))
+			continue
+		}
+		rrs = append(rrs, rr)
+	}
+	return rrs, nil
+}
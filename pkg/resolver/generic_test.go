@@ -0,0 +1,73 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestResolver_Resolve_Generic(t *testing.T) {
+	mockRepo := &MockDNSQueryRepository{
+		QueryGenericRecordsFn: func(ctx context.Context, zoneName, recordName string, rrType uint16, viewID int64) ([]*model.GenericRecord, error) {
+			switch {
+			case recordName == "test.com." && rrType == dns.TypeNAPTR:
+				return []*model.GenericRecord{
+					{RRType: rrType, RData: `100 10 "U" "E2U+sip" "!^.*$!sip:info@test.com!" .`, TTL: 300},
+					{RRType: rrType, RData: `not valid`, TTL: 300},
+				}, nil
+			case recordName == "test.com." && rrType == 65534:
+				return []*model.GenericRecord{{RRType: rrType, RData: `\# 4 0a000001`, TTL: 300}}, nil
+			}
+			return nil, nil
+		},
+		QuerySOARecordFn: func(ctx context.Context, zoneName string, viewID int64) (*model.SOARecord, error) {
+			return &model.SOARecord{PrimaryNS: "ns1.test.com.", MBox: "admin.test.com.", MinTTL: 60, TTL: 3600}, nil
+		},
+		NameExistsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) (bool, error) {
+			return recordName == "test.com.", nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+	resolve := func(qType uint16) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion("test.com.", qType)
+		msg, err := r.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.NoError(t, err)
+		return msg
+	}
+
+	t.Run("Presentation format", func(t *testing.T) {
+		msg := resolve(dns.TypeNAPTR)
+		// The unparsable row is skipped
+		if assert.Len(t, msg.Answer, 1) {
+			naptr, ok := msg.Answer[0].(*dns.NAPTR)
+			assert.True(t, ok)
+			assert.Equal(t, "E2U+sip", naptr.Service)
+			assert.Equal(t, uint32(300), naptr.Hdr.Ttl)
+		}
+	})
+
+	t.Run("RFC 3597 unknown type", func(t *testing.T) {
+		msg := resolve(65534)
+		if assert.Len(t, msg.Answer, 1) {
+			rfc3597, ok := msg.Answer[0].(*dns.RFC3597)
+			assert.True(t, ok)
+			assert.Equal(t, "0a000001", rfc3597.Rdata)
+		}
+	})
+
+	t.Run("No data for other types", func(t *testing.T) {
+		msg := resolve(dns.TypeLOC)
+		assert.Empty(t, msg.Answer)
+		assert.Equal(t, dns.RcodeSuccess, msg.Rcode)
+		assert.Len(t, msg.Ns, 1)
+	})
+}
@@ -1,46 +1,109 @@
 package resolver
 
 import (
+	"errors"
 	"fmt"
 	"net"
 
 	"github.com/oschwald/geoip2-golang"
 )
 
+// MaxMindProvider looks addresses up in MaxMind GeoIP2/GeoLite2 databases,
+// a City database for the location and an optional ASN database
 type MaxMindProvider struct {
-	db *geoip2.Reader
+	db  *geoip2.Reader
+	asn *geoip2.Reader
 }
 
-func NewMaxMindProvider(dbPath string) (*MaxMindProvider, error) {
-	db, err := geoip2.Open(dbPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open maxmind db: %w", err)
+// NewMaxMindProvider opens the City database at dbPath and, when asnPath is
+// not empty, the ASN database at asnPath. Either path may be empty, but not
+// both.
+func NewMaxMindProvider(dbPath, asnPath string) (*MaxMindProvider, error) {
+	if dbPath == "" && asnPath == "" {
+		return nil, errors.New("no maxmind db given")
+	}
+	p := &MaxMindProvider{}
+	if dbPath != "" {
+		db, err := geoip2.Open(dbPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open maxmind db: %w", err)
+		}
+		p.db = db
+	}
+	if asnPath != "" {
+		asn, err := geoip2.Open(asnPath)
+		if err != nil {
+			p.Close()
+			return nil, fmt.Errorf("failed to open maxmind asn db: %w", err)
+		}
+		p.asn = asn
 	}
-	return &MaxMindProvider{db: db}, nil
+	return p, nil
 }
 
-func (p *MaxMindProvider) Lookup(ipStr string) (countryCode string, regionCode string, err error) {
+// Lookup returns the continent, country and region from the City database
+// and the autonomous system from the ASN database
+func (p *MaxMindProvider) Lookup(ipStr string) (GeoInfo, error) {
+	var info GeoInfo
 	ip := net.ParseIP(ipStr)
 	if ip == nil {
-		return "", "", fmt.Errorf("invalid IP address: %s", ipStr)
+		return info, fmt.Errorf("invalid IP address: %s", ipStr)
 	}
 
-	record, err := p.db.City(ip)
-	if err != nil {
-		return "", "", err
+	if p.db != nil {
+		record, err := p.db.City(ip)
+		if err != nil {
+			return info, err
+		}
+		info.Continent = record.Continent.Code
+		info.Country = record.Country.IsoCode
+		if len(record.Subdivisions) > 0 {
+			info.Region = record.Subdivisions[0].IsoCode
+		}
+	}
+
+	if p.asn != nil {
+		record, err := p.asn.ASN(ip)
+		if err != nil {
+			return info, err
+		}
+		info.ASN = uint32(record.AutonomousSystemNumber)
+		info.Organization = record.AutonomousSystemOrganization
 	}
 
-	countryCode = record.Country.IsoCode
-	if len(record.Subdivisions) > 0 {
-		regionCode = record.Subdivisions[0].IsoCode
+	return info, nil
+}
+
+// Location returns the approximate coordinates of ipStr
+func (p *MaxMindProvider) Location(ipStr string) (latitude, longitude float64, err error) {
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return 0, 0, fmt.Errorf("invalid IP address: %s", ipStr)
+	}
+	if p.db == nil {
+		return 0, 0, errors.New("no city db")
 	}
 
-	return countryCode, regionCode, nil
+	record, err := p.db.City(ip)
+	if err != nil {
+		return 0, 0, err
+	}
+	// An empty location record means the IP is not in the database
+	if record.Location.AccuracyRadius == 0 && record.Location.Latitude == 0 && record.Location.Longitude == 0 {
+		return 0, 0, fmt.Errorf("no location for %s", ipStr)
+	}
+	return record.Location.Latitude, record.Location.Longitude, nil
 }
 
 func (p *MaxMindProvider) Close() error {
+	var err error
 	if p.db != nil {
-		return p.db.Close()
+		err = p.db.Close()
+	}
+	if p.asn != nil {
+		if asnErr := p.asn.Close(); err == nil {
+			err = asnErr
+		}
 	}
-	return nil
+	return err
 }
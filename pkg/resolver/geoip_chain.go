@@ -0,0 +1,81 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// GeoIPChain asks its providers in order and merges their answers. The
+// location (continent, country and region) and the autonomous system (ASN
+// and organization) are each taken as a whole from the first provider that
+// knows them, so an override pinning the country also decides the region.
+type GeoIPChain struct {
+	providers []GeoIPProvider
+}
+
+// NewGeoIPChain creates a chain of providers, earlier providers take precedence
+func NewGeoIPChain(providers ...GeoIPProvider) *GeoIPChain {
+	return &GeoIPChain{providers: providers}
+}
+
+// Lookup merges what the providers know about ip, it fails only when none
+// of them knows anything
+func (c *GeoIPChain) Lookup(ip string) (GeoInfo, error) {
+	var info GeoInfo
+	var located, hasASN bool
+	var lastErr error
+	for _, p := range c.providers {
+		res, err := p.Lookup(ip)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if !located && (res.Continent != "" || res.Country != "") {
+			info.Continent, info.Country, info.Region = res.Continent, res.Country, res.Region
+			located = true
+		}
+		if !hasASN && res.ASN != 0 {
+			info.ASN, info.Organization = res.ASN, res.Organization
+			hasASN = true
+		}
+		if located && hasASN {
+			break
+		}
+	}
+	if located || hasASN {
+		return info, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no record for %s", ip)
+	}
+	return info, lastErr
+}
+
+// Location returns the coordinates from the first provider that has them
+func (c *GeoIPChain) Location(ip string) (latitude, longitude float64, err error) {
+	err = errors.New("no location provider")
+	for _, p := range c.providers {
+		locator, ok := p.(LocationProvider)
+		if !ok {
+			continue
+		}
+		if latitude, longitude, err = locator.Location(ip); err == nil {
+			return latitude, longitude, nil
+		}
+	}
+	return 0, 0, err
+}
+
+// Close closes the providers holding resources
+func (c *GeoIPChain) Close() error {
+	var err error
+	for _, p := range c.providers {
+		if closer, ok := p.(io.Closer); ok {
+			if cerr := closer.Close(); err == nil {
+				err = cerr
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package resolver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestGeoIPChain(t *testing.T) {
+	overrides := NewOverrideProvider(nil)
+	overrides.Set([]*model.GeoIPOverride{{CIDR: "203.0.113.0/24", Country: "CN"}})
+	city := &MockGeoIPProvider{LookupFn: func(ip string) (GeoInfo, error) {
+		if ip == "192.0.2.1" {
+			return GeoInfo{}, errors.New("no record")
+		}
+		return GeoInfo{Continent: "NA", Country: "US", Region: "CA"}, nil
+	}}
+	asn := &MockGeoIPProvider{LookupFn: func(ip string) (GeoInfo, error) {
+		return GeoInfo{ASN: 64500, Organization: "Example"}, nil
+	}}
+	chain := NewGeoIPChain(overrides, city, asn)
+
+	// The override decides the whole location, the ASN comes from further down
+	info, err := chain.Lookup("203.0.113.1")
+	assert.NoError(t, err)
+	assert.Equal(t, GeoInfo{Country: "CN", ASN: 64500, Organization: "Example"}, info)
+
+	info, err = chain.Lookup("198.51.100.1")
+	assert.NoError(t, err)
+	assert.Equal(t, GeoInfo{Continent: "NA", Country: "US", Region: "CA", ASN: 64500, Organization: "Example"}, info)
+
+	info, err = chain.Lookup("192.0.2.1")
+	assert.NoError(t, err)
+	assert.Equal(t, GeoInfo{ASN: 64500, Organization: "Example"}, info)
+
+	_, err = NewGeoIPChain(overrides, city).Lookup("192.0.2.1")
+	assert.Error(t, err)
+
+	// Only the override can locate addresses here
+	lat := 31.23
+	overrides.Set([]*model.GeoIPOverride{{CIDR: "203.0.113.0/24", Country: "CN", Latitude: &lat, Longitude: &lat}})
+	_, _, err = chain.Location("203.0.113.1")
+	assert.NoError(t, err)
+	_, _, err = chain.Location("198.51.100.1")
+	assert.Error(t, err)
+}
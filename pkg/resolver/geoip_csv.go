@@ -0,0 +1,211 @@
+package resolver
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CSV column kinds
+const (
+	csvSkip = iota
+	csvStart
+	csvEnd
+	csvContinent
+	csvCountry
+	csvRegion
+	csvASN
+	csvOrg
+	csvLatitude
+	csvLongitude
+)
+
+// csvLayouts are the column layouts of the supported range files, trailing
+// columns missing from a file are left empty
+var csvLayouts = map[string][]int{
+	// IP2Location DB1 to DB5 and their LITE editions, regions are names
+	"ip2location": {csvStart, csvEnd, csvCountry, csvSkip, csvRegion, csvSkip, csvLatitude, csvLongitude},
+	// IP2Location ASN LITE
+	"ip2location-asn": {csvStart, csvEnd, csvSkip, csvASN, csvOrg},
+	// DB-IP city editions, regions are names
+	"dbip": {csvStart, csvEnd, csvContinent, csvCountry, csvRegion, csvSkip, csvLatitude, csvLongitude},
+	// DB-IP country editions
+	"dbip-country": {csvStart, csvEnd, csvCountry},
+	// DB-IP ASN editions
+	"dbip-asn": {csvStart, csvEnd, csvASN, csvOrg},
+}
+
+// CSVLayouts returns the names of the supported CSV layouts
+func CSVLayouts() []string {
+	names := make([]string, 0, len(csvLayouts))
+	for name := range csvLayouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CSVProvider looks addresses up in IP2Location or DB-IP style CSV files of
+// address ranges, loaded into an in-memory interval tree
+type CSVProvider struct {
+	tree *rangeTree
+}
+
+// NewCSVProvider loads the range file at path using the named layout
+func NewCSVProvider(path, layout string) (*CSVProvider, error) {
+	columns, ok := csvLayouts[layout]
+	if !ok {
+		return nil, fmt.Errorf("unknown csv layout %q, use one of %s", layout, strings.Join(CSVLayouts(), ", "))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open csv db: %w", err)
+	}
+	defer f.Close()
+
+	ranges, err := readCSVRanges(f, columns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv db %s: %w", path, err)
+	}
+	return &CSVProvider{tree: newRangeTree(ranges)}, nil
+}
+
+// readCSVRanges parses one range per line, a header line is skipped
+func readCSVRanges(r io.Reader, columns []int) ([]geoRange, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	var ranges []geoRange
+	for line := 1; ; line++ {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return ranges, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rng, err := parseCSVRange(fields, columns)
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ranges = append(ranges, rng)
+	}
+}
+
+// parseCSVRange maps the fields of a line to a range, "-" meaning unknown
+func parseCSVRange(fields []string, columns []int) (geoRange, error) {
+	var rng geoRange
+	var lat, lon string
+	for i, kind := range columns {
+		if i >= len(fields) {
+			break
+		}
+		v := strings.TrimSpace(fields[i])
+		if v == "-" {
+			v = ""
+		}
+		var err error
+		switch kind {
+		case csvStart:
+			rng.start, err = parseCSVAddr(v)
+		case csvEnd:
+			rng.end, err = parseCSVAddr(v)
+		case csvContinent:
+			rng.info.Continent = v
+		case csvCountry:
+			rng.info.Country = v
+		case csvRegion:
+			rng.info.Region = v
+		case csvASN:
+			if v != "" {
+				if asn, ok := parseASN(v); ok {
+					rng.info.ASN = asn
+				}
+			}
+		case csvOrg:
+			rng.info.Organization = v
+		case csvLatitude:
+			lat = v
+		case csvLongitude:
+			lon = v
+		}
+		if err != nil {
+			return rng, err
+		}
+	}
+	if !rng.start.IsValid() || !rng.end.IsValid() {
+		return rng, errors.New("missing address range")
+	}
+	if lat != "" && lon != "" {
+		latitude, latErr := strconv.ParseFloat(lat, 64)
+		longitude, lonErr := strconv.ParseFloat(lon, 64)
+		if latErr == nil && lonErr == nil {
+			rng.latitude, rng.longitude, rng.located = latitude, longitude, true
+		}
+	}
+	return rng, nil
+}
+
+// parseCSVAddr parses an address written as text or, as IP2Location does,
+// as a decimal number. Numbers up to 2^32-1 are IPv4 addresses.
+func parseCSVAddr(s string) (netip.Addr, error) {
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return addr.Unmap(), nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok || n.Sign() < 0 || n.BitLen() > 128 {
+		return netip.Addr{}, fmt.Errorf("invalid address %q", s)
+	}
+	if n.BitLen() <= 32 {
+		v := uint32(n.Uint64())
+		return netip.AddrFrom4([4]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}), nil
+	}
+	var b [16]byte
+	n.FillBytes(b[:])
+	return netip.AddrFrom16(b).Unmap(), nil
+}
+
+// Lookup returns what the range containing ip is known for
+func (p *CSVProvider) Lookup(ip string) (GeoInfo, error) {
+	rng, err := p.find(ip)
+	if err != nil {
+		return GeoInfo{}, err
+	}
+	return rng.info, nil
+}
+
+// Location returns the coordinates of the range containing ip
+func (p *CSVProvider) Location(ip string) (latitude, longitude float64, err error) {
+	rng, err := p.find(ip)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !rng.located {
+		return 0, 0, fmt.Errorf("no location for %s", ip)
+	}
+	return rng.latitude, rng.longitude, nil
+}
+
+// find returns the range containing ip
+func (p *CSVProvider) find(ip string) (*geoRange, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP address: %s", ip)
+	}
+	rng := p.tree.lookup(addr)
+	if rng == nil {
+		return nil, fmt.Errorf("no record for %s", ip)
+	}
+	return rng, nil
+}
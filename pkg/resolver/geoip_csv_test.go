@@ -0,0 +1,131 @@
+package resolver
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeCSV writes content to a file in a temporary directory
+func writeCSV(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "ranges.csv")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestCSVProvider_IP2Location(t *testing.T) {
+	// 1.0.0.0-1.0.0.255, 1.0.1.0-1.0.3.255 and ::ffff:1.0.4.0-::ffff:1.0.4.255 from an IPv6 file
+	path := writeCSV(t, `"16777216","16777471","AU","Australia","Queensland","Brisbane","-27.467940","153.028090"
+"16777472","16778239","CN","China","Fujian","Fuzhou","26.061390","119.306110"
+"281470698521600","281470698521855","CN","China","Guangdong","Guangzhou","23.116670","113.250000"
+"58568830314966105553470370067249102848","58568830314966105553470370067249102863","DE","Germany","-","-","-","-"
+`)
+	p, err := NewCSVProvider(path, "ip2location")
+	assert.NoError(t, err)
+
+	info, err := p.Lookup("1.0.2.3")
+	assert.NoError(t, err)
+	assert.Equal(t, GeoInfo{Country: "CN", Region: "Fujian"}, info)
+
+	info, err = p.Lookup("::ffff:1.0.4.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Guangdong", info.Region)
+
+	// 2c0f:f248::/124 written as a number
+	info, err = p.Lookup("2c0f:f248::5")
+	assert.NoError(t, err)
+	assert.Equal(t, GeoInfo{Country: "DE"}, info)
+
+	lat, lon, err := p.Location("1.0.0.1")
+	assert.NoError(t, err)
+	assert.InDelta(t, -27.46794, lat, 1e-6)
+	assert.InDelta(t, 153.02809, lon, 1e-6)
+
+	_, _, err = p.Location("2c0f:f248::5")
+	assert.Error(t, err)
+
+	_, err = p.Lookup("8.8.8.8")
+	assert.Error(t, err)
+	_, err = p.Lookup("invalid-ip")
+	assert.Error(t, err)
+}
+
+func TestCSVProvider_DBIP(t *testing.T) {
+	city := writeCSV(t, `ip_start,ip_end,continent,country,stateprov,city,latitude,longitude
+1.0.0.0,1.0.0.255,OC,AU,Queensland,South Brisbane,-27.4767,153.017
+2001:200::,2001:200:ffff:ffff:ffff:ffff:ffff:ffff,AS,JP,Tokyo,Tokyo,35.6895,139.692
+`)
+	p, err := NewCSVProvider(city, "dbip")
+	assert.NoError(t, err)
+	info, err := p.Lookup("2001:200::1")
+	assert.NoError(t, err)
+	assert.Equal(t, GeoInfo{Continent: "AS", Country: "JP", Region: "Tokyo"}, info)
+
+	asn := writeCSV(t, "1.0.0.0,1.0.0.255,13335,Cloudflare\n")
+	p, err = NewCSVProvider(asn, "dbip-asn")
+	assert.NoError(t, err)
+	info, err = p.Lookup("1.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, GeoInfo{ASN: 13335, Organization: "Cloudflare"}, info)
+
+	_, err = NewCSVProvider(writeCSV(t, "1.0.0.0,1.0.0.255,AU\nbroken,line,AU\n"), "dbip-country")
+	assert.ErrorContains(t, err, "line 2")
+
+	_, err = NewCSVProvider(asn, "maxmind")
+	assert.Error(t, err)
+}
+
+func TestRangeTree_Lookup(t *testing.T) {
+	rng := func(start, end, country string) geoRange {
+		return geoRange{
+			start: netip.MustParseAddr(start),
+			end:   netip.MustParseAddr(end),
+			info:  GeoInfo{Country: country},
+		}
+	}
+	tree := newRangeTree([]geoRange{
+		rng("10.0.0.0", "10.255.255.255", "A"),
+		rng("10.1.0.0", "10.1.255.255", "B"),
+		rng("10.1.2.0", "10.1.2.255", "C"),
+		rng("10.2.0.0", "10.2.255.255", "D"),
+		rng("192.0.2.0", "192.0.2.255", "E"),
+		rng("2001:db8::", "2001:db8::ffff", "F"),
+		// Dropped: reversed and mixed family ranges
+		rng("172.16.0.10", "172.16.0.1", "X"),
+		rng("172.16.0.0", "2001:db8::1", "X"),
+	})
+
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"10.0.0.1", "A"},
+		{"10.1.0.1", "B"},
+		{"10.1.2.3", "C"},
+		{"10.1.3.0", "B"},
+		{"10.2.0.0", "D"},
+		{"10.255.255.255", "A"},
+		{"192.0.2.128", "E"},
+		{"::ffff:192.0.2.1", "E"},
+		{"2001:db8::1", "F"},
+		{"172.16.0.5", ""},
+		{"11.0.0.0", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if r := tree.lookup(netip.MustParseAddr(tt.addr)); r != nil {
+			got = r.info.Country
+		}
+		assert.Equal(t, tt.want, got, tt.addr)
+	}
+
+	start, end := prefixRange(netip.MustParsePrefix("192.0.2.77/26"))
+	assert.Equal(t, "192.0.2.64", start.String())
+	assert.Equal(t, "192.0.2.127", end.String())
+	start, end = prefixRange(netip.MustParsePrefix("2001:db8::/32"))
+	assert.Equal(t, "2001:db8::", start.String())
+	assert.Equal(t, "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff", end.String())
+}
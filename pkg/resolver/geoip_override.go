@@ -0,0 +1,125 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sync/atomic"
+	"time"
+
+	"github.com/cylonchau/hermes/pkg/logger"
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// OverrideLoader returns all GeoIP overrides
+type OverrideLoader func(ctx context.Context) ([]*model.GeoIPOverride, error)
+
+// OverrideProvider serves the GeoIP overrides pinned by operators, the most
+// specific CIDR containing an address wins. Chain it before the GeoIP
+// databases so overrides take precedence.
+type OverrideProvider struct {
+	loader OverrideLoader
+	tree   atomic.Pointer[rangeTree]
+}
+
+// NewOverrideProvider creates an override provider backed by the given loader
+func NewOverrideProvider(loader OverrideLoader) *OverrideProvider {
+	return &OverrideProvider{loader: loader}
+}
+
+// Refresh reloads the overrides from the loader and swaps the tree
+func (p *OverrideProvider) Refresh(ctx context.Context) error {
+	if p.loader == nil {
+		return nil
+	}
+	overrides, err := p.loader(ctx)
+	if err != nil {
+		return err
+	}
+	p.Set(overrides)
+	return nil
+}
+
+// Set replaces the current overrides, entries with an invalid CIDR are skipped
+func (p *OverrideProvider) Set(overrides []*model.GeoIPOverride) {
+	ranges := make([]geoRange, 0, len(overrides))
+	for _, o := range overrides {
+		prefix, err := netip.ParsePrefix(o.CIDR)
+		if err != nil {
+			logger.Warn("Skipping GeoIP override with invalid CIDR", logger.String("cidr", o.CIDR))
+			continue
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		rng := geoRange{info: GeoInfo{
+			Continent:    o.Continent,
+			Country:      o.Country,
+			Region:       o.Region,
+			ASN:          o.ASN,
+			Organization: o.Organization,
+		}}
+		rng.start, rng.end = prefixRange(prefix)
+		if o.Latitude != nil && o.Longitude != nil {
+			rng.latitude, rng.longitude, rng.located = *o.Latitude, *o.Longitude, true
+		}
+		ranges = append(ranges, rng)
+	}
+	p.tree.Store(newRangeTree(ranges))
+}
+
+// Lookup returns the override of the most specific CIDR containing ip
+func (p *OverrideProvider) Lookup(ip string) (GeoInfo, error) {
+	rng, err := p.find(ip)
+	if err != nil {
+		return GeoInfo{}, err
+	}
+	return rng.info, nil
+}
+
+// Location returns the coordinates of the most specific CIDR containing ip
+// that has them
+func (p *OverrideProvider) Location(ip string) (latitude, longitude float64, err error) {
+	rng, err := p.find(ip)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !rng.located {
+		return 0, 0, fmt.Errorf("no location for %s", ip)
+	}
+	return rng.latitude, rng.longitude, nil
+}
+
+// find returns the override range containing ip
+func (p *OverrideProvider) find(ip string) (*geoRange, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP address: %s", ip)
+	}
+	rng := p.tree.Load().lookup(addr)
+	if rng == nil {
+		return nil, fmt.Errorf("no override for %s", ip)
+	}
+	return rng, nil
+}
+
+// Run periodically refreshes the overrides until ctx is cancelled
+func (p *OverrideProvider) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.Refresh(ctx); err != nil {
+				logger.Warn("Failed to refresh GeoIP overrides", logger.Err(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestOverrideProvider(t *testing.T) {
+	lat, lon := 31.23, 121.47
+	overrides := []*model.GeoIPOverride{
+		{CIDR: "203.0.113.0/24", Continent: "AS", Country: "CN", Region: "SH", Latitude: &lat, Longitude: &lon},
+		// Partner NAT inside the office block
+		{CIDR: "203.0.113.64/28", Country: "HK", ASN: 64500, Organization: "Partner"},
+		{CIDR: "::ffff:198.51.100.0/120", Country: "JP"},
+		{CIDR: "2001:db8::/32", Country: "DE"},
+		{CIDR: "not-a-cidr", Country: "US"},
+	}
+	var loadErr error
+	p := NewOverrideProvider(func(ctx context.Context) ([]*model.GeoIPOverride, error) {
+		return overrides, loadErr
+	})
+
+	// Nothing is known before the first load
+	_, err := p.Lookup("203.0.113.1")
+	assert.Error(t, err)
+
+	assert.NoError(t, p.Refresh(context.Background()))
+
+	info, err := p.Lookup("203.0.113.1")
+	assert.NoError(t, err)
+	assert.Equal(t, GeoInfo{Continent: "AS", Country: "CN", Region: "SH"}, info)
+
+	info, err = p.Lookup("203.0.113.70")
+	assert.NoError(t, err)
+	assert.Equal(t, GeoInfo{Country: "HK", ASN: 64500, Organization: "Partner"}, info)
+
+	info, err = p.Lookup("198.51.100.9")
+	assert.NoError(t, err)
+	assert.Equal(t, "JP", info.Country)
+
+	info, err = p.Lookup("2001:db8:1::1")
+	assert.NoError(t, err)
+	assert.Equal(t, "DE", info.Country)
+
+	_, err = p.Lookup("192.0.2.1")
+	assert.Error(t, err)
+
+	gotLat, gotLon, err := p.Location("203.0.113.1")
+	assert.NoError(t, err)
+	assert.Equal(t, lat, gotLat)
+	assert.Equal(t, lon, gotLon)
+	_, _, err = p.Location("203.0.113.70")
+	assert.Error(t, err)
+
+	// A failed refresh keeps the previous overrides
+	loadErr = errors.New("db down")
+	assert.Error(t, p.Refresh(context.Background()))
+	_, err = p.Lookup("203.0.113.1")
+	assert.NoError(t, err)
+}
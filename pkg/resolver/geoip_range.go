@@ -0,0 +1,117 @@
+package resolver
+
+import (
+	"net/netip"
+	"sort"
+)
+
+// geoRange is an inclusive address range and what is known about it
+type geoRange struct {
+	start, end netip.Addr
+	info       GeoInfo
+	located    bool // latitude and longitude are set
+	latitude   float64
+	longitude  float64
+}
+
+// rangeTree is a static interval tree over address ranges: the ranges sorted
+// by start form an implicit balanced tree, each node keeping the highest end
+// of its subtree so lookups skip subtrees ending before the address
+type rangeTree struct {
+	ranges []geoRange
+	maxEnd []netip.Addr
+}
+
+// newRangeTree builds a tree from ranges, dropping ranges whose ends are of
+// different address families or out of order
+func newRangeTree(ranges []geoRange) *rangeTree {
+	valid := make([]geoRange, 0, len(ranges))
+	for _, r := range ranges {
+		r.start, r.end = r.start.Unmap(), r.end.Unmap()
+		if r.start.Is4() == r.end.Is4() && r.start.Compare(r.end) <= 0 {
+			valid = append(valid, r)
+		}
+	}
+	sort.SliceStable(valid, func(a, b int) bool {
+		return valid[a].start.Less(valid[b].start)
+	})
+
+	t := &rangeTree{ranges: valid, maxEnd: make([]netip.Addr, len(valid))}
+	t.build(0, len(valid))
+	return t
+}
+
+// build fills maxEnd for the subtree rooted at the middle of [lo, hi)
+func (t *rangeTree) build(lo, hi int) netip.Addr {
+	if lo >= hi {
+		return netip.Addr{}
+	}
+	mid := (lo + hi) / 2
+	end := t.ranges[mid].end
+	for _, child := range []netip.Addr{t.build(lo, mid), t.build(mid+1, hi)} {
+		if child.IsValid() && end.Less(child) {
+			end = child
+		}
+	}
+	t.maxEnd[mid] = end
+	return end
+}
+
+// lookup returns the narrowest range containing addr, nil if none does.
+// Ranges either nest or are disjoint, so the narrowest one is the containing
+// range with the highest start.
+func (t *rangeTree) lookup(addr netip.Addr) *geoRange {
+	if t == nil || !addr.IsValid() {
+		return nil
+	}
+	addr = addr.Unmap()
+	var best *geoRange
+	t.search(0, len(t.ranges), addr, &best)
+	return best
+}
+
+// search looks for ranges containing addr in the subtree of [lo, hi)
+func (t *rangeTree) search(lo, hi int, addr netip.Addr, best **geoRange) {
+	if lo >= hi {
+		return
+	}
+	mid := (lo + hi) / 2
+	if t.maxEnd[mid].Less(addr) {
+		return
+	}
+	t.search(lo, mid, addr, best)
+	r := &t.ranges[mid]
+	if addr.Less(r.start) {
+		return
+	}
+	if addr.Compare(r.end) <= 0 && (*best == nil || narrower(r, *best)) {
+		*best = r
+	}
+	t.search(mid+1, hi, addr, best)
+}
+
+// narrower reports whether a lies inside b
+func narrower(a, b *geoRange) bool {
+	if c := a.start.Compare(b.start); c != 0 {
+		return c > 0
+	}
+	return a.end.Less(b.end)
+}
+
+// prefixRange returns the first and last address of a prefix
+func prefixRange(prefix netip.Prefix) (netip.Addr, netip.Addr) {
+	prefix = prefix.Masked()
+	last := prefix.Addr().As16()
+	offset := 0
+	if prefix.Addr().Is4() {
+		offset = 96
+	}
+	for i := offset + prefix.Bits(); i < 128; i++ {
+		last[i/8] |= 1 << (7 - i%8)
+	}
+	end := netip.AddrFrom16(last)
+	if prefix.Addr().Is4() {
+		end = end.Unmap()
+	}
+	return prefix.Addr(), end
+}
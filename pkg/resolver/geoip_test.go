@@ -1,43 +1,91 @@
 package resolver
 
 import (
-	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestMaxMindProvider_Lookup(t *testing.T) {
-	// 使用系统中现有的测试数据
-	dbPath := "../../target/coredns-src/plugin/geoip/testdata/GeoLite2-City.mmdb"
+// 测试库由 testdata/mmdb 生成: go run ./resolver/testdata/mmdb
+const (
+	testCityDB = "testdata/GeoLite2-City-Test.mmdb"
+	testASNDB  = "testdata/GeoLite2-ASN-Test.mmdb"
+)
+
+func TestNewMaxMindProvider(t *testing.T) {
+	_, err := NewMaxMindProvider("", "")
+	assert.Error(t, err)
 
-	// 检查测试文件是否存在，不存在则跳过（防止在不同环境下执行失败）
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		t.Skip("Test MMDB file not found, skipping unit test")
-	}
+	_, err = NewMaxMindProvider("", "testdata/missing-asn.mmdb")
+	assert.Error(t, err)
 
-	provider, err := NewMaxMindProvider(dbPath)
+	_, err = NewMaxMindProvider(testCityDB, "testdata/missing-asn.mmdb")
+	assert.Error(t, err)
+}
+
+func TestMaxMindProvider_Lookup(t *testing.T) {
+	provider, err := NewMaxMindProvider(testCityDB, testASNDB)
 	assert.NoError(t, err)
 	defer provider.Close()
 
 	t.Run("Valid IPv4 Lookup", func(t *testing.T) {
-		// 假设 81.2.69.142 在测试库中有数据 (GeoIP2 官方测试库常包含这个)
-		country, region, err := provider.Lookup("81.2.69.142")
-		if err == nil {
-			assert.NotEmpty(t, country)
-			t.Logf("Lookup success: Country=%s, Region=%s", country, region)
-		} else {
-			t.Logf("Lookup failed (expected if IP not in test db): %v", err)
-		}
+		info, err := provider.Lookup("81.2.69.142")
+		assert.NoError(t, err)
+		assert.Equal(t, GeoInfo{
+			Continent:    "EU",
+			Country:      "GB",
+			Region:       "ENG",
+			ASN:          20712,
+			Organization: "Andrews & Arnold Ltd",
+		}, info)
+	})
+
+	t.Run("Outside ASN networks", func(t *testing.T) {
+		info, err := provider.Lookup("192.0.2.1")
+		assert.NoError(t, err)
+		assert.Equal(t, GeoInfo{Continent: "OC", Country: "AU"}, info)
+	})
+
+	t.Run("Unknown IP", func(t *testing.T) {
+		info, err := provider.Lookup("8.8.8.8")
+		assert.NoError(t, err)
+		assert.Equal(t, GeoInfo{}, info)
+	})
+
+	t.Run("ASN only", func(t *testing.T) {
+		asn, err := NewMaxMindProvider("", testASNDB)
+		assert.NoError(t, err)
+		defer asn.Close()
+
+		info, err := asn.Lookup("81.2.69.1")
+		assert.NoError(t, err)
+		assert.Equal(t, GeoInfo{ASN: 20712, Organization: "Andrews & Arnold Ltd"}, info)
+
+		_, _, err = asn.Location("81.2.69.142")
+		assert.Error(t, err)
 	})
 
 	t.Run("Invalid IP", func(t *testing.T) {
-		_, _, err := provider.Lookup("invalid-ip")
+		_, err := provider.Lookup("invalid-ip")
+		assert.Error(t, err)
+	})
+
+	t.Run("Location", func(t *testing.T) {
+		lat, lon, err := provider.Location("81.2.69.143")
+		assert.NoError(t, err)
+		assert.InDelta(t, 51.5142, lat, 1e-6)
+		assert.InDelta(t, -0.0931, lon, 1e-6)
+
+		// 库中没有坐标
+		_, _, err = provider.Location("192.0.2.1")
+		assert.Error(t, err)
+
+		_, _, err = provider.Location("invalid-ip")
 		assert.Error(t, err)
 	})
 
 	t.Run("Empty IP", func(t *testing.T) {
-		_, _, err := provider.Lookup("")
+		_, err := provider.Lookup("")
 		assert.Error(t, err)
 	})
 }
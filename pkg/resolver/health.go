@@ -0,0 +1,97 @@
+package resolver
+
+import (
+	"net"
+
+	"github.com/miekg/dns"
+)
+
+// HealthChecker reports the health of addresses attached to a health check.
+// It is satisfied by health.Checker.
+type HealthChecker interface {
+	Healthy(checkID int64, ip net.IP) bool
+	Fallback(checkID int64) []net.IP
+}
+
+// healthState holds the configured health checker
+type healthState struct {
+	checker HealthChecker
+}
+
+// SetHealth enables leaving unhealthy addresses out of answers; nil disables it
+func (r *Resolver) SetHealth(h HealthChecker) {
+	if h == nil {
+		r.health.Store(nil)
+		return
+	}
+	r.health.Store(&healthState{checker: h})
+}
+
+// healthy drops A/AAAA members whose address failed its health check.
+// When every checked member is down the fallback addresses of their checks
+// are served instead, or the full set when no fallback is configured.
+// Members come from rows read through the L1 cache, which keeps every
+// address, so a state change shows in the next answer without invalidation.
+func (r *Resolver) healthy(members []member) []member {
+	hs := r.health.Load()
+	if hs == nil || len(members) == 0 {
+		return members
+	}
+
+	var (
+		kept []member
+		down []int64
+	)
+	for _, m := range members {
+		if m.checkID > 0 && !hs.checker.Healthy(m.checkID, address(m.rr)) {
+			down = append(down, m.checkID)
+			continue
+		}
+		kept = append(kept, m)
+	}
+	if len(kept) > 0 || len(down) == 0 {
+		return kept
+	}
+
+	// Every member is down
+	hdr := *members[0].rr.Header()
+	seen := make(map[int64]struct{})
+	for _, checkID := range down {
+		if _, ok := seen[checkID]; ok {
+			continue
+		}
+		seen[checkID] = struct{}{}
+		for _, ip := range hs.checker.Fallback(checkID) {
+			if rr := addressRR(hdr, ip); rr != nil {
+				kept = append(kept, member{rr: rr, weight: 1})
+			}
+		}
+	}
+	if len(kept) == 0 {
+		return members
+	}
+	return kept
+}
+
+// address returns the IP of an A or AAAA record
+func address(rr dns.RR) net.IP {
+	switch v := rr.(type) {
+	case *dns.A:
+		return v.A
+	case *dns.AAAA:
+		return v.AAAA
+	}
+	return nil
+}
+
+// addressRR builds a record of hdr's type for ip, nil when the family does not match
+func addressRR(hdr dns.RR_Header, ip net.IP) dns.RR {
+	v4 := ip.To4()
+	switch {
+	case hdr.Rrtype == dns.TypeA && v4 != nil:
+		return &dns.A{Hdr: hdr, A: v4}
+	case hdr.Rrtype == dns.TypeAAAA && v4 == nil:
+		return &dns.AAAA{Hdr: hdr, AAAA: ip}
+	}
+	return nil
+}
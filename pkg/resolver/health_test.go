@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+type fakeHealth struct {
+	down      map[string]bool
+	fallbacks map[int64][]net.IP
+}
+
+func (f *fakeHealth) Healthy(checkID int64, ip net.IP) bool { return !f.down[ip.String()] }
+
+func (f *fakeHealth) Fallback(checkID int64) []net.IP { return f.fallbacks[checkID] }
+
+func TestResolver_Resolve_Health(t *testing.T) {
+	mockRepo := &MockDNSQueryRepository{
+		QueryARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			switch recordName {
+			case "www.test.com.":
+				return []*model.ARecord{
+					{IP: 0x0a000001, HealthCheckID: 1, TTL: 60},
+					{IP: 0x0a000002, HealthCheckID: 1, TTL: 60},
+				}, nil
+			case "nofallback.test.com.":
+				return []*model.ARecord{
+					{IP: 0x0a000001, HealthCheckID: 2, TTL: 60},
+					{IP: 0x0a000002, HealthCheckID: 2, TTL: 60},
+				}, nil
+			}
+			return nil, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+
+	health := &fakeHealth{
+		down: map[string]bool{},
+		fallbacks: map[int64][]net.IP{
+			1: {net.ParseIP("2001:db8::1"), net.ParseIP("192.0.2.1")},
+		},
+	}
+	r.SetHealth(health)
+
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+	resolve := func(name string) []string {
+		req := new(dns.Msg)
+		req.SetQuestion(name, dns.TypeA)
+		msg, err := r.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.NoError(t, err)
+		var ips []string
+		for _, rr := range msg.Answer {
+			ips = append(ips, rr.(*dns.A).A.String())
+			assert.Equal(t, uint32(60), rr.Header().Ttl)
+		}
+		return ips
+	}
+
+	t.Run("All healthy", func(t *testing.T) {
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, resolve("www.test.com."))
+	})
+
+	t.Run("Unhealthy members are left out", func(t *testing.T) {
+		health.down["10.0.0.1"] = true
+		assert.Equal(t, []string{"10.0.0.2"}, resolve("www.test.com."))
+	})
+
+	t.Run("Fallback of the matching family when all are down", func(t *testing.T) {
+		health.down["10.0.0.2"] = true
+		assert.Equal(t, []string{"192.0.2.1"}, resolve("www.test.com."))
+	})
+
+	t.Run("Full set without fallback", func(t *testing.T) {
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, resolve("nofallback.test.com."))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		r.SetHealth(nil)
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, resolve("www.test.com."))
+	})
+}
@@ -0,0 +1,68 @@
+package resolver
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// earthRadiusKm is the mean Earth radius used for great-circle distances
+const earthRadiusKm = 6371.0
+
+// LocationProvider reports the coordinates of an IP address.
+// GeoIP providers implementing it enable the nearest policy.
+type LocationProvider interface {
+	Location(ip string) (latitude, longitude float64, err error)
+}
+
+// clientLocation returns the coordinates of the querying client
+func (r *Resolver) clientLocation(ctx context.Context) (float64, float64, bool) {
+	locator, ok := r.geoip.(LocationProvider)
+	if !ok {
+		return 0, 0, false
+	}
+	ip, ok := clientIP(ctx)
+	if !ok {
+		return 0, 0, false
+	}
+	lat, lon, err := locator.Location(ip)
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+// nearestOrder returns the member indexes ordered by distance to the given
+// point, members without coordinates last. It returns nil when no member
+// has coordinates.
+func nearestOrder(members []member, lat, lon float64) []int {
+	distances := make([]float64, len(members))
+	order := make([]int, len(members))
+	located := false
+	for i, m := range members {
+		order[i] = i
+		if m.latitude == nil || m.longitude == nil {
+			distances[i] = math.Inf(1)
+			continue
+		}
+		distances[i] = distance(lat, lon, *m.latitude, *m.longitude)
+		located = true
+	}
+	if !located {
+		return nil
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return distances[order[a]] < distances[order[b]]
+	})
+	return order
+}
+
+// distance returns the great-circle distance in kilometres (haversine formula)
+func distance(lat1, lon1, lat2, lon2 float64) float64 {
+	const rad = math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,137 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/dao/memory"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// fakeLocator places clients by IP
+type fakeLocator struct {
+	locations map[string][2]float64
+}
+
+func (f *fakeLocator) Lookup(ip string) (GeoInfo, error) {
+	return GeoInfo{}, errors.New("not implemented")
+}
+
+func (f *fakeLocator) Location(ip string) (float64, float64, error) {
+	loc, ok := f.locations[ip]
+	if !ok {
+		return 0, 0, errors.New("no location")
+	}
+	return loc[0], loc[1], nil
+}
+
+func coord(v float64) *float64 { return &v }
+
+func TestResolver_Resolve_Nearest(t *testing.T) {
+	mockRepo := &MockDNSQueryRepository{
+		QueryARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			records := []*model.ARecord{
+				{IP: 0x0a000001, TTL: 60, Latitude: coord(40.71), Longitude: coord(-74.00)}, // New York
+				{IP: 0x0a000002, TTL: 60, Latitude: coord(50.11), Longitude: coord(8.68)},   // Frankfurt
+				{IP: 0x0a000003, TTL: 60}, // No location
+				{IP: 0x0a000004, TTL: 60, Latitude: coord(35.68), Longitude: coord(139.69)}, // Tokyo
+			}
+			if recordName == "unlocated.test.com." {
+				return records[2:3:3], nil
+			}
+			return records, nil
+		},
+		QueryRRSetPolicyFn: func(ctx context.Context, zoneName, recordName string, qType uint16, viewID int64) (*model.RRSetPolicy, error) {
+			return &model.RRSetPolicy{Policy: model.PolicyNearest, Count: 2}, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+	r.geoip = &fakeLocator{locations: map[string][2]float64{
+		"192.0.2.1": {48.86, 2.35},   // Paris
+		"192.0.2.2": {37.57, 126.98}, // Seoul
+	}}
+
+	resolve := func(client, name string) []string {
+		req := new(dns.Msg)
+		req.SetQuestion(name, dns.TypeA)
+		w := &MockResponseWriter{RemoteIP: net.ParseIP(client)}
+		msg, err := r.Resolve(context.Background(), request.Request{W: w, Req: req})
+		assert.NoError(t, err)
+		var ips []string
+		for _, rr := range msg.Answer {
+			ips = append(ips, rr.(*dns.A).A.String())
+		}
+		return ips
+	}
+
+	t.Run("Closest first, limited to count", func(t *testing.T) {
+		assert.Equal(t, []string{"10.0.0.2", "10.0.0.1"}, resolve("192.0.2.1", "www.test.com."))
+		assert.Equal(t, []string{"10.0.0.4", "10.0.0.2"}, resolve("192.0.2.2", "www.test.com."))
+	})
+
+	t.Run("Unknown client location falls back to all records", func(t *testing.T) {
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}, resolve("198.51.100.1", "www.test.com."))
+	})
+
+	t.Run("Client subnets sharing a view and the cache", func(t *testing.T) {
+		var reads int
+		counting := *mockRepo
+		counting.QueryARecordsFn = func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			reads++
+			return mockRepo.QueryARecordsFn(ctx, zoneName, recordName, viewID)
+		}
+		cached := newTestResolver(t, rdb.NewCachedDNSQueryRepository(&counting, memory.NewCacheDAO(0)), "test.com.")
+		cached.geoip = &fakeLocator{locations: map[string][2]float64{
+			"203.0.113.0":   {48.86, 2.35},   // Paris
+			"203.0.113.128": {37.57, 126.98}, // Seoul
+		}}
+		cached.SetECS(nil)
+
+		resolveSubnet := func(subnet string) []string {
+			req := new(dns.Msg)
+			req.SetQuestion("www.test.com.", dns.TypeA)
+			req.SetEdns0(4096, false)
+			req.IsEdns0().Option = append(req.IsEdns0().Option, &dns.EDNS0_SUBNET{
+				Code: dns.EDNS0SUBNET, Family: 1, SourceNetmask: 25, Address: net.ParseIP(subnet).To4(),
+			})
+			w := &MockResponseWriter{RemoteIP: net.ParseIP("192.0.2.53")}
+			msg, err := cached.Resolve(context.Background(), request.Request{W: w, Req: req})
+			assert.NoError(t, err)
+			var ips []string
+			for _, rr := range msg.Answer {
+				ips = append(ips, rr.(*dns.A).A.String())
+			}
+			return ips
+		}
+
+		assert.Equal(t, []string{"10.0.0.2", "10.0.0.1"}, resolveSubnet("203.0.113.0"))
+		assert.Equal(t, []string{"10.0.0.4", "10.0.0.2"}, resolveSubnet("203.0.113.128"))
+		assert.Equal(t, []string{"10.0.0.2", "10.0.0.1"}, resolveSubnet("203.0.113.0"))
+		// The cache holds the full RRset once, the nearest records are
+		// picked per subnet after reading it
+		assert.Equal(t, 1, reads)
+	})
+}
+
+func TestNearestOrder(t *testing.T) {
+	members := []member{
+		{},
+		{latitude: coord(0), longitude: coord(10)},
+		{latitude: coord(0), longitude: coord(1)},
+	}
+	assert.Equal(t, []int{2, 1, 0}, nearestOrder(members, 0, 0))
+	assert.Nil(t, nearestOrder(members[:1], 0, 0))
+}
+
+func TestDistance(t *testing.T) {
+	// Paris to London is about 344 km
+	assert.InDelta(t, 344, distance(48.8566, 2.3522, 51.5074, -0.1278), 5)
+	assert.Zero(t, distance(10, 20, 10, 20))
+}
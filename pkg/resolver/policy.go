@@ -0,0 +1,149 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/miekg/dns"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// member is an A/AAAA record with the attributes used to select answers
+type member struct {
+	rr        dns.RR
+	weight    uint32
+	checkID   int64
+	latitude  *float64
+	longitude *float64
+}
+
+// rotationSlots is the number of round-robin counters, RRsets whose keys
+// hash to the same slot share one
+const rotationSlots = 1024
+
+// policyKey marks in the context whether lookups answer the question itself
+type policyKey struct{}
+
+// withPolicy sets whether RRset policies apply to lookups made with ctx.
+// Only the answer section is subject to them, glue, additional records and
+// ALIAS targets always get the full set.
+func withPolicy(ctx context.Context, apply bool) context.Context {
+	return context.WithValue(ctx, policyKey{}, apply)
+}
+
+// selectRRs applies the RRset policy configured for name to the records
+// read through the cache, so the cache always keeps the full set
+func (r *Resolver) selectRRs(ctx context.Context, zone, name string, qType uint16, viewID int64, members []member) ([]dns.RR, error) {
+	if apply, _ := ctx.Value(policyKey{}).(bool); !apply || len(members) < 2 {
+		return rrsOf(members, nil), nil
+	}
+	policy, err := r.dao.QueryRRSetPolicy(ctx, zone, name, qType, viewID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return rrsOf(members, nil), nil
+	}
+
+	var order []int
+	switch policy.Policy {
+	case model.PolicyRoundRobin:
+		h := fnv.New32a()
+		fmt.Fprintf(h, "%s|%d|%d", name, qType, viewID)
+		counter := &r.rotation[h.Sum32()%rotationSlots]
+		order = rotate(len(members), counter.Add(1)-1)
+	case model.PolicyWeighted:
+		order = weightedOrder(weightsOf(members))
+	case model.PolicyFixed:
+		order = fixedOrder(weightsOf(members))
+	case model.PolicyNearest:
+		lat, lon, ok := r.clientLocation(ctx)
+		if !ok {
+			// Unknown client location falls back to all records
+			return rrsOf(members, nil), nil
+		}
+		if order = nearestOrder(members, lat, lon); order == nil {
+			return rrsOf(members, nil), nil
+		}
+	default:
+		return rrsOf(members, nil), nil
+	}
+
+	if policy.Count > 0 && policy.Count < len(order) {
+		order = order[:policy.Count]
+	}
+	return rrsOf(members, order), nil
+}
+
+// rrsOf returns the records of members in the given order, all of them when order is nil
+func rrsOf(members []member, order []int) []dns.RR {
+	if order == nil {
+		rrs := make([]dns.RR, 0, len(members))
+		for _, m := range members {
+			rrs = append(rrs, m.rr)
+		}
+		return rrs
+	}
+	rrs := make([]dns.RR, 0, len(order))
+	for _, i := range order {
+		rrs = append(rrs, members[i].rr)
+	}
+	return rrs
+}
+
+func weightsOf(members []member) []uint32 {
+	weights := make([]uint32, len(members))
+	for i, m := range members {
+		weights[i] = m.weight
+	}
+	return weights
+}
+
+// rotate returns the indexes 0..n-1 shifted left by step
+func rotate(n int, step uint64) []int {
+	order := make([]int, n)
+	start := int(step % uint64(n))
+	for i := range order {
+		order[i] = (start + i) % n
+	}
+	return order
+}
+
+// weightedOrder returns a random permutation in which records with a higher
+// weight tend to come first (weighted sampling without replacement).
+// Zero weight records are only used once all weighted ones are exhausted.
+func weightedOrder(weights []uint32) []int {
+	keys := make([]float64, len(weights))
+	order := make([]int, len(weights))
+	for i, w := range weights {
+		order[i] = i
+		if w == 0 {
+			keys[i] = -rand.Float64()
+			continue
+		}
+		// Efraimidis-Spirakis: u^(1/w), largest keys win
+		keys[i] = math.Pow(rand.Float64(), 1/float64(w))
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return keys[order[a]] > keys[order[b]]
+	})
+	return order
+}
+
+// fixedOrder returns the indexes ordered by weight descending, records of
+// equal weight keep their database order
+func fixedOrder(weights []uint32) []int {
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return weights[order[a]] > weights[order[b]]
+	})
+	return order
+}
@@ -0,0 +1,126 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestResolver_Resolve_RRSetPolicy(t *testing.T) {
+	policies := map[string]*model.RRSetPolicy{
+		"rr.test.com.":       {Policy: model.PolicyRoundRobin},
+		"weighted.test.com.": {Policy: model.PolicyWeighted, Count: 1},
+		"fixed.test.com.":    {Policy: model.PolicyFixed, Count: 2},
+	}
+	mockRepo := &MockDNSQueryRepository{
+		QueryARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			if recordName == "cname.test.com." || recordName == "alias.test.com." {
+				return nil, nil
+			}
+			return []*model.ARecord{
+				{IP: 0x0a000001, Weight: 1, TTL: 60},
+				{IP: 0x0a000002, Weight: 9, TTL: 60},
+				{IP: 0x0a000003, Weight: 0, TTL: 60},
+			}, nil
+		},
+		QueryRRSetPolicyFn: func(ctx context.Context, zoneName, recordName string, qType uint16, viewID int64) (*model.RRSetPolicy, error) {
+			assert.Equal(t, dns.TypeA, qType)
+			return policies[recordName], nil
+		},
+		QueryCNAMERecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CNAMERecord, error) {
+			if recordName == "cname.test.com." {
+				return []*model.CNAMERecord{{Target: "fixed.test.com.", TTL: 60}}, nil
+			}
+			return nil, nil
+		},
+		QueryALIASRecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ALIASRecord, error) {
+			if recordName == "alias.test.com." {
+				return []*model.ALIASRecord{{Target: "fixed.test.com.", TTL: 60}}, nil
+			}
+			return nil, nil
+		},
+		QueryMXRecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.MXRecord, error) {
+			return []*model.MXRecord{{Host: "fixed.test.com.", Priority: 10, TTL: 60}}, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+	query := func(name string, qType uint16) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion(name, qType)
+		msg, err := r.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.NoError(t, err)
+		return msg
+	}
+	addresses := func(rrs []dns.RR) []string {
+		var ips []string
+		for _, rr := range rrs {
+			if a, ok := rr.(*dns.A); ok {
+				ips = append(ips, a.A.String())
+			}
+		}
+		return ips
+	}
+	resolve := func(name string) []string {
+		return addresses(query(name, dns.TypeA).Answer)
+	}
+
+	t.Run("No policy returns all in database order", func(t *testing.T) {
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, resolve("all.test.com."))
+	})
+
+	t.Run("Round robin", func(t *testing.T) {
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, resolve("rr.test.com."))
+		assert.Equal(t, []string{"10.0.0.2", "10.0.0.3", "10.0.0.1"}, resolve("rr.test.com."))
+		assert.Equal(t, []string{"10.0.0.3", "10.0.0.1", "10.0.0.2"}, resolve("rr.test.com."))
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, resolve("rr.test.com."))
+	})
+
+	t.Run("Weighted subset", func(t *testing.T) {
+		counts := make(map[string]int)
+		for i := 0; i < 1000; i++ {
+			ips := resolve("weighted.test.com.")
+			if assert.Len(t, ips, 1) {
+				counts[ips[0]]++
+			}
+		}
+		// Zero weight records are never picked while others are available
+		assert.Zero(t, counts["10.0.0.3"])
+		assert.Greater(t, counts["10.0.0.2"], 800)
+		assert.Greater(t, counts["10.0.0.1"], 30)
+	})
+
+	t.Run("Fixed order by weight", func(t *testing.T) {
+		assert.Equal(t, []string{"10.0.0.2", "10.0.0.1"}, resolve("fixed.test.com."))
+	})
+
+	t.Run("CNAME target in the answer", func(t *testing.T) {
+		msg := query("cname.test.com.", dns.TypeA)
+		if assert.Len(t, msg.Answer, 3) {
+			assert.IsType(t, &dns.CNAME{}, msg.Answer[0])
+		}
+		assert.Equal(t, []string{"10.0.0.2", "10.0.0.1"}, addresses(msg.Answer))
+	})
+
+	t.Run("ALIAS targets are not trimmed", func(t *testing.T) {
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, resolve("alias.test.com."))
+	})
+
+	t.Run("Additional records are not trimmed", func(t *testing.T) {
+		msg := query("mail.test.com.", dns.TypeMX)
+		assert.Len(t, msg.Answer, 1)
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, addresses(msg.Extra))
+	})
+}
+
+func TestWeightedOrder_AllZero(t *testing.T) {
+	order := weightedOrder([]uint32{0, 0, 0})
+	assert.ElementsMatch(t, []int{0, 1, 2}, order)
+}
@@ -3,250 +3,622 @@ package resolver
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"net"
 	"net/netip"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/coredns/coredns/request"
 	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/logger"
 	"github.com/cylonchau/hermes/pkg/model"
 	"github.com/miekg/dns"
 	"gorm.io/gorm"
 )
 
+// GeoInfo is what a GeoIP provider knows about an address, unknown fields
+// are left empty
+type GeoInfo struct {
+	Continent    string // continent code, e.g. EU
+	Country      string // ISO 3166-1 country code, e.g. CN
+	Region       string // subdivision code, e.g. GD
+	ASN          uint32 // autonomous system number
+	Organization string // autonomous system organization
+}
+
 // GeoIPProvider defines the interface for GeoIP lookups
 type GeoIPProvider interface {
-	Lookup(ip string) (country, region string, err error)
+	Lookup(ip string) (GeoInfo, error)
 }
 
+var (
+	// ErrNotAuthoritative is returned when the query name is not inside any served zone
+	ErrNotAuthoritative = errors.New("not authoritative")
+	// ErrNotReady is returned when the database has not been initialized yet
+	ErrNotReady = errors.New("database not initialized")
+)
+
 // Resolver is the core DNS resolving processor
 type Resolver struct {
-	dao   rdb.DNSQueryRepository
-	db    *gorm.DB
-	geoip GeoIPProvider
+	dao    rdb.DNSQueryRepository
+	db     *gorm.DB
+	geoip  GeoIPProvider
+	zones  *ZoneIndex
+	cuts   *ZoneIndex
+	dnames *ZoneIndex
+	ptrs   *ZoneIndex
+	views  *ViewMatcher
+
+	upstream atomic.Pointer[upstreamState]
+	health   atomic.Pointer[healthState]
+	ecs      atomic.Pointer[ecsState]
+	rotation [rotationSlots]atomic.Uint64 // round-robin counters by RRset key hash
 }
 
 // NewResolver creates a resolver instance
 func NewResolver(dao rdb.DNSQueryRepository, db *gorm.DB, geoip GeoIPProvider) *Resolver {
-	return &Resolver{dao: dao, db: db, geoip: geoip}
+	r := &Resolver{dao: dao, db: db, geoip: geoip}
+	r.zones = NewZoneIndex(r.loadZones)
+	r.cuts = NewZoneIndex(r.loadDelegations)
+	r.dnames = NewZoneIndex(r.loadDNAMEs)
+	r.ptrs = NewZoneIndex(r.loadAutoPTRNetworks)
+	r.views = NewViewMatcher(r.loadViews)
+	return r
+}
+
+// Zones returns the zone suffix index used by the resolver
+func (r *Resolver) Zones() *ZoneIndex {
+	return r.zones
+}
+
+// Delegations returns the index of zone cuts below served zone apexes
+func (r *Resolver) Delegations() *ZoneIndex {
+	return r.cuts
+}
+
+// DNAMEs returns the index of DNAME owner names
+func (r *Resolver) DNAMEs() *ZoneIndex {
+	return r.dnames
+}
+
+// AutoPTRs returns the index of reverse networks holding forward records of
+// zones with automatic PTR records
+func (r *Resolver) AutoPTRs() *ZoneIndex {
+	return r.ptrs
+}
+
+// Views returns the compiled view matcher
+func (r *Resolver) Views() *ViewMatcher {
+	return r.views
+}
+
+// Refresh reloads the zone, delegation, DNAME and automatic PTR indexes and
+// the view matcher
+func (r *Resolver) Refresh(ctx context.Context) error {
+	if err := r.zones.Refresh(ctx); err != nil {
+		return err
+	}
+	if err := r.cuts.Refresh(ctx); err != nil {
+		return err
+	}
+	if err := r.dnames.Refresh(ctx); err != nil {
+		return err
+	}
+	if err := r.ptrs.Refresh(ctx); err != nil {
+		return err
+	}
+	return r.views.Refresh(ctx)
+}
+
+// Run periodically refreshes the zone, delegation, DNAME and automatic PTR
+// indexes and the view matcher until ctx is cancelled
+func (r *Resolver) Run(ctx context.Context, interval time.Duration) {
+	go r.cuts.Run(ctx, interval)
+	go r.dnames.Run(ctx, interval)
+	go r.ptrs.Run(ctx, interval)
+	go r.views.Run(ctx, interval)
+	r.zones.Run(ctx, interval)
 }
 
 // Resolve handles DNS resolution logic
 func (r *Resolver) Resolve(ctx context.Context, state request.Request) (*dns.Msg, error) {
+	ecs, useECS, err := r.clientSubnet(state)
+	if err != nil {
+		// Names of other plugins are left to them whatever their options
+		if _, _, zoneErr := r.parseQuery(ctx, state.Name()); zoneErr != nil {
+			return nil, zoneErr
+		}
+		m := new(dns.Msg)
+		m.SetRcode(state.Req, dns.RcodeFormatError)
+		return m, nil
+	}
+
+	client := state.IP()
+	var subnet netip.Prefix
+	if useECS {
+		client = subnetAddress(ecs)
+		subnet = subnetPrefix(ecs)
+	}
+	m, err := r.resolve(ctx, state, client, subnet)
+
+	// The answer is tailored to the whole source prefix when the subnet was
+	// used, and valid for everyone (scope 0) when it was ignored
+	if m != nil && ecs != nil {
+		var scope uint8
+		if useECS {
+			scope = ecs.SourceNetmask
+		}
+		echoSubnet(state, m, ecs, scope)
+	}
+	return m, err
+}
+
+// resolve answers the query for the given client address and subnet
+func (r *Resolver) resolve(ctx context.Context, state request.Request, clientIP string, subnet netip.Prefix) (*dns.Msg, error) {
 	qName := state.Name()
 	qType := state.QType()
-	clientIP := state.IP()
+	ctx = context.WithValue(ctx, requestKey{}, state)
+	ctx = context.WithValue(ctx, clientKey{}, clientIP)
 
-	// 1. Identify view
-	viewID, _ := r.matchView(ctx, clientIP)
+	m := new(dns.Msg)
+	m.SetReply(state.Req)
+	m.Authoritative = true
 
-	// 2. Find most matching Zone
+	// 1. Find most matching Zone
 	zone, name, err := r.parseQuery(ctx, qName)
 	if err != nil {
+		// Reverse names outside configured reverse zones may be generated
+		// from forward records, the index keeps other names off the database
+		if errors.Is(err, ErrNotAuthoritative) && qType == dns.TypePTR {
+			if _, ok := r.ptrs.Match(ctx, qName); ok {
+				// Views that cannot be loaded leave the name to the next plugin
+				viewID, viewErr := r.matchView(ctx, state, clientIP, subnet)
+				if viewErr != nil {
+					return nil, err
+				}
+				answer, ptrErr := r.autoPTR(ctx, dns.Fqdn(qName), viewID)
+				if ptrErr != nil {
+					return r.serverFailure(state, "", viewID, ptrErr), nil
+				}
+				if len(answer) > 0 {
+					// No served zone holds the name
+					m.Authoritative = false
+					m.Answer = answer
+					return m, nil
+				}
+			}
+		}
 		return nil, err
 	}
 
-	m := new(dns.Msg)
-	m.SetReply(state.Req)
-	m.Authoritative = true
+	// 2. Identify view. Answering from the default view when views cannot
+	// be loaded could expose records meant for other clients, so such
+	// queries fail.
+	viewID, err := r.matchView(ctx, state, clientIP, subnet)
+	if err != nil {
+		return r.serverFailure(state, zone, 0, err), nil
+	}
+
+	// 3. Names at or below a zone cut are referred to the child zone
+	ns, err := r.delegation(ctx, zone, name, qType, viewID)
+	if err != nil {
+		return r.serverFailure(state, zone, viewID, err), nil
+	}
+	if len(ns) > 0 {
+		res, err := r.referral(ctx, ns, viewID, m)
+		if err != nil {
+			return r.serverFailure(state, zone, viewID, err), nil
+		}
+		trimAdditional(state, res)
+		return res, nil
+	}
+
+	// 4. Retrieve records based on query type
+	answer, err := r.answer(withPolicy(ctx, true), zone, name, qType, viewID)
+	if errors.Is(err, errDNAMEOverflow) {
+		m.Answer = answer
+		m.Rcode = dns.RcodeYXDomain
+		return m, nil
+	}
+	var delegated *referralError
+	if errors.As(err, &delegated) {
+		m.Answer = answer
+		res, err := r.referral(ctx, delegated.ns, viewID, m)
+		if err != nil {
+			return r.serverFailure(state, zone, viewID, err), nil
+		}
+		trimAdditional(state, res)
+		return res, nil
+	}
+	if err != nil {
+		return r.serverFailure(state, zone, viewID, err), nil
+	}
+	m.Answer = append(m.Answer, answer...)
+
+	// 5. If record is not found or is NXDOMAIN state
+	if len(m.Answer) == 0 {
+		res, err := r.handleNoData(ctx, zone, name, viewID, m)
+		if err != nil && !errors.Is(err, ErrNotAuthoritative) {
+			return r.serverFailure(state, zone, viewID, err), nil
+		}
+		return res, err
+	}
+
+	// 6. CNAME chain ending inside a served zone without data is a negative answer for the target
+	if last, ok := m.Answer[len(m.Answer)-1].(*dns.CNAME); ok && qType != dns.TypeCNAME {
+		if targetZone, ok := r.zones.Match(ctx, last.Target); ok {
+			_, err := r.handleNoData(ctx, targetZone, strings.ToLower(last.Target), viewID, m)
+			if err != nil && !errors.Is(err, ErrNotAuthoritative) {
+				return r.serverFailure(state, targetZone, viewID, err), nil
+			}
+		}
+	}
+
+	// 7. Addresses of MX, NS and SRV targets go to the Additional section
+	if err := r.additional(ctx, viewID, m); err != nil {
+		return r.serverFailure(state, zone, viewID, err), nil
+	}
+	trimAdditional(state, m)
+
+	return m, nil
+}
+
+// lookup retrieves the RRset of qType owned by name in the given zone and view
+func (r *Resolver) lookup(ctx context.Context, zone, name string, qType uint16, viewID int64) ([]dns.RR, error) {
+	var rrs []dns.RR
 
-	// 3. Retrieve records based on query type
 	switch qType {
 	case dns.TypeA:
 		records, err := r.dao.QueryARecords(ctx, zone, name, viewID)
-		if err == nil {
-			for _, rec := range records {
-				ip := make(net.IP, 4)
-				binary.BigEndian.PutUint32(ip, uint32(rec.IP))
-				m.Answer = append(m.Answer, &dns.A{
-					Hdr: dns.RR_Header{Name: qName, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: rec.TTL},
+		if err != nil {
+			return nil, err
+		}
+		members := make([]member, 0, len(records))
+		for _, rec := range records {
+			ip := make(net.IP, 4)
+			binary.BigEndian.PutUint32(ip, rec.IP)
+			members = append(members, member{
+				rr: &dns.A{
+					Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: rec.TTL},
 					A:   ip,
-				})
-			}
+				},
+				weight:    rec.Weight,
+				checkID:   rec.HealthCheckID,
+				latitude:  rec.Latitude,
+				longitude: rec.Longitude,
+			})
 		}
+		return r.selectRRs(ctx, zone, name, qType, viewID, r.healthy(members))
 	case dns.TypeAAAA:
 		records, err := r.dao.QueryAAAARecords(ctx, zone, name, viewID)
-		if err == nil {
-			for _, rec := range records {
-				m.Answer = append(m.Answer, &dns.AAAA{
-					Hdr:  dns.RR_Header{Name: qName, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: rec.TTL},
+		if err != nil {
+			return nil, err
+		}
+		members := make([]member, 0, len(records))
+		for _, rec := range records {
+			members = append(members, member{
+				rr: &dns.AAAA{
+					Hdr:  dns.RR_Header{Name: name, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: rec.TTL},
 					AAAA: net.IP(rec.IP),
-				})
-			}
+				},
+				weight:    rec.Weight,
+				checkID:   rec.HealthCheckID,
+				latitude:  rec.Latitude,
+				longitude: rec.Longitude,
+			})
 		}
+		return r.selectRRs(ctx, zone, name, qType, viewID, r.healthy(members))
 	case dns.TypeCNAME:
 		records, err := r.dao.QueryCNAMERecords(ctx, zone, name, viewID)
-		if err == nil {
-			for _, rec := range records {
-				m.Answer = append(m.Answer, &dns.CNAME{
-					Hdr:    dns.RR_Header{Name: qName, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: rec.TTL},
-					Target: dns.Fqdn(rec.Target),
-				})
-			}
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			rrs = append(rrs, &dns.CNAME{
+				Hdr:    dns.RR_Header{Name: name, Rrtype: dns.TypeCNAME, Class: dns.ClassINET, Ttl: rec.TTL},
+				Target: dns.Fqdn(rec.Target),
+			})
+		}
+	case dns.TypeDNAME:
+		records, err := r.dao.QueryDNAMERecords(ctx, zone, name, viewID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			rrs = append(rrs, &dns.DNAME{
+				Hdr:    dns.RR_Header{Name: name, Rrtype: dns.TypeDNAME, Class: dns.ClassINET, Ttl: rec.TTL},
+				Target: dns.Fqdn(rec.Target),
+			})
 		}
 	case dns.TypeMX:
 		records, err := r.dao.QueryMXRecords(ctx, zone, name, viewID)
-		if err == nil {
-			for _, rec := range records {
-				m.Answer = append(m.Answer, &dns.MX{
-					Hdr:        dns.RR_Header{Name: qName, Rrtype: dns.TypeMX, Class: dns.ClassINET, Ttl: rec.TTL},
-					Preference: uint16(rec.Priority),
-					Mx:         dns.Fqdn(rec.Host),
-				})
-			}
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			rrs = append(rrs, &dns.MX{
+				Hdr:        dns.RR_Header{Name: name, Rrtype: dns.TypeMX, Class: dns.ClassINET, Ttl: rec.TTL},
+				Preference: rec.Priority,
+				Mx:         dns.Fqdn(rec.Host),
+			})
 		}
 	case dns.TypeTXT:
 		records, err := r.dao.QueryTXTRecords(ctx, zone, name, viewID)
-		if err == nil {
-			for _, rec := range records {
-				m.Answer = append(m.Answer, &dns.TXT{
-					Hdr: dns.RR_Header{Name: qName, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: rec.TTL},
-					Txt: []string{rec.Text},
-				})
-			}
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			rrs = append(rrs, &dns.TXT{
+				Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: rec.TTL},
+				Txt: []string{rec.Text},
+			})
 		}
 	case dns.TypeNS:
 		records, err := r.dao.QueryNSRecords(ctx, zone, name, viewID)
-		if err == nil {
-			for _, rec := range records {
-				m.Answer = append(m.Answer, &dns.NS{
-					Hdr: dns.RR_Header{Name: qName, Rrtype: dns.TypeNS, Class: dns.ClassINET, Ttl: rec.TTL},
-					Ns:  dns.Fqdn(rec.NameServer),
-				})
-			}
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			rrs = append(rrs, &dns.NS{
+				Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeNS, Class: dns.ClassINET, Ttl: rec.TTL},
+				Ns:  dns.Fqdn(rec.NameServer),
+			})
 		}
 	case dns.TypeSOA:
+		// SOA only exists at the zone apex
+		if name != zone {
+			return nil, nil
+		}
 		rec, err := r.dao.QuerySOARecord(ctx, zone, viewID)
-		if err == nil && rec != nil {
-			m.Answer = append(m.Answer, &dns.SOA{
-				Hdr:     dns.RR_Header{Name: dns.Fqdn(zone), Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: rec.TTL},
-				Ns:      dns.Fqdn(rec.PrimaryNS),
-				Mbox:    dns.Fqdn(rec.MBox),
-				Serial:  rec.Serial,
-				Refresh: rec.Refresh,
-				Retry:   rec.Retry,
-				Expire:  rec.Expire,
-				Minttl:  rec.MinTTL,
-			})
+		if err != nil {
+			return nil, err
+		}
+		if rec != nil {
+			rrs = append(rrs, soaRR(zone, rec))
 		}
 	case dns.TypeSRV:
 		records, err := r.dao.QuerySRVRecords(ctx, zone, name, viewID)
-		if err == nil {
-			for _, rec := range records {
-				m.Answer = append(m.Answer, &dns.SRV{
-					Hdr:      dns.RR_Header{Name: qName, Rrtype: dns.TypeSRV, Class: dns.ClassINET, Ttl: rec.TTL},
-					Priority: uint16(rec.Priority),
-					Weight:   uint16(rec.Weight),
-					Port:     uint16(rec.Port),
-					Target:   dns.Fqdn(rec.Target),
-				})
-			}
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			rrs = append(rrs, &dns.SRV{
+				Hdr:      dns.RR_Header{Name: name, Rrtype: dns.TypeSRV, Class: dns.ClassINET, Ttl: rec.TTL},
+				Priority: rec.Priority,
+				Weight:   rec.Weight,
+				Port:     rec.Port,
+				Target:   dns.Fqdn(rec.Target),
+			})
+		}
+	case dns.TypePTR:
+		records, err := r.dao.QueryPTRRecords(ctx, zone, name, viewID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			rrs = append(rrs, &dns.PTR{
+				Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypePTR, Class: dns.ClassINET, Ttl: rec.TTL},
+				Ptr: dns.Fqdn(rec.PTRDName),
+			})
+		}
+		// Explicit PTRs take precedence over generated ones
+		if len(rrs) == 0 {
+			return r.autoPTR(ctx, name, viewID)
+		}
+	case dns.TypeCAA:
+		records, err := r.dao.QueryCAARecords(ctx, zone, name, viewID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			rrs = append(rrs, &dns.CAA{
+				Hdr:   dns.RR_Header{Name: name, Rrtype: dns.TypeCAA, Class: dns.ClassINET, Ttl: rec.TTL},
+				Flag:  rec.Flag,
+				Tag:   rec.Tag,
+				Value: rec.Value,
+			})
+		}
+	case dns.TypeSVCB:
+		records, err := r.dao.QuerySVCBRecords(ctx, zone, name, viewID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			rrs = append(rrs, svcbRR(name, dns.TypeSVCB, rec.TTL, rec.Priority, rec.Target, rec.SvcParams))
+		}
+	case dns.TypeHTTPS:
+		records, err := r.dao.QueryHTTPSRecords(ctx, zone, name, viewID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			rrs = append(rrs, &dns.HTTPS{SVCB: *svcbRR(name, dns.TypeHTTPS, rec.TTL, rec.Priority, rec.Target, rec.SvcParams)})
+		}
+	case dns.TypeTLSA:
+		records, err := r.dao.QueryTLSARecords(ctx, zone, name, viewID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			rrs = append(rrs, &dns.TLSA{
+				Hdr:          dns.RR_Header{Name: name, Rrtype: dns.TypeTLSA, Class: dns.ClassINET, Ttl: rec.TTL},
+				Usage:        rec.Usage,
+				Selector:     rec.Selector,
+				MatchingType: rec.MatchingType,
+				Certificate:  strings.ToLower(rec.Certificate),
+			})
+		}
+	case dns.TypeSSHFP:
+		records, err := r.dao.QuerySSHFPRecords(ctx, zone, name, viewID)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			rrs = append(rrs, &dns.SSHFP{
+				Hdr:         dns.RR_Header{Name: name, Rrtype: dns.TypeSSHFP, Class: dns.ClassINET, Ttl: rec.TTL},
+				Algorithm:   rec.Algorithm,
+				Type:        rec.Type,
+				FingerPrint: strings.ToLower(rec.FingerPrint),
+			})
 		}
+	default:
+		return r.generic(ctx, zone, name, qType, viewID)
 	}
 
-	// 4. If record is not found or is NXDOMAIN state
-	if len(m.Answer) == 0 {
-		return r.handleNoData(ctx, zone, viewID, m)
-	}
+	return rrs, nil
+}
 
-	return m, nil
+// soaRR converts a SOA model into a dns.SOA owned by the zone apex
+func soaRR(zone string, rec *model.SOARecord) *dns.SOA {
+	return &dns.SOA{
+		Hdr:     dns.RR_Header{Name: dns.Fqdn(zone), Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: rec.TTL},
+		Ns:      dns.Fqdn(rec.PrimaryNS),
+		Mbox:    dns.Fqdn(rec.MBox),
+		Serial:  rec.Serial,
+		Refresh: rec.Refresh,
+		Retry:   rec.Retry,
+		Expire:  rec.Expire,
+		Minttl:  rec.MinTTL,
+	}
 }
 
-// parseQuery parses query domain name, splitting into Zone and Record Name
+// parseQuery parses query domain name, splitting into Zone and Record Name.
+// The zone is the longest active zone that is a suffix of qName.
 func (r *Resolver) parseQuery(ctx context.Context, qName string) (zone, name string, err error) {
-	// Currently uses a simple dot split approach
-	labels := dns.SplitDomainName(qName)
-	if len(labels) < 2 {
-		return "", "", fmt.Errorf("invalid domain name: %s", qName)
+	zone, ok := r.zones.Match(ctx, qName)
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrNotAuthoritative, qName)
 	}
 
 	// Use FQDN format (with trailing dot) to match database storage spec
-	zone = strings.Join(labels[len(labels)-2:], ".") + "."
-	name = qName
+	return zone, dns.Fqdn(qName), nil
+}
+
+// loadZones loads names of all active zones from the database
+func (r *Resolver) loadZones(ctx context.Context) ([]string, error) {
+	db := r.db
+	if db == nil {
+		db = model.DB
+	}
+	if db == nil {
+		return nil, ErrNotReady
+	}
 
-	return zone, name, nil
+	zones, err := rdb.NewZoneDAO(db).GetActiveZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(zones))
+	for _, z := range zones {
+		names = append(names, z.Name)
+	}
+	return names, nil
 }
 
-// handleNoData handles NO DATA states appending SOA to Authority section
-func (r *Resolver) handleNoData(ctx context.Context, zone string, viewID int64, m *dns.Msg) (*dns.Msg, error) {
+// handleNoData builds a negative answer (RFC 2308): NXDOMAIN when name does not
+// exist in the zone and view, NODATA otherwise. The zone SOA is appended to the
+// Authority section with its TTL capped by the SOA minimum.
+func (r *Resolver) handleNoData(ctx context.Context, zone, name string, viewID int64, m *dns.Msg) (*dns.Msg, error) {
 	rec, err := r.dao.QuerySOARecord(ctx, zone, viewID)
-	if err == nil && rec != nil && rec.ID > 0 {
-		m.Ns = append(m.Ns, &dns.SOA{
-			Hdr:     dns.RR_Header{Name: dns.Fqdn(zone), Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: rec.TTL},
-			Ns:      dns.Fqdn(rec.PrimaryNS),
-			Mbox:    dns.Fqdn(rec.MBox),
-			Serial:  rec.Serial,
-			Refresh: rec.Refresh,
-			Retry:   rec.Retry,
-			Expire:  rec.Expire,
-			Minttl:  rec.MinTTL,
-		})
-	} else {
-		// Explicitly mark: completely absent within server authority
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		// Zone without SOA is not served authoritatively, let the next plugin handle it
+		return nil, fmt.Errorf("%w: zone %s has no SOA", ErrNotAuthoritative, zone)
+	}
+
+	exists, err := r.nameExists(ctx, zone, name, viewID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
 		m.Rcode = dns.RcodeNameError
 	}
+
+	soa := soaRR(zone, rec)
+	soa.Hdr.Ttl = min(rec.TTL, rec.MinTTL)
+	m.Ns = append(m.Ns, soa)
 	return m, nil
 }
 
+// nameExists reports whether name exists in the zone, either as an owner name,
+// an empty non-terminal, or through a covering wildcard
+func (r *Resolver) nameExists(ctx context.Context, zone, name string, viewID int64) (bool, error) {
+	if strings.EqualFold(name, zone) {
+		return true, nil
+	}
 
-
-// matchView matches View based on client IP
-func (r *Resolver) matchView(ctx context.Context, clientIP string) (int64, error) {
-	// 1. Fetch all views and sort by priority
-	// Note: cache support should be added later to avoid full-scans
-	var views []model.View
-	db := r.db
-	if db == nil {
-		db = model.DB
+	exists, err := r.dao.NameExists(ctx, zone, name, viewID)
+	if err != nil || exists {
+		return exists, err
 	}
-	if db == nil {
-		return 0, fmt.Errorf("database not initialized")
+
+	source, err := r.wildcardSource(ctx, zone, name, viewID)
+	if err != nil || source == "" {
+		return false, err
 	}
-	err := db.WithContext(ctx).Order("priority DESC").Find(&views).Error
-	if err != nil {
-		return 0, err
+	return r.dao.NameExists(ctx, zone, source, viewID)
+}
+
+// serverFailure builds a SERVFAIL response carrying an RFC 8914 Extended DNS Error
+func (r *Resolver) serverFailure(state request.Request, zone string, viewID int64, err error) *dns.Msg {
+	logger.Error("DNS query failed",
+		logger.String("zone", zone),
+		logger.String("qname", state.Name()),
+		logger.String("qtype", dns.TypeToString[state.QType()]),
+		logger.Int64("view", viewID),
+		logger.Err(err),
+	)
+
+	m := new(dns.Msg)
+	m.SetRcode(state.Req, dns.RcodeServerFailure)
+
+	// EDE travels in the OPT record, only add it when the client speaks EDNS0
+	if opt := state.Req.IsEdns0(); opt != nil {
+		m.SetEdns0(opt.UDPSize(), opt.Do())
+		m.IsEdns0().Option = append(m.IsEdns0().Option, &dns.EDNS0_EDE{
+			InfoCode:  extendedErrorCode(err),
+			ExtraText: "hermes: backend unavailable",
+		})
 	}
+	return m
+}
 
-	ip, err := netip.ParseAddr(clientIP)
-	if err != nil {
-		return 0, err
-	}
-
-	// 2. Traverse views for match
-	for _, v := range views {
-		switch v.Category {
-		case "acl":
-			// Handle CIDR lists (supports comma separated)
-			cidrs := strings.Split(v.Value, ",")
-			for _, cidrStr := range cidrs {
-				cidrStr = strings.TrimSpace(cidrStr)
-				if cidrStr == "" {
-					continue
-				}
-				prefix, err := netip.ParsePrefix(cidrStr)
-				if err == nil {
-					if prefix.Contains(ip) {
-						return v.ID, nil
-					}
-				}
-			}
-		case "geoip":
-			if r.geoip == nil {
-				continue
-			}
-			country, region, err := r.geoip.Lookup(clientIP)
-			if err != nil {
-				continue
-			}
+// extendedErrorCode maps a backend error to an Extended DNS Error info code
+func extendedErrorCode(err error) uint16 {
+	if errors.Is(err, ErrNotReady) {
+		return dns.ExtendedErrorCodeNotReady
+	}
+	return dns.ExtendedErrorCodeNetworkError
+}
 
-			// Supports country code (e.g., CN) or city/region code (e.g., CN-GD)
-			// Note: Value definitions must align with GeoIP datasource
-			if v.Value != "" && (v.Value == country || v.Value == country+"-"+region || v.Value == region) {
-				return v.ID, nil
-			}
-		}
+// matchView matches View based on the client address, client subnet,
+// transport and listener address of the query
+func (r *Resolver) matchView(ctx context.Context, state request.Request, clientIP string, subnet netip.Prefix) (int64, error) {
+	client := ViewClient{Subnet: subnet, Transport: state.Proto()}
+	if addr, err := netip.ParseAddr(clientIP); err == nil {
+		client.Addr = addr
+	}
+	if addr, err := netip.ParseAddr(state.LocalIP()); err == nil {
+		client.Listener = addr.Unmap()
 	}
+	return r.views.Match(ctx, client, r.geoip)
+}
 
-	return 0, nil
+// loadViews returns all views in match order
+func (r *Resolver) loadViews(ctx context.Context) ([]model.View, error) {
+	db := r.db
+	if db == nil {
+		db = model.DB
+	}
+	if db == nil {
+		return nil, ErrNotReady
+	}
+	var views []model.View
+	err := db.WithContext(ctx).Preload("Rules").Order("priority DESC, id ASC").Find(&views).Error
+	return views, err
 }
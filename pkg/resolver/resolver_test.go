@@ -2,6 +2,7 @@ package resolver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"regexp"
@@ -15,6 +16,7 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
 	"github.com/cylonchau/hermes/pkg/model"
 )
 
@@ -38,28 +40,55 @@ func setupMockDB() (*gorm.DB, sqlmock.Sqlmock, error) {
 	return gormDB, mock, nil
 }
 
+// newTestResolver returns a resolver over dao serving zones, with empty
+// delegation, view, DNAME and automatic PTR indexes so nothing is loaded
+// from the database
+func newTestResolver(t *testing.T, dao rdb.DNSQueryRepository, zones ...string) *Resolver {
+	db, _, err := setupMockDB()
+	assert.NoError(t, err)
+	r := NewResolver(dao, db, nil)
+	r.Zones().Set(zones)
+	r.Delegations().Set(nil)
+	r.Views().Set(nil)
+	r.DNAMEs().Set(nil)
+	r.AutoPTRs().Set(nil)
+	return r
+}
+
 // MockGeoIPProvider is a mock implementation of GeoIPProvider
 type MockGeoIPProvider struct {
-	LookupFn func(ip string) (string, string, error)
+	LookupFn func(ip string) (GeoInfo, error)
 }
 
-func (m *MockGeoIPProvider) Lookup(ip string) (string, string, error) {
+func (m *MockGeoIPProvider) Lookup(ip string) (GeoInfo, error) {
 	if m.LookupFn != nil {
 		return m.LookupFn(ip)
 	}
-	return "", "", nil
+	return GeoInfo{}, nil
 }
 
 // MockDNSQueryRepository is a mock implementation of DNSQueryRepository
 type MockDNSQueryRepository struct {
-	QueryARecordsFn     func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error)
-	QueryAAAARecordsFn  func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.AAAARecord, error)
-	QueryMXRecordsFn    func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.MXRecord, error)
-	QueryTXTRecordsFn   func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.TXTRecord, error)
-	QuerySOARecordFn    func(ctx context.Context, zoneName string, viewID int64) (*model.SOARecord, error)
-	QueryNSRecordsFn    func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.NSRecord, error)
-	QueryCNAMERecordsFn func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CNAMERecord, error)
-	QuerySRVRecordsFn   func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SRVRecord, error)
+	QueryARecordsFn       func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error)
+	QueryAAAARecordsFn    func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.AAAARecord, error)
+	QueryMXRecordsFn      func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.MXRecord, error)
+	QueryTXTRecordsFn     func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.TXTRecord, error)
+	QuerySOARecordFn      func(ctx context.Context, zoneName string, viewID int64) (*model.SOARecord, error)
+	QueryNSRecordsFn      func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.NSRecord, error)
+	QueryCNAMERecordsFn   func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CNAMERecord, error)
+	QueryDNAMERecordsFn   func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.DNAMERecord, error)
+	QueryALIASRecordsFn   func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ALIASRecord, error)
+	QuerySRVRecordsFn     func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SRVRecord, error)
+	QueryCAARecordsFn     func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CAARecord, error)
+	QueryPTRRecordsFn     func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.PTRRecord, error)
+	QuerySVCBRecordsFn    func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SVCBRecord, error)
+	QueryHTTPSRecordsFn   func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.HTTPSRecord, error)
+	QueryTLSARecordsFn    func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.TLSARecord, error)
+	QuerySSHFPRecordsFn   func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SSHFPRecord, error)
+	QueryGenericRecordsFn func(ctx context.Context, zoneName, recordName string, rrType uint16, viewID int64) ([]*model.GenericRecord, error)
+	QueryAutoPTRRecordsFn func(ctx context.Context, ip net.IP, viewID int64) ([]*model.PTRRecord, error)
+	QueryRRSetPolicyFn    func(ctx context.Context, zoneName, recordName string, qType uint16, viewID int64) (*model.RRSetPolicy, error)
+	NameExistsFn          func(ctx context.Context, zoneName, recordName string, viewID int64) (bool, error)
 }
 
 func (m *MockDNSQueryRepository) QueryARecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
@@ -104,28 +133,115 @@ func (m *MockDNSQueryRepository) QueryCNAMERecords(ctx context.Context, zoneName
 	}
 	return nil, nil
 }
+func (m *MockDNSQueryRepository) QueryDNAMERecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.DNAMERecord, error) {
+	if m.QueryDNAMERecordsFn != nil {
+		return m.QueryDNAMERecordsFn(ctx, zoneName, recordName, viewID)
+	}
+	return nil, nil
+}
+func (m *MockDNSQueryRepository) QueryALIASRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ALIASRecord, error) {
+	if m.QueryALIASRecordsFn != nil {
+		return m.QueryALIASRecordsFn(ctx, zoneName, recordName, viewID)
+	}
+	return nil, nil
+}
 func (m *MockDNSQueryRepository) QuerySRVRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SRVRecord, error) {
 	if m.QuerySRVRecordsFn != nil {
 		return m.QuerySRVRecordsFn(ctx, zoneName, recordName, viewID)
 	}
 	return nil, nil
 }
+func (m *MockDNSQueryRepository) QueryCAARecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CAARecord, error) {
+	if m.QueryCAARecordsFn != nil {
+		return m.QueryCAARecordsFn(ctx, zoneName, recordName, viewID)
+	}
+	return nil, nil
+}
+func (m *MockDNSQueryRepository) QueryPTRRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.PTRRecord, error) {
+	if m.QueryPTRRecordsFn != nil {
+		return m.QueryPTRRecordsFn(ctx, zoneName, recordName, viewID)
+	}
+	return nil, nil
+}
+func (m *MockDNSQueryRepository) QuerySVCBRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SVCBRecord, error) {
+	if m.QuerySVCBRecordsFn != nil {
+		return m.QuerySVCBRecordsFn(ctx, zoneName, recordName, viewID)
+	}
+	return nil, nil
+}
+func (m *MockDNSQueryRepository) QueryHTTPSRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.HTTPSRecord, error) {
+	if m.QueryHTTPSRecordsFn != nil {
+		return m.QueryHTTPSRecordsFn(ctx, zoneName, recordName, viewID)
+	}
+	return nil, nil
+}
+func (m *MockDNSQueryRepository) QueryTLSARecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.TLSARecord, error) {
+	if m.QueryTLSARecordsFn != nil {
+		return m.QueryTLSARecordsFn(ctx, zoneName, recordName, viewID)
+	}
+	return nil, nil
+}
+func (m *MockDNSQueryRepository) QuerySSHFPRecords(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SSHFPRecord, error) {
+	if m.QuerySSHFPRecordsFn != nil {
+		return m.QuerySSHFPRecordsFn(ctx, zoneName, recordName, viewID)
+	}
+	return nil, nil
+}
+func (m *MockDNSQueryRepository) QueryGenericRecords(ctx context.Context, zoneName, recordName string, rrType uint16, viewID int64) ([]*model.GenericRecord, error) {
+	if m.QueryGenericRecordsFn != nil {
+		return m.QueryGenericRecordsFn(ctx, zoneName, recordName, rrType, viewID)
+	}
+	return nil, nil
+}
+func (m *MockDNSQueryRepository) QueryAutoPTRRecords(ctx context.Context, ip net.IP, viewID int64) ([]*model.PTRRecord, error) {
+	if m.QueryAutoPTRRecordsFn != nil {
+		return m.QueryAutoPTRRecordsFn(ctx, ip, viewID)
+	}
+	return nil, nil
+}
+func (m *MockDNSQueryRepository) QueryRRSetPolicy(ctx context.Context, zoneName, recordName string, qType uint16, viewID int64) (*model.RRSetPolicy, error) {
+	if m.QueryRRSetPolicyFn != nil {
+		return m.QueryRRSetPolicyFn(ctx, zoneName, recordName, qType, viewID)
+	}
+	return nil, nil
+}
+func (m *MockDNSQueryRepository) NameExists(ctx context.Context, zoneName, recordName string, viewID int64) (bool, error) {
+	if m.NameExistsFn != nil {
+		return m.NameExistsFn(ctx, zoneName, recordName, viewID)
+	}
+	return false, nil
+}
 
 // MockResponseWriter is a mock implementation of dns.ResponseWriter
 type MockResponseWriter struct {
 	dns.ResponseWriter
 	RemoteIP net.IP
+	LocalIP  net.IP
+	TCP      bool
 }
 
 func (m *MockResponseWriter) RemoteAddr() net.Addr {
+	if m.TCP {
+		return &net.TCPAddr{IP: m.RemoteIP, Port: 53}
+	}
 	return &net.UDPAddr{IP: m.RemoteIP, Port: 53}
 }
+
+func (m *MockResponseWriter) LocalAddr() net.Addr {
+	ip := m.LocalIP
+	if ip == nil {
+		ip = net.IPv4(127, 0, 0, 1)
+	}
+	if m.TCP {
+		return &net.TCPAddr{IP: ip, Port: 53}
+	}
+	return &net.UDPAddr{IP: ip, Port: 53}
+}
 func (m *MockResponseWriter) WriteMsg(msg *dns.Msg) error { return nil }
 
 func TestResolver_Resolve(t *testing.T) {
 	mockRepo := &MockDNSQueryRepository{}
-	db, _, _ := setupMockDB() // We need a GORM DB for matchView
-	r := NewResolver(mockRepo, db, nil)
+	r := newTestResolver(t, mockRepo, "test.com.")
 
 	ctx := context.Background()
 	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
@@ -188,6 +304,9 @@ func TestResolver_Resolve(t *testing.T) {
 		if len(msg.Answer) != 0 {
 			t.Errorf("expected 0 answer, got %d", len(msg.Answer))
 		}
+		if msg.Rcode != dns.RcodeNameError {
+			t.Errorf("expected NXDOMAIN, got %s", dns.RcodeToString[msg.Rcode])
+		}
 		if len(msg.Ns) != 1 {
 			t.Errorf("expected 1 record in authority section, got %d", len(msg.Ns))
 		}
@@ -195,19 +314,26 @@ func TestResolver_Resolve(t *testing.T) {
 
 	t.Run("GeoIP match", func(t *testing.T) {
 		mockGeoIP := &MockGeoIPProvider{
-			LookupFn: func(ip string) (string, string, error) {
-				return "CN", "GD", nil
+			LookupFn: func(ip string) (GeoInfo, error) {
+				return GeoInfo{Country: "CN", Region: "GD"}, nil
 			},
 		}
 		// Create a resolver with mock DB that has a GeoIP view
 		gormDB, sqlMock, _ := setupMockDB()
 		rGeo := NewResolver(mockRepo, gormDB, mockGeoIP)
+		rGeo.Zones().Set([]string{"test.com."})
+		rGeo.Delegations().Set(nil)
+		rGeo.DNAMEs().Set(nil)
 
 		// Mock View lookup
 		viewRows := sqlmock.NewRows([]string{"id", "name", "category", "value", "priority"}).
 			AddRow(int64(10), "Guangdong", "geoip", "CN-GD", 10)
-		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view` ORDER BY priority DESC")).
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view` ORDER BY priority DESC, id ASC")).
 			WillReturnRows(viewRows)
+		// Not migrated yet, the legacy category and value still apply
+		sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view_rule` WHERE `view_rule`.`view_id` = ?")).
+			WithArgs(int64(10)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "view_id", "type", "value", "negate"}))
 
 		mockRepo.QueryARecordsFn = func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
 			if viewID == 10 {
@@ -223,6 +349,289 @@ func TestResolver_Resolve(t *testing.T) {
 		msg, err := rGeo.Resolve(ctx, state)
 		assert.NoError(t, err)
 		assert.NotNil(t, msg)
+		assert.Len(t, msg.Answer, 1)
 		assert.NoError(t, sqlMock.ExpectationsWereMet())
 	})
+	t.Run("Resolve CAA Record", func(t *testing.T) {
+		mockRepo.QueryCAARecordsFn = func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CAARecord, error) {
+			if zoneName == "test.com." && recordName == "test.com." {
+				return []*model.CAARecord{{Flag: 0, Tag: "issue", Value: "letsencrypt.org", TTL: 3600}}, nil
+			}
+			return nil, nil
+		}
+
+		req := new(dns.Msg)
+		req.SetQuestion("test.com.", dns.TypeCAA)
+		state := request.Request{W: mockW, Req: req}
+
+		msg, err := r.Resolve(ctx, state)
+		assert.NoError(t, err)
+		if assert.Len(t, msg.Answer, 1) {
+			caa, ok := msg.Answer[0].(*dns.CAA)
+			assert.True(t, ok)
+			assert.Equal(t, "issue", caa.Tag)
+			assert.Equal(t, "letsencrypt.org", caa.Value)
+		}
+	})
+
+	t.Run("Resolve TLSA Record", func(t *testing.T) {
+		mockRepo.QueryTLSARecordsFn = func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.TLSARecord, error) {
+			if recordName == "_25._tcp.mail.test.com." {
+				return []*model.TLSARecord{{Usage: 3, Selector: 1, MatchingType: 1, Certificate: "2FA298CACF0FE340542AD61A491B776CD8EF56429130A13538A5E8CE07E70C33", TTL: 3600}}, nil
+			}
+			return nil, nil
+		}
+
+		req := new(dns.Msg)
+		req.SetQuestion("_25._tcp.mail.test.com.", dns.TypeTLSA)
+		state := request.Request{W: mockW, Req: req}
+
+		msg, err := r.Resolve(ctx, state)
+		assert.NoError(t, err)
+		if assert.Len(t, msg.Answer, 1) {
+			tlsa, ok := msg.Answer[0].(*dns.TLSA)
+			assert.True(t, ok)
+			assert.Equal(t, uint8(3), tlsa.Usage)
+			assert.Equal(t, "2fa298cacf0fe340542ad61a491b776cd8ef56429130a13538a5e8ce07e70c33", tlsa.Certificate)
+		}
+	})
+
+	t.Run("Resolve SSHFP Record", func(t *testing.T) {
+		mockRepo.QuerySSHFPRecordsFn = func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.SSHFPRecord, error) {
+			if recordName == "host.test.com." {
+				return []*model.SSHFPRecord{
+					{Algorithm: 4, Type: 1, FingerPrint: "daaf1f2ee7ae020db8193c91c6e2ce928ac6317e", TTL: 3600},
+					{Algorithm: 4, Type: 2, FingerPrint: "244236f4073086345acd8effecad97e50c80559de958608c1b985850f4cc97d4", TTL: 3600},
+				}, nil
+			}
+			return nil, nil
+		}
+
+		req := new(dns.Msg)
+		req.SetQuestion("host.test.com.", dns.TypeSSHFP)
+		state := request.Request{W: mockW, Req: req}
+
+		msg, err := r.Resolve(ctx, state)
+		assert.NoError(t, err)
+		if assert.Len(t, msg.Answer, 2) {
+			sshfp, ok := msg.Answer[1].(*dns.SSHFP)
+			assert.True(t, ok)
+			assert.Equal(t, uint8(4), sshfp.Algorithm)
+			assert.Equal(t, uint8(2), sshfp.Type)
+		}
+	})
+
+	t.Run("Zone without SOA is not authoritative", func(t *testing.T) {
+		mockRepo.QueryARecordsFn = nil
+		mockRepo.QuerySOARecordFn = nil
+
+		req := new(dns.Msg)
+		req.SetQuestion("missing.test.com.", dns.TypeA)
+		state := request.Request{W: mockW, Req: req}
+
+		msg, err := r.Resolve(ctx, state)
+		assert.ErrorIs(t, err, ErrNotAuthoritative)
+		assert.Nil(t, msg)
+	})
+
+	t.Run("Not authoritative", func(t *testing.T) {
+		req := new(dns.Msg)
+		req.SetQuestion("www.example.org.", dns.TypeA)
+		state := request.Request{W: mockW, Req: req}
+
+		msg, err := r.Resolve(ctx, state)
+		assert.ErrorIs(t, err, ErrNotAuthoritative)
+		assert.Nil(t, msg)
+	})
+}
+
+func TestResolver_Resolve_DatabaseFailure(t *testing.T) {
+	db, mock, err := setupMockDB()
+	assert.NoError(t, err)
+	r := NewResolver(rdb.NewRecordDAO(db), db, nil)
+	r.Zones().Set([]string{"test.com."})
+	r.Delegations().Set(nil)
+	r.DNAMEs().Set(nil)
+
+	ctx := context.Background()
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+
+	t.Run("Foreign names do not load views", func(t *testing.T) {
+		req := new(dns.Msg)
+		req.SetQuestion("www.example.org.", dns.TypeA)
+		msg, err := r.Resolve(ctx, request.Request{W: mockW, Req: req})
+		assert.ErrorIs(t, err, ErrNotAuthoritative)
+		assert.Nil(t, msg)
+		assert.Nil(t, r.Views().compiled.Load())
+		assert.Zero(t, r.Views().failedAt.Load())
+	})
+
+	t.Run("View load failure", func(t *testing.T) {
+		// Answering from the default view instead could leak records
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `view` ORDER BY priority DESC, id ASC")).
+			WillReturnError(errors.New("connection refused"))
+
+		req := new(dns.Msg)
+		req.SetQuestion("www.test.com.", dns.TypeA)
+		req.SetEdns0(dns.DefaultMsgSize, false)
+		state := request.Request{W: mockW, Req: req}
+
+		msg, err := r.Resolve(ctx, state)
+		assert.NoError(t, err)
+		assert.Equal(t, dns.RcodeServerFailure, msg.Rcode)
+		assert.Empty(t, msg.Answer)
+
+		opt := msg.IsEdns0()
+		if assert.NotNil(t, opt) && assert.Len(t, opt.Option, 1) {
+			ede, ok := opt.Option[0].(*dns.EDNS0_EDE)
+			assert.True(t, ok)
+			assert.Equal(t, dns.ExtendedErrorCodeNotReady, ede.InfoCode)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		// Names of other plugins still fall through
+		req = new(dns.Msg)
+		req.SetQuestion("www.example.org.", dns.TypeA)
+		msg, err = r.Resolve(ctx, request.Request{W: mockW, Req: req})
+		assert.ErrorIs(t, err, ErrNotAuthoritative)
+		assert.Nil(t, msg)
+
+		// Later queries fail without waiting for the database again
+		req = new(dns.Msg)
+		req.SetQuestion("www.test.com.", dns.TypeA)
+		msg, err = r.Resolve(ctx, request.Request{W: mockW, Req: req})
+		assert.NoError(t, err)
+		assert.Equal(t, dns.RcodeServerFailure, msg.Rcode)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	r.Views().Set(nil)
+
+	t.Run("SERVFAIL with EDE", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_a`.*, `record`.ttl, ")).
+			WillReturnError(errors.New("connection refused"))
+
+		req := new(dns.Msg)
+		req.SetQuestion("www.test.com.", dns.TypeA)
+		req.SetEdns0(dns.DefaultMsgSize, false)
+		state := request.Request{W: mockW, Req: req}
+
+		msg, err := r.Resolve(ctx, state)
+		assert.NoError(t, err)
+		assert.Equal(t, dns.RcodeServerFailure, msg.Rcode)
+		assert.Empty(t, msg.Answer)
+
+		opt := msg.IsEdns0()
+		if assert.NotNil(t, opt) && assert.Len(t, opt.Option, 1) {
+			ede, ok := opt.Option[0].(*dns.EDNS0_EDE)
+			assert.True(t, ok)
+			assert.Equal(t, dns.ExtendedErrorCodeNetworkError, ede.InfoCode)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("SERVFAIL without EDNS0", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_a`.*, `record`.ttl, ")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "record_id", "ip", "ttl"}))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_alias`.*, `record`.ttl FROM `record_alias`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "record_id", "target", "ttl"}))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `record_cname`.*, `record`.ttl FROM `record_cname`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "record_id", "target", "ttl"}))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `record`")).
+			WillReturnError(errors.New("connection refused"))
+
+		req := new(dns.Msg)
+		req.SetQuestion("missing.test.com.", dns.TypeA)
+		state := request.Request{W: mockW, Req: req}
+
+		msg, err := r.Resolve(ctx, state)
+		assert.NoError(t, err)
+		assert.Equal(t, dns.RcodeServerFailure, msg.Rcode)
+		assert.Nil(t, msg.IsEdns0())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestExtendedErrorCode(t *testing.T) {
+	assert.Equal(t, dns.ExtendedErrorCodeNotReady, extendedErrorCode(ErrNotReady))
+	assert.Equal(t, dns.ExtendedErrorCodeNotReady, extendedErrorCode(fmt.Errorf("wrap: %w", ErrNotReady)))
+	assert.Equal(t, dns.ExtendedErrorCodeNetworkError, extendedErrorCode(errors.New("i/o timeout")))
+}
+
+func TestResolver_Resolve_Negative(t *testing.T) {
+	names := []string{"test.com.", "host.test.com.", "a.ent.test.com.", "alias.test.com.", "dangling.test.com."}
+	mockRepo := &MockDNSQueryRepository{
+		NameExistsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) (bool, error) {
+			for _, n := range names {
+				if dns.IsSubDomain(recordName, n) {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+		QueryARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			if recordName == "host.test.com." {
+				return []*model.ARecord{{IP: 0x01020304, TTL: 600}}, nil
+			}
+			return nil, nil
+		},
+		QueryCNAMERecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CNAMERecord, error) {
+			switch recordName {
+			case "alias.test.com.":
+				return []*model.CNAMERecord{{Target: "host.test.com.", TTL: 600}}, nil
+			case "dangling.test.com.":
+				return []*model.CNAMERecord{{Target: "gone.test.com.", TTL: 600}}, nil
+			}
+			return nil, nil
+		},
+		QuerySOARecordFn: func(ctx context.Context, zoneName string, viewID int64) (*model.SOARecord, error) {
+			return &model.SOARecord{PrimaryNS: "ns1.test.com.", MBox: "admin.test.com.", TTL: 3600, MinTTL: 300}, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+	resolve := func(qName string, qType uint16) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion(qName, qType)
+		msg, err := r.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.NoError(t, err)
+		return msg
+	}
+
+	tests := []struct {
+		name    string
+		qName   string
+		qType   uint16
+		rcode   int
+		answers int
+	}{
+		{"NXDOMAIN", "missing.test.com.", dns.TypeA, dns.RcodeNameError, 0},
+		{"NODATA for other type", "host.test.com.", dns.TypeAAAA, dns.RcodeSuccess, 0},
+		{"NODATA for empty non-terminal", "ent.test.com.", dns.TypeA, dns.RcodeSuccess, 0},
+		{"NODATA at apex", "test.com.", dns.TypeA, dns.RcodeSuccess, 0},
+		{"CNAME to NODATA", "alias.test.com.", dns.TypeMX, dns.RcodeSuccess, 1},
+		{"CNAME to NXDOMAIN", "dangling.test.com.", dns.TypeA, dns.RcodeNameError, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := resolve(tt.qName, tt.qType)
+			assert.Equal(t, tt.rcode, msg.Rcode)
+			assert.Len(t, msg.Answer, tt.answers)
+			if assert.Len(t, msg.Ns, 1) {
+				soa, ok := msg.Ns[0].(*dns.SOA)
+				assert.True(t, ok)
+				// Negative TTL is the SOA minimum
+				assert.Equal(t, uint32(300), soa.Hdr.Ttl)
+			}
+		})
+	}
+
+	t.Run("Positive answer has no SOA", func(t *testing.T) {
+		msg := resolve("alias.test.com.", dns.TypeA)
+		assert.Equal(t, dns.RcodeSuccess, msg.Rcode)
+		assert.Len(t, msg.Answer, 2)
+		assert.Empty(t, msg.Ns)
+	})
 }
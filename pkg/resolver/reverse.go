@@ -0,0 +1,48 @@
+package resolver
+
+import (
+	"context"
+	"net"
+
+	"github.com/coredns/coredns/plugin/pkg/dnsutil"
+	"github.com/miekg/dns"
+
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// autoPTR generates PTR records for a reverse name from A/AAAA records of
+// zones that have automatic reverse records enabled
+func (r *Resolver) autoPTR(ctx context.Context, name string, viewID int64) ([]dns.RR, error) {
+	ip := net.ParseIP(dnsutil.ExtractAddressFromReverse(name))
+	if ip == nil {
+		return nil, nil
+	}
+
+	records, err := r.dao.QueryAutoPTRRecords(ctx, ip, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rrs []dns.RR
+	for _, rec := range records {
+		rrs = append(rrs, &dns.PTR{
+			Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypePTR, Class: dns.ClassINET, Ttl: rec.TTL},
+			Ptr: dns.Fqdn(rec.PTRDName),
+		})
+	}
+	return rrs, nil
+}
+
+// loadAutoPTRNetworks loads the reverse names of networks holding A/AAAA
+// records of zones with automatic PTR records
+func (r *Resolver) loadAutoPTRNetworks(ctx context.Context) ([]string, error) {
+	db := r.db
+	if db == nil {
+		db = model.DB
+	}
+	if db == nil {
+		return nil, ErrNotReady
+	}
+	return rdb.NewRecordDAO(db).GetAutoPTRNetworks(ctx)
+}
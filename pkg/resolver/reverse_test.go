@@ -0,0 +1,100 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestResolver_Resolve_PTR(t *testing.T) {
+	var autoQueries int
+	mockRepo := &MockDNSQueryRepository{
+		QueryPTRRecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.PTRRecord, error) {
+			if recordName == "1.0.0.10.in-addr.arpa." {
+				return []*model.PTRRecord{{PTRDName: "gw.test.com", TTL: 600}}, nil
+			}
+			return nil, nil
+		},
+		QueryAutoPTRRecordsFn: func(ctx context.Context, ip net.IP, viewID int64) ([]*model.PTRRecord, error) {
+			autoQueries++
+			if ip.Equal(net.ParseIP("192.0.2.10")) || ip.Equal(net.ParseIP("10.0.0.2")) {
+				return []*model.PTRRecord{{PTRDName: "www.test.com.", TTL: 300}}, nil
+			}
+			return nil, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.", "0.0.10.in-addr.arpa.")
+	r.AutoPTRs().Set([]string{"0.0.10.in-addr.arpa.", "2.0.192.in-addr.arpa."})
+
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+	resolve := func(name string) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion(name, dns.TypePTR)
+		msg, err := r.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.NoError(t, err)
+		return msg
+	}
+
+	t.Run("Explicit PTR in a reverse zone", func(t *testing.T) {
+		msg := resolve("1.0.0.10.in-addr.arpa.")
+		if assert.Len(t, msg.Answer, 1) {
+			assert.Equal(t, "gw.test.com.", msg.Answer[0].(*dns.PTR).Ptr)
+		}
+		assert.True(t, msg.Authoritative)
+	})
+
+	t.Run("Generated PTR inside a reverse zone", func(t *testing.T) {
+		msg := resolve("2.0.0.10.in-addr.arpa.")
+		if assert.Len(t, msg.Answer, 1) {
+			assert.Equal(t, "www.test.com.", msg.Answer[0].(*dns.PTR).Ptr)
+		}
+		assert.True(t, msg.Authoritative)
+	})
+
+	t.Run("Generated PTR without a reverse zone", func(t *testing.T) {
+		msg := resolve("10.2.0.192.in-addr.arpa.")
+		if assert.Len(t, msg.Answer, 1) {
+			assert.Equal(t, "www.test.com.", msg.Answer[0].(*dns.PTR).Ptr)
+			assert.Equal(t, uint32(300), msg.Answer[0].Header().Ttl)
+		}
+		assert.False(t, msg.Authoritative)
+	})
+
+	t.Run("Unknown address is not authoritative", func(t *testing.T) {
+		req := new(dns.Msg)
+		req.SetQuestion("11.2.0.192.in-addr.arpa.", dns.TypePTR)
+		_, err := r.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.ErrorIs(t, err, ErrNotAuthoritative)
+	})
+
+	t.Run("Networks without forward records skip the database", func(t *testing.T) {
+		autoQueries = 0
+		req := new(dns.Msg)
+		req.SetQuestion("10.113.0.203.in-addr.arpa.", dns.TypePTR)
+		_, err := r.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.ErrorIs(t, err, ErrNotAuthoritative)
+		assert.Zero(t, autoQueries)
+	})
+
+	t.Run("Views not loaded fall through", func(t *testing.T) {
+		autoQueries = 0
+		unready := newTestResolver(t, mockRepo, "test.com.")
+		unready.AutoPTRs().Set([]string{"2.0.192.in-addr.arpa."})
+		unready.views = NewViewMatcher(func(ctx context.Context) ([]model.View, error) {
+			return nil, errors.New("connection refused")
+		})
+		req := new(dns.Msg)
+		req.SetQuestion("10.2.0.192.in-addr.arpa.", dns.TypePTR)
+		msg, err := unready.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.ErrorIs(t, err, ErrNotAuthoritative)
+		assert.Nil(t, msg)
+		assert.Zero(t, autoQueries)
+	})
+}
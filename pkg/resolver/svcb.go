@@ -0,0 +1,85 @@
+package resolver
+
+import (
+	"encoding/base64"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// svcbRR builds a SVCB RR from stored service parameters. Parameters are
+// validated when written through the API, values that fail to parse are left out.
+func svcbRR(name string, rrtype uint16, ttl uint32, priority uint16, target string, params model.SvcParams) *dns.SVCB {
+	rr := &dns.SVCB{
+		Hdr:      dns.RR_Header{Name: name, Rrtype: rrtype, Class: dns.ClassINET, Ttl: ttl},
+		Priority: priority,
+		Target:   dns.Fqdn(target),
+	}
+	// AliasMode carries no parameters
+	if priority == 0 {
+		return rr
+	}
+
+	// Keys must appear in ascending order on the wire
+	if alpn := splitList(params.ALPN); len(alpn) > 0 {
+		rr.Value = append(rr.Value, &dns.SVCBAlpn{Alpn: alpn})
+	}
+	if params.NoDefaultALPN {
+		rr.Value = append(rr.Value, &dns.SVCBNoDefaultAlpn{})
+	}
+	if params.Port > 0 {
+		rr.Value = append(rr.Value, &dns.SVCBPort{Port: params.Port})
+	}
+	if hint := parseHints(params.IPv4Hint, true); len(hint) > 0 {
+		rr.Value = append(rr.Value, &dns.SVCBIPv4Hint{Hint: hint})
+	}
+	if params.ECH != "" {
+		if ech, err := base64.StdEncoding.DecodeString(params.ECH); err == nil {
+			rr.Value = append(rr.Value, &dns.SVCBECHConfig{ECH: ech})
+		}
+	}
+	if hint := parseHints(params.IPv6Hint, false); len(hint) > 0 {
+		rr.Value = append(rr.Value, &dns.SVCBIPv6Hint{Hint: hint})
+	}
+	return rr
+}
+
+// svcbTarget returns the name whose addresses serve a SVCB record, or "."
+// when there is none
+func svcbTarget(rr *dns.SVCB) string {
+	if rr.Target != "." {
+		return rr.Target
+	}
+	// In ServiceMode "." stands for the owner name itself
+	if rr.Priority > 0 {
+		return rr.Hdr.Name
+	}
+	return "."
+}
+
+// splitList splits a comma separated column value
+func splitList(s string) []string {
+	var res []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// parseHints parses a comma separated list of addresses of one family
+func parseHints(s string, v4 bool) []net.IP {
+	var res []net.IP
+	for _, v := range splitList(s) {
+		ip := net.ParseIP(v)
+		if ip == nil || (ip.To4() != nil) != v4 {
+			continue
+		}
+		res = append(res, ip)
+	}
+	return res
+}
@@ -0,0 +1,83 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestResolver_Resolve_HTTPS(t *testing.T) {
+	mockRepo := &MockDNSQueryRepository{
+		QueryHTTPSRecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.HTTPSRecord, error) {
+			switch recordName {
+			case "test.com.":
+				// AliasMode at the apex where a CNAME is not allowed
+				return []*model.HTTPSRecord{{Priority: 0, Target: "cdn.provider.net", TTL: 300}}, nil
+			case "www.test.com.":
+				return []*model.HTTPSRecord{{
+					Priority: 1,
+					Target:   ".",
+					SvcParams: model.SvcParams{
+						ALPN:     "h2,h3",
+						Port:     8443,
+						IPv4Hint: "192.0.2.1, 192.0.2.2",
+						IPv6Hint: "2001:db8::1",
+						ECH:      "AEX+DQBBpQAgACB/",
+					},
+					TTL: 300,
+				}}, nil
+			}
+			return nil, nil
+		},
+		QueryARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			if recordName == "www.test.com." {
+				return []*model.ARecord{{IP: 0xc0000201, TTL: 600}}, nil
+			}
+			return nil, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+	resolve := func(name string) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion(name, dns.TypeHTTPS)
+		msg, err := r.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.NoError(t, err)
+		return msg
+	}
+
+	t.Run("AliasMode at the zone apex", func(t *testing.T) {
+		msg := resolve("test.com.")
+		if assert.Len(t, msg.Answer, 1) {
+			rr := msg.Answer[0].(*dns.HTTPS)
+			assert.Equal(t, uint16(0), rr.Priority)
+			assert.Equal(t, "cdn.provider.net.", rr.Target)
+			assert.Empty(t, rr.Value)
+		}
+		assert.Empty(t, msg.Extra)
+	})
+
+	t.Run("ServiceMode with parameters", func(t *testing.T) {
+		msg := resolve("www.test.com.")
+		if assert.Len(t, msg.Answer, 1) {
+			rr := msg.Answer[0].(*dns.HTTPS)
+			assert.Equal(t, `www.test.com.	300	IN	HTTPS	1 . alpn="h2,h3" port="8443" ipv4hint="192.0.2.1,192.0.2.2" ech="AEX+DQBBpQAgACB/" ipv6hint="2001:db8::1"`, rr.String())
+
+			// The RR survives a wire round trip
+			buf, err := msg.Pack()
+			assert.NoError(t, err)
+			assert.NoError(t, new(dns.Msg).Unpack(buf))
+		}
+		// "." in ServiceMode refers to the owner's own addresses
+		if assert.Len(t, msg.Extra, 1) {
+			assert.Equal(t, "www.test.com.", msg.Extra[0].Header().Name)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+// Command mmdb writes the small MaxMind databases used by the GeoIP tests.
+//
+//	go run ./resolver/testdata/mmdb
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type (
+	// Numbers are written with the MMDB type of their Go type
+	m   map[string]interface{}
+	arr []interface{}
+)
+
+type network struct {
+	prefix string
+	data   m
+}
+
+func main() {
+	dir := filepath.Join("resolver", "testdata")
+	city := []network{
+		{"81.2.69.142/31", m{
+			"continent":    m{"code": "EU"},
+			"country":      m{"iso_code": "GB"},
+			"subdivisions": arr{m{"iso_code": "ENG"}},
+			"location": m{
+				"latitude":        51.5142,
+				"longitude":       -0.0931,
+				"accuracy_radius": uint16(10),
+			},
+		}},
+		// No location
+		{"192.0.2.0/24", m{
+			"continent": m{"code": "OC"},
+			"country":   m{"iso_code": "AU"},
+		}},
+	}
+	asn := []network{
+		{"81.2.69.0/24", m{
+			"autonomous_system_number":       uint32(20712),
+			"autonomous_system_organization": "Andrews & Arnold Ltd",
+		}},
+	}
+	if err := write(filepath.Join(dir, "GeoLite2-City-Test.mmdb"), "GeoLite2-City", city); err != nil {
+		log.Fatal(err)
+	}
+	if err := write(filepath.Join(dir, "GeoLite2-ASN-Test.mmdb"), "GeoLite2-ASN", asn); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// write stores networks in an IPv4 database with 24 bit records
+func write(path, dbType string, networks []network) error {
+	type node struct{ child [2]int } // -1 empty, < -1 data index -(i+2)
+	nodes := []*node{{child: [2]int{-1, -1}}}
+	var data bytes.Buffer
+	var offsets []int
+
+	for i, n := range networks {
+		prefix, err := netip.ParsePrefix(n.prefix)
+		if err != nil {
+			return err
+		}
+		offsets = append(offsets, data.Len())
+		encode(&data, n.data)
+
+		ip := prefix.Masked().Addr().As4()
+		cur := 0
+		for bit := 0; bit < prefix.Bits(); bit++ {
+			b := int(ip[bit/8]>>(7-bit%8)) & 1
+			if bit == prefix.Bits()-1 {
+				nodes[cur].child[b] = -(i + 2)
+				break
+			}
+			if nodes[cur].child[b] < 0 {
+				nodes = append(nodes, &node{child: [2]int{-1, -1}})
+				nodes[cur].child[b] = len(nodes) - 1
+			}
+			cur = nodes[cur].child[b]
+		}
+	}
+
+	var out bytes.Buffer
+	count := len(nodes)
+	for _, n := range nodes {
+		for _, c := range n.child {
+			record := count
+			switch {
+			case c >= 0:
+				record = c
+			case c < -1:
+				record = count + 16 + offsets[-c-2]
+			}
+			out.Write([]byte{byte(record >> 16), byte(record >> 8), byte(record)})
+		}
+	}
+	out.Write(make([]byte, 16))
+	out.Write(data.Bytes())
+	out.WriteString("\xab\xcd\xefMaxMind.com")
+	encode(&out, m{
+		"node_count":                  uint32(count),
+		"record_size":                 uint16(24),
+		"ip_version":                  uint16(4),
+		"database_type":               dbType,
+		"languages":                   arr{"en"},
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1700000000),
+		"description":                 m{"en": "Hermes test data"},
+	})
+	return os.WriteFile(path, out.Bytes(), 0o644)
+}
+
+// encode appends v in the MMDB data section format
+func encode(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case string:
+		control(buf, 2, len(v))
+		buf.WriteString(v)
+	case float64:
+		control(buf, 3, 8)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case uint16:
+		unsigned(buf, 5, uint64(v))
+	case uint32:
+		unsigned(buf, 6, uint64(v))
+	case uint64:
+		unsigned(buf, 9, v)
+	case m:
+		control(buf, 7, len(v))
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			encode(buf, k)
+			encode(buf, v[k])
+		}
+	case arr:
+		control(buf, 11, len(v))
+		for _, e := range v {
+			encode(buf, e)
+		}
+	default:
+		panic(fmt.Sprintf("unsupported type %T", v))
+	}
+}
+
+// unsigned writes n with the fewest bytes
+func unsigned(buf *bytes.Buffer, typ int, n uint64) {
+	var b []byte
+	for ; n > 0; n >>= 8 {
+		b = append([]byte{byte(n)}, b...)
+	}
+	control(buf, typ, len(b))
+	buf.Write(b)
+}
+
+// control writes the control byte of a field with a size below 285
+func control(buf *bytes.Buffer, typ, size int) {
+	if size >= 285 {
+		panic("size too large")
+	}
+	first := byte(typ << 5)
+	if typ > 7 {
+		first = 0
+	}
+	if size < 29 {
+		buf.WriteByte(first | byte(size))
+	} else {
+		buf.WriteByte(first | 29)
+	}
+	if typ > 7 {
+		buf.WriteByte(byte(typ - 7))
+	}
+	if size >= 29 {
+		buf.WriteByte(byte(size - 29))
+	}
+}
@@ -0,0 +1,117 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+
+	"github.com/cylonchau/hermes/pkg/logger"
+)
+
+// upstreamCacheSize is the memory reserved for answers resolved through the upstream
+const upstreamCacheSize = 4 * 1024 * 1024
+
+// Upstream resolves names outside the zones served by Hermes.
+// It is satisfied by CoreDNS's pkg/upstream.
+type Upstream interface {
+	Lookup(ctx context.Context, state request.Request, name string, typ uint16) (*dns.Msg, error)
+}
+
+// upstreamState holds the configured upstream and its answer cache
+type upstreamState struct {
+	upstream Upstream
+	cache    *freecache.Cache
+}
+
+// requestKey carries the client request in the context for upstream lookups
+type requestKey struct{}
+
+// SetUpstream enables resolving external targets through u; nil disables it
+func (r *Resolver) SetUpstream(u Upstream) {
+	if u == nil {
+		r.upstream.Store(nil)
+		return
+	}
+	r.upstream.Store(&upstreamState{upstream: u, cache: freecache.NewCache(upstreamCacheSize)})
+}
+
+// external returns the qType RRset of an out-of-zone name from the upstream.
+// Answers are cached and served with their TTLs counted down.
+func (r *Resolver) external(ctx context.Context, name string, qType uint16) ([]dns.RR, error) {
+	up := r.upstream.Load()
+	if up == nil {
+		return nil, nil
+	}
+	state, ok := ctx.Value(requestKey{}).(request.Request)
+	if !ok {
+		return nil, nil
+	}
+
+	key := []byte(fmt.Sprintf("%s/%d", dns.CanonicalName(name), qType))
+	if buf, expireAt, err := up.cache.GetWithExpiration(key); err == nil {
+		cached := new(dns.Msg)
+		if err := cached.Unpack(buf); err == nil {
+			return countDown(cached.Answer, expireAt), nil
+		}
+	}
+
+	res, err := up.upstream.Lookup(ctx, state, name, qType)
+	if err != nil {
+		logger.Warn("Upstream lookup failed", logger.String("qname", name), logger.Err(err))
+		return nil, nil
+	}
+	if res == nil || res.Rcode != dns.RcodeSuccess {
+		return nil, nil
+	}
+
+	// Only the RRsets answering the question are kept
+	var rrs []dns.RR
+	for _, rr := range res.Answer {
+		if t := rr.Header().Rrtype; t == qType || t == dns.TypeCNAME || t == dns.TypeDNAME {
+			rrs = append(rrs, rr)
+		}
+	}
+	if ttl := minTTL(rrs); ttl > 0 {
+		cached := &dns.Msg{Answer: rrs}
+		if buf, err := cached.Pack(); err == nil {
+			_ = up.cache.Set(key, buf, int(ttl))
+		}
+	}
+	return rrs, nil
+}
+
+// minTTL returns the smallest TTL among rrs, 0 when rrs is empty
+func minTTL(rrs []dns.RR) uint32 {
+	var ttl uint32
+	for i, rr := range rrs {
+		if i == 0 || rr.Header().Ttl < ttl {
+			ttl = rr.Header().Ttl
+		}
+	}
+	return ttl
+}
+
+// countDown lowers the TTLs of cached rrs by the time they have spent in the
+// cache. The entry expires together with the record having the smallest TTL.
+func countDown(rrs []dns.RR, expireAt uint32) []dns.RR {
+	remaining := int64(expireAt) - time.Now().Unix()
+	if remaining < 0 {
+		remaining = 0
+	}
+	elapsed := int64(minTTL(rrs)) - remaining
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	for _, rr := range rrs {
+		if ttl := int64(rr.Header().Ttl) - elapsed; ttl > 0 {
+			rr.Header().Ttl = uint32(ttl)
+		} else {
+			rr.Header().Ttl = 0
+		}
+	}
+	return rrs
+}
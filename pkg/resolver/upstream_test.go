@@ -0,0 +1,24 @@
+package resolver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountDown(t *testing.T) {
+	a, _ := dns.NewRR("cdn.example.net. 120 IN A 203.0.113.7")
+	cname, _ := dns.NewRR("www.example.net. 300 IN CNAME cdn.example.net.")
+
+	// Cached 70 seconds ago with the smallest TTL of 120 seconds
+	expireAt := uint32(time.Now().Unix() + 50)
+	rrs := countDown([]dns.RR{cname, a}, expireAt)
+	assert.InDelta(t, 230, rrs[0].Header().Ttl, 1)
+	assert.InDelta(t, 50, rrs[1].Header().Ttl, 1)
+
+	// Expired entries never produce wrapped TTLs
+	rrs = countDown([]dns.RR{a}, uint32(time.Now().Unix()-10))
+	assert.Equal(t, uint32(0), rrs[0].Header().Ttl)
+}
@@ -0,0 +1,286 @@
+package resolver
+
+import (
+	"context"
+	"net/netip"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cylonchau/hermes/pkg/logger"
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// ViewLoader returns all views in match order
+type ViewLoader func(ctx context.Context) ([]model.View, error)
+
+// ViewMatcher is an in-memory compiled form of the view table. Views that
+// are a plain union of CIDR, GeoIP and ASN rules are kept in a prefix trie
+// per address family and in lookup tables, other views are evaluated rule by rule
+// only while they could still beat the indexed match. The compiled set is
+// swapped atomically on refresh.
+type ViewMatcher struct {
+	loader   ViewLoader
+	compiled atomic.Pointer[compiledViews]
+	loading  sync.Mutex
+	failedAt atomic.Int64 // unix nanoseconds of the last failed load
+}
+
+// viewLoadRetry is how long queries wait after a failed load before one of
+// them tries again, Run keeps refreshing in the meantime
+const viewLoadRetry = 5 * time.Second
+
+// compiledViews maps rules to the rank of the first view using them, the
+// lowest rank among all matching views wins
+type compiledViews struct {
+	ids        []int64 // view ID by rank
+	v4         *trieNode
+	v6         *trieNode
+	continents map[string]int
+	countries  map[string]int
+	regions    map[string]int
+	asns       map[uint32]int
+	evaluated  []compiledView // in rank order
+	none       int            // rank meaning no match
+}
+
+// trieNode is a node of a binary prefix trie
+type trieNode struct {
+	children [2]*trieNode
+	rank     int
+}
+
+// NewViewMatcher creates a view matcher backed by the given loader
+func NewViewMatcher(loader ViewLoader) *ViewMatcher {
+	return &ViewMatcher{loader: loader}
+}
+
+// Refresh reloads views from the loader and swaps the matcher
+func (vm *ViewMatcher) Refresh(ctx context.Context) error {
+	if vm.loader == nil {
+		return nil
+	}
+	views, err := vm.loader(ctx)
+	if err != nil {
+		return err
+	}
+	vm.Set(views)
+	return nil
+}
+
+// Set compiles views and replaces the current matcher. Views are matched by
+// priority, highest first, and by ID among views of equal priority.
+func (vm *ViewMatcher) Set(views []model.View) {
+	ordered := append([]model.View(nil), views...)
+	sort.SliceStable(ordered, func(a, b int) bool {
+		if ordered[a].Priority != ordered[b].Priority {
+			return ordered[a].Priority > ordered[b].Priority
+		}
+		return ordered[a].ID < ordered[b].ID
+	})
+
+	cv := &compiledViews{
+		ids:        make([]int64, len(ordered)),
+		continents: make(map[string]int),
+		countries:  make(map[string]int),
+		regions:    make(map[string]int),
+		asns:       make(map[uint32]int),
+		none:       len(ordered),
+	}
+	cv.v4 = &trieNode{rank: cv.none}
+	cv.v6 = &trieNode{rank: cv.none}
+	for rank := range ordered {
+		v := &ordered[rank]
+		cv.ids[rank] = v.ID
+		match, rules := viewRules(v)
+		if len(rules) == 0 {
+			continue
+		}
+		if !indexable(match, rules) {
+			view := compiledView{rank: rank, any: match == model.ViewMatchAny}
+			for _, rule := range rules {
+				view.conds = append(view.conds, compileRule(rule))
+			}
+			cv.evaluated = append(cv.evaluated, view)
+			continue
+		}
+		for _, rule := range rules {
+			c := compileRule(rule)
+			switch c.kind {
+			case model.ViewRuleCIDR, model.ViewRuleACL:
+				for _, prefix := range c.prefixes {
+					cv.insert(prefix, rank)
+				}
+			case model.ViewRuleContinent:
+				addCodes(cv.continents, c.codes, rank)
+			case model.ViewRuleCountry:
+				addCodes(cv.countries, c.codes, rank)
+			case model.ViewRuleRegion:
+				addCodes(cv.regions, c.codes, rank)
+			case model.ViewRuleASN:
+				for _, asn := range c.asns {
+					if _, ok := cv.asns[asn]; !ok {
+						cv.asns[asn] = rank
+					}
+				}
+			}
+		}
+	}
+	vm.compiled.Store(cv)
+}
+
+// addCodes maps codes to rank unless a better ranked view already uses them
+func addCodes(table map[string]int, codes []string, rank int) {
+	for _, code := range codes {
+		if _, ok := table[code]; !ok {
+			table[code] = rank
+		}
+	}
+}
+
+// insert adds prefix to the trie of its family, keeping the lowest rank
+func (cv *compiledViews) insert(prefix netip.Prefix, rank int) {
+	node := cv.v4
+	if prefix.Addr().Is6() {
+		node = cv.v6
+	}
+	addr := prefix.Addr().AsSlice()
+	for i := 0; i < prefix.Bits(); i++ {
+		bit := addr[i/8] >> (7 - i%8) & 1
+		if node.children[bit] == nil {
+			node.children[bit] = &trieNode{rank: cv.none}
+		}
+		node = node.children[bit]
+	}
+	if rank < node.rank {
+		node.rank = rank
+	}
+}
+
+// lookupACL returns the lowest rank of all prefixes containing addr
+func (cv *compiledViews) lookupACL(addr netip.Addr) int {
+	node := cv.v4
+	if !addr.Is4() {
+		node = cv.v6
+	}
+	best := node.rank
+	b := addr.AsSlice()
+	for i := 0; i < len(b)*8 && node != nil; i++ {
+		node = node.children[b[i/8]>>(7-i%8)&1]
+		if node != nil && node.rank < best {
+			best = node.rank
+		}
+	}
+	return best
+}
+
+// hasGeo reports whether any indexed view uses GeoIP or ASN rules
+func (cv *compiledViews) hasGeo() bool {
+	return len(cv.continents) > 0 || len(cv.countries) > 0 || len(cv.regions) > 0 || len(cv.asns) > 0
+}
+
+// lookupGeo returns the lowest rank among best and the GeoIP and ASN rules
+// matching geo
+func (cv *compiledViews) lookupGeo(geo GeoInfo, best int) int {
+	consider := func(rank int, found bool) {
+		if found && rank < best {
+			best = rank
+		}
+	}
+	if geo.Continent != "" {
+		rank, found := cv.continents[geo.Continent]
+		consider(rank, found)
+	}
+	if geo.Country != "" {
+		rank, found := cv.countries[geo.Country]
+		consider(rank, found)
+	}
+	for _, key := range regionKeys(geo.Country, geo.Region) {
+		rank, found := cv.regions[key]
+		consider(rank, found)
+	}
+	if geo.ASN != 0 {
+		rank, found := cv.asns[geo.ASN]
+		consider(rank, found)
+	}
+	return best
+}
+
+// load returns the current matcher, loading it on first use. While a load
+// is in progress or within viewLoadRetry of a failed one, queries get nil
+// right away instead of queueing behind the database.
+func (vm *ViewMatcher) load(ctx context.Context) *compiledViews {
+	if cv := vm.compiled.Load(); cv != nil {
+		return cv
+	}
+	if time.Since(time.Unix(0, vm.failedAt.Load())) < viewLoadRetry || !vm.loading.TryLock() {
+		return nil
+	}
+	defer vm.loading.Unlock()
+	if cv := vm.compiled.Load(); cv != nil {
+		return cv
+	}
+	if err := vm.Refresh(ctx); err != nil {
+		vm.failedAt.Store(time.Now().UnixNano())
+		logger.Error("Failed to load views", logger.Err(err))
+	}
+	return vm.compiled.Load()
+}
+
+// Match returns the ID of the first view matching the client, 0 when none
+// does. geoip is only consulted when a GeoIP or ASN rule could decide the
+// match.
+func (vm *ViewMatcher) Match(ctx context.Context, client ViewClient, geoip GeoIPProvider) (int64, error) {
+	cv := vm.load(ctx)
+	if cv == nil {
+		return 0, ErrNotReady
+	}
+	in := &matchInput{client: client, geoip: geoip}
+
+	best := cv.none
+	if client.Addr.IsValid() {
+		best = cv.lookupACL(client.Addr)
+	}
+	if cv.hasGeo() {
+		if geo, ok := in.location(); ok {
+			best = cv.lookupGeo(geo, best)
+		}
+	}
+	for i := range cv.evaluated {
+		view := &cv.evaluated[i]
+		if view.rank >= best {
+			break
+		}
+		if view.match(in) {
+			best = view.rank
+			break
+		}
+	}
+
+	if best == cv.none {
+		return 0, nil
+	}
+	return cv.ids[best], nil
+}
+
+// Run periodically refreshes the matcher until ctx is cancelled
+func (vm *ViewMatcher) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := vm.Refresh(ctx); err != nil {
+				logger.Warn("Failed to refresh views", logger.Err(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// viewClient returns a UDP client with the given address
+func viewClient(ip string) ViewClient {
+	return ViewClient{Addr: netip.MustParseAddr(ip), Transport: "udp"}
+}
+
+func TestViewMatcher_Match(t *testing.T) {
+	vm := NewViewMatcher(nil)
+	vm.Set([]model.View{
+		{ID: 1, Category: "acl", Value: "10.0.0.0/8", Priority: 10},
+		{ID: 2, Category: "acl", Value: "10.1.0.0/16, 192.168.1.0/24", Priority: 20},
+		{ID: 3, Category: "acl", Value: "10.2.0.0/16", Priority: 5},
+		{ID: 4, Category: "acl", Value: "2001:db8::/32", Priority: 10},
+		{ID: 5, Category: "acl", Value: "172.16.0.0/12", Priority: 10},
+		{ID: 6, Category: "acl", Value: "172.16.0.0/12", Priority: 10},
+		{ID: 7, Category: "acl", Value: "not-a-cidr", Priority: 100},
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		ip   string
+		view int64
+	}{
+		{"10.1.2.3", 2},        // more specific view with higher priority
+		{"10.2.2.3", 1},        // less specific view wins on priority
+		{"10.3.2.3", 1},        // only the /8 matches
+		{"192.168.1.9", 2},     // second CIDR of a list
+		{"172.16.5.5", 5},      // equal priority resolved by ID
+		{"2001:db8::1", 4},     // IPv6
+		{"::ffff:10.1.2.3", 0}, // IPv4-mapped IPv6 only matches IPv6 prefixes
+		{"8.8.8.8", 0},         // no match
+		{"2001:db9::1", 0},     // no match in IPv6
+	}
+
+	for _, tt := range tests {
+		view, err := vm.Match(ctx, viewClient(tt.ip), nil)
+		assert.NoError(t, err, tt.ip)
+		assert.Equal(t, tt.view, view, tt.ip)
+	}
+
+	// Clients without a usable address match no address rule
+	view, err := vm.Match(ctx, ViewClient{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), view)
+}
+
+func TestViewMatcher_GeoIP(t *testing.T) {
+	vm := NewViewMatcher(nil)
+	vm.Set([]model.View{
+		{ID: 1, Category: "geoip", Value: "CN", Priority: 10},
+		{ID: 2, Category: "geoip", Value: "CN-GD", Priority: 20},
+		{ID: 3, Category: "acl", Value: "203.0.113.0/24", Priority: 30},
+		{ID: 4, Category: "geoip", Value: "US", Priority: 10},
+	})
+	ctx := context.Background()
+
+	geo := func(country, region string) GeoIPProvider {
+		return &MockGeoIPProvider{LookupFn: func(ip string) (GeoInfo, error) {
+			return GeoInfo{Country: country, Region: region}, nil
+		}}
+	}
+
+	view, _ := vm.Match(ctx, viewClient("198.51.100.1"), geo("CN", "GD"))
+	assert.Equal(t, int64(2), view)
+
+	view, _ = vm.Match(ctx, viewClient("198.51.100.1"), geo("CN", "BJ"))
+	assert.Equal(t, int64(1), view)
+
+	// ACL view has the highest priority
+	view, _ = vm.Match(ctx, viewClient("203.0.113.5"), geo("CN", "GD"))
+	assert.Equal(t, int64(3), view)
+
+	view, _ = vm.Match(ctx, viewClient("198.51.100.1"), geo("DE", "BE"))
+	assert.Equal(t, int64(0), view)
+
+	// Lookup failures leave only ACL views
+	failing := &MockGeoIPProvider{LookupFn: func(ip string) (GeoInfo, error) {
+		return GeoInfo{}, errors.New("no record")
+	}}
+	view, _ = vm.Match(ctx, viewClient("198.51.100.1"), failing)
+	assert.Equal(t, int64(0), view)
+}
+
+func TestViewMatcher_ASN(t *testing.T) {
+	vm := NewViewMatcher(nil)
+	vm.Set([]model.View{
+		{ID: 1, Match: model.ViewMatchAny, Priority: 10, Rules: []model.ViewRule{{Type: "continent", Value: "EU"}}},
+		{ID: 2, Match: model.ViewMatchAny, Priority: 20, Rules: []model.ViewRule{
+			{Type: "asn", Value: "AS4134, 4812"},
+			{Type: "country", Value: "HK"},
+		}},
+		// Organization keywords are evaluated, not indexed
+		{ID: 3, Match: model.ViewMatchAll, Priority: 30, Rules: []model.ViewRule{
+			{Type: "continent", Value: "AS"},
+			{Type: "org", Value: "unicom"},
+		}},
+	})
+	ctx := context.Background()
+
+	geo := func(info GeoInfo) GeoIPProvider {
+		return &MockGeoIPProvider{LookupFn: func(ip string) (GeoInfo, error) {
+			return info, nil
+		}}
+	}
+
+	view, _ := vm.Match(ctx, viewClient("198.51.100.1"), geo(GeoInfo{Continent: "eu", Country: "DE"}))
+	assert.Equal(t, int64(1), view)
+
+	view, _ = vm.Match(ctx, viewClient("198.51.100.1"), geo(GeoInfo{Continent: "AS", Country: "CN", ASN: 4812}))
+	assert.Equal(t, int64(2), view)
+
+	view, _ = vm.Match(ctx, viewClient("198.51.100.1"), geo(GeoInfo{
+		Continent: "AS", Country: "CN", ASN: 4837, Organization: "China Unicom Backbone",
+	}))
+	assert.Equal(t, int64(3), view)
+
+	// No ASN database, only the location is known
+	view, _ = vm.Match(ctx, viewClient("198.51.100.1"), geo(GeoInfo{Continent: "AS", Country: "CN"}))
+	assert.Equal(t, int64(0), view)
+}
+
+func TestViewMatcher_Refresh(t *testing.T) {
+	views := []model.View{{ID: 1, Category: "acl", Value: "10.0.0.0/8"}}
+	var loadErr error
+	calls := 0
+	vm := NewViewMatcher(func(ctx context.Context) ([]model.View, error) {
+		calls++
+		return views, loadErr
+	})
+	ctx := context.Background()
+
+	// First match loads lazily
+	view, err := vm.Match(ctx, viewClient("10.0.0.1"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), view)
+
+	// Later matches use the compiled set
+	_, _ = vm.Match(ctx, viewClient("10.0.0.1"), nil)
+	assert.Equal(t, 1, calls)
+
+	views = []model.View{{ID: 2, Category: "acl", Value: "10.0.0.0/8"}}
+	assert.NoError(t, vm.Refresh(ctx))
+	view, _ = vm.Match(ctx, viewClient("10.0.0.1"), nil)
+	assert.Equal(t, int64(2), view)
+
+	// A failed refresh keeps the previous set
+	loadErr = errors.New("db down")
+	assert.Error(t, vm.Refresh(ctx))
+	view, _ = vm.Match(ctx, viewClient("10.0.0.1"), nil)
+	assert.Equal(t, int64(2), view)
+}
+
+func TestViewMatcher_NotReady(t *testing.T) {
+	loadErr := errors.New("db down")
+	calls := 0
+	vm := NewViewMatcher(func(ctx context.Context) ([]model.View, error) {
+		calls++
+		return []model.View{{ID: 1, Category: "acl", Value: "10.0.0.0/8"}}, loadErr
+	})
+	ctx := context.Background()
+
+	_, err := vm.Match(ctx, viewClient("10.0.0.1"), nil)
+	assert.ErrorIs(t, err, ErrNotReady)
+
+	// Queries right after a failed load do not retry it
+	_, err = vm.Match(ctx, viewClient("10.0.0.1"), nil)
+	assert.ErrorIs(t, err, ErrNotReady)
+	assert.Equal(t, 1, calls)
+
+	// Nor while another load is in progress
+	vm.failedAt.Store(0)
+	vm.loading.Lock()
+	_, err = vm.Match(ctx, viewClient("10.0.0.1"), nil)
+	assert.ErrorIs(t, err, ErrNotReady)
+	vm.loading.Unlock()
+	assert.Equal(t, 1, calls)
+
+	// Once the retry window is over the next query loads again
+	vm.failedAt.Store(time.Now().Add(-viewLoadRetry).UnixNano())
+	_, err = vm.Match(ctx, viewClient("10.0.0.1"), nil)
+	assert.ErrorIs(t, err, ErrNotReady)
+	assert.Equal(t, 2, calls)
+
+	loadErr = nil
+	vm.failedAt.Store(0)
+	view, err := vm.Match(ctx, viewClient("10.0.0.1"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), view)
+
+	_, _ = vm.Match(ctx, viewClient("10.0.0.1"), nil)
+	assert.Equal(t, 3, calls)
+
+	// Run refreshes regardless of the retry window
+	failing := NewViewMatcher(func(ctx context.Context) ([]model.View, error) {
+		return nil, errors.New("db down")
+	})
+	_, err = failing.Match(ctx, viewClient("10.0.0.1"), nil)
+	assert.ErrorIs(t, err, ErrNotReady)
+	failing.loader = vm.loader
+	assert.NoError(t, failing.Refresh(ctx))
+	view, err = failing.Match(ctx, viewClient("10.0.0.1"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), view)
+}
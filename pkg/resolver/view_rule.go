@@ -0,0 +1,270 @@
+package resolver
+
+import (
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+// ViewClient holds the query attributes views are matched on
+type ViewClient struct {
+	Addr      netip.Addr   // client address, the ECS subnet address when it applies
+	Subnet    netip.Prefix // trusted EDNS Client Subnet of the query, if any
+	Transport string       // "udp" or "tcp"
+	Listener  netip.Addr   // local address the query arrived on
+}
+
+// condition is a compiled view rule
+type condition struct {
+	kind     string
+	negate   bool
+	prefixes []netip.Prefix
+	codes    []string
+	asns     []uint32
+}
+
+// compileRule parses the values of a rule, invalid values are dropped
+func compileRule(rule model.ViewRule) condition {
+	c := condition{kind: rule.Type, negate: rule.Negate}
+	if rule.Type == model.ViewRuleGeoIP {
+		// Legacy values are compared whole and as written
+		if rule.Value != "" {
+			c.codes = []string{rule.Value}
+		}
+		return c
+	}
+	for _, v := range rule.Values() {
+		switch rule.Type {
+		case model.ViewRuleACL:
+			// Legacy ACLs only take CIDRs, bare addresses never matched
+			if prefix, err := netip.ParsePrefix(v); err == nil {
+				c.prefixes = append(c.prefixes, prefix.Masked())
+			}
+		case model.ViewRuleCIDR, model.ViewRuleECS, model.ViewRuleListener:
+			if prefix, ok := parsePrefix(v); ok {
+				c.prefixes = append(c.prefixes, prefix)
+			}
+		case model.ViewRuleContinent, model.ViewRuleCountry, model.ViewRuleRegion, model.ViewRuleOrg:
+			c.codes = append(c.codes, strings.ToUpper(v))
+		case model.ViewRuleTransport:
+			c.codes = append(c.codes, strings.ToLower(v))
+		case model.ViewRuleASN:
+			if asn, ok := parseASN(v); ok {
+				c.asns = append(c.asns, asn)
+			}
+		}
+	}
+	return c
+}
+
+// parsePrefix parses a CIDR or a single address as a host prefix. Like the
+// original matcher, IPv4-mapped prefixes only match IPv4-mapped addresses.
+func parsePrefix(s string) (netip.Prefix, bool) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, false
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), true
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return prefix.Masked(), true
+}
+
+// parseASN parses an AS number with an optional "AS" prefix
+func parseASN(s string) (uint32, bool) {
+	if len(s) > 2 && strings.EqualFold(s[:2], "AS") {
+		s = s[2:]
+	}
+	asn, err := strconv.ParseUint(s, 10, 32)
+	return uint32(asn), err == nil
+}
+
+// matchInput evaluates conditions for one client, the GeoIP lookup is done
+// at most once and only when a condition needs it
+type matchInput struct {
+	client ViewClient
+	geoip  GeoIPProvider
+
+	geoDone bool
+	geo     GeoInfo
+	raw     GeoInfo // geo as returned by the provider, for legacy rules
+	geoOK   bool
+}
+
+// location returns what the GeoIP provider knows about the client, with
+// codes and the organization upper-cased
+func (in *matchInput) location() (GeoInfo, bool) {
+	if !in.geoDone {
+		in.geoDone = true
+		if in.geoip != nil && in.client.Addr.IsValid() {
+			info, err := in.geoip.Lookup(in.client.Addr.String())
+			if err == nil {
+				in.raw = info
+				info.Continent = strings.ToUpper(info.Continent)
+				info.Country = strings.ToUpper(info.Country)
+				info.Region = strings.ToUpper(info.Region)
+				info.Organization = strings.ToUpper(info.Organization)
+				in.geo, in.geoOK = info, true
+			}
+		}
+	}
+	return in.geo, in.geoOK
+}
+
+// regionKeys returns the keys a region rule may use for the client,
+// country-region (CN-GD) and bare region (GD)
+func regionKeys(country, region string) []string {
+	if region == "" {
+		return nil
+	}
+	return []string{country + "-" + region, region}
+}
+
+// match reports whether the condition holds for the client. Attributes that
+// are unknown, like a failed GeoIP lookup, never satisfy a condition, so a
+// negated condition holds for them.
+func (c *condition) match(in *matchInput) bool {
+	var ok bool
+	switch c.kind {
+	case model.ViewRuleCIDR, model.ViewRuleACL:
+		ok = containsAddr(c.prefixes, in.client.Addr)
+	case model.ViewRuleGeoIP:
+		if _, found := in.location(); found {
+			geo := in.raw
+			for _, v := range c.codes {
+				if v == geo.Country || v == geo.Country+"-"+geo.Region || v == geo.Region {
+					ok = true
+					break
+				}
+			}
+		}
+	case model.ViewRuleListener:
+		ok = containsAddr(c.prefixes, in.client.Listener)
+	case model.ViewRuleECS:
+		subnet := in.client.Subnet
+		if subnet.IsValid() {
+			// No value means any client subnet
+			ok = len(c.prefixes) == 0
+			for _, prefix := range c.prefixes {
+				if prefix.Bits() <= subnet.Bits() && prefix.Contains(subnet.Addr()) {
+					ok = true
+					break
+				}
+			}
+		}
+	case model.ViewRuleTransport:
+		ok = containsCode(c.codes, in.client.Transport)
+	case model.ViewRuleContinent:
+		if geo, found := in.location(); found && geo.Continent != "" {
+			ok = containsCode(c.codes, geo.Continent)
+		}
+	case model.ViewRuleCountry:
+		if geo, found := in.location(); found && geo.Country != "" {
+			ok = containsCode(c.codes, geo.Country)
+		}
+	case model.ViewRuleRegion:
+		if geo, found := in.location(); found {
+			for _, key := range regionKeys(geo.Country, geo.Region) {
+				if containsCode(c.codes, key) {
+					ok = true
+					break
+				}
+			}
+		}
+	case model.ViewRuleASN:
+		if geo, found := in.location(); found && geo.ASN != 0 {
+			for _, v := range c.asns {
+				if v == geo.ASN {
+					ok = true
+					break
+				}
+			}
+		}
+	case model.ViewRuleOrg:
+		if geo, found := in.location(); found && geo.Organization != "" {
+			for _, keyword := range c.codes {
+				if strings.Contains(geo.Organization, keyword) {
+					ok = true
+					break
+				}
+			}
+		}
+	}
+	return ok != c.negate
+}
+
+// containsAddr reports whether any prefix contains addr
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsCode reports whether codes holds s
+func containsCode(codes []string, s string) bool {
+	for _, code := range codes {
+		if code == s {
+			return true
+		}
+	}
+	return false
+}
+
+// compiledView is a view that needs its conditions evaluated one by one
+type compiledView struct {
+	rank  int
+	any   bool
+	conds []condition
+}
+
+// match reports whether the client satisfies the view
+func (v *compiledView) match(in *matchInput) bool {
+	for i := range v.conds {
+		if v.conds[i].match(in) == v.any {
+			return v.any
+		}
+	}
+	return !v.any
+}
+
+// viewRules returns how the rules of a view combine and the rules, converting
+// the legacy single category format of views that have not been migrated yet
+func viewRules(v *model.View) (string, []model.ViewRule) {
+	if len(v.Rules) > 0 {
+		return v.Match, v.Rules
+	}
+	return v.LegacyRules()
+}
+
+// indexable reports whether the view is a plain union of address, GeoIP and
+// ASN rules, which can be looked up in the trie and lookup tables instead of
+// being evaluated
+func indexable(match string, rules []model.ViewRule) bool {
+	if len(rules) > 1 && match != model.ViewMatchAny {
+		return false
+	}
+	for _, rule := range rules {
+		if rule.Negate {
+			return false
+		}
+		switch rule.Type {
+		case model.ViewRuleCIDR, model.ViewRuleACL, model.ViewRuleContinent, model.ViewRuleCountry,
+			model.ViewRuleRegion, model.ViewRuleASN:
+		default:
+			return false
+		}
+	}
+	return true
+}
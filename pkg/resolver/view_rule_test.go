@@ -0,0 +1,232 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestCondition_Match(t *testing.T) {
+	geo := &MockGeoIPProvider{LookupFn: func(ip string) (GeoInfo, error) {
+		return GeoInfo{Continent: "as", Country: "cn", Region: "gd", ASN: 4134, Organization: "Chinanet"}, nil
+	}}
+	client := ViewClient{
+		Addr:      netip.MustParseAddr("198.51.100.7"),
+		Subnet:    netip.MustParsePrefix("198.51.100.0/24"),
+		Transport: "tcp",
+		Listener:  netip.MustParseAddr("192.0.2.53"),
+	}
+
+	tests := []struct {
+		name string
+		rule model.ViewRule
+		want bool
+	}{
+		{"cidr", model.ViewRule{Type: "cidr", Value: "10.0.0.0/8, 198.51.100.0/24"}, true},
+		{"cidr host", model.ViewRule{Type: "cidr", Value: "198.51.100.7"}, true},
+		{"cidr mapped", model.ViewRule{Type: "cidr", Value: "::ffff:198.51.100.0/120"}, false},
+		{"cidr miss", model.ViewRule{Type: "cidr", Value: "10.0.0.0/8"}, false},
+		{"cidr negated", model.ViewRule{Type: "cidr", Value: "10.0.0.0/8", Negate: true}, true},
+		{"continent", model.ViewRule{Type: "continent", Value: "AS"}, true},
+		{"continent miss", model.ViewRule{Type: "continent", Value: "EU"}, false},
+		{"country", model.ViewRule{Type: "country", Value: "US,cn"}, true},
+		{"region with country", model.ViewRule{Type: "region", Value: "CN-GD"}, true},
+		{"region alone", model.ViewRule{Type: "region", Value: "GD"}, true},
+		{"region miss", model.ViewRule{Type: "region", Value: "CN-BJ"}, false},
+		{"asn", model.ViewRule{Type: "asn", Value: "AS4134"}, true},
+		{"asn miss", model.ViewRule{Type: "asn", Value: "4837"}, false},
+		{"org", model.ViewRule{Type: "org", Value: "unicom, chinanet"}, true},
+		{"org miss", model.ViewRule{Type: "org", Value: "cernet"}, false},
+		{"ecs any", model.ViewRule{Type: "ecs"}, true},
+		{"ecs inside", model.ViewRule{Type: "ecs", Value: "198.51.0.0/16"}, true},
+		{"ecs wider than rule", model.ViewRule{Type: "ecs", Value: "198.51.100.0/25"}, false},
+		{"transport", model.ViewRule{Type: "transport", Value: "TCP"}, true},
+		{"transport miss", model.ViewRule{Type: "transport", Value: "udp"}, false},
+		{"listener", model.ViewRule{Type: "listener", Value: "192.0.2.53"}, true},
+		{"listener miss", model.ViewRule{Type: "listener", Value: "192.0.2.0/30"}, false},
+		{"acl", model.ViewRule{Type: "acl", Value: "10.0.0.0/8, 198.51.100.0/24"}, true},
+		{"acl host ignored", model.ViewRule{Type: "acl", Value: "198.51.100.7"}, false},
+		{"geoip country region", model.ViewRule{Type: "geoip", Value: "cn-gd"}, true},
+		{"geoip region", model.ViewRule{Type: "geoip", Value: "gd"}, true},
+		{"geoip case sensitive", model.ViewRule{Type: "geoip", Value: "CN"}, false},
+		{"geoip whole value", model.ViewRule{Type: "geoip", Value: "us,cn"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := compileRule(tt.rule)
+			assert.Equal(t, tt.want, c.match(&matchInput{client: client, geoip: geo}))
+		})
+	}
+
+	t.Run("unknown attributes", func(t *testing.T) {
+		in := &matchInput{client: ViewClient{Addr: client.Addr}}
+		for _, rule := range []model.ViewRule{
+			{Type: "continent", Value: "AS"},
+			{Type: "country", Value: "CN"},
+			{Type: "asn", Value: "4134"},
+			{Type: "org", Value: "chinanet"},
+			{Type: "ecs"},
+			{Type: "listener", Value: "0.0.0.0/0"},
+		} {
+			c := compileRule(rule)
+			assert.False(t, c.match(in), rule.Type)
+			c.negate = true
+			assert.True(t, c.match(in), rule.Type)
+		}
+	})
+}
+
+func TestViewMatcher_Rules(t *testing.T) {
+	vm := NewViewMatcher(nil)
+	vm.Set([]model.View{
+		// Office clients over TCP, except the lab
+		{ID: 1, Match: model.ViewMatchAll, Priority: 30, Rules: []model.ViewRule{
+			{Type: "cidr", Value: "10.0.0.0/8"},
+			{Type: "cidr", Value: "10.9.0.0/16", Negate: true},
+			{Type: "transport", Value: "tcp"},
+		}},
+		// Queries on the internal listener or from a resolver sending ECS
+		{ID: 2, Match: model.ViewMatchAny, Priority: 20, Rules: []model.ViewRule{
+			{Type: "listener", Value: "192.0.2.53"},
+			{Type: "ecs"},
+		}},
+		// Plain CIDR union served from the trie
+		{ID: 3, Match: model.ViewMatchAny, Priority: 10, Rules: []model.ViewRule{
+			{Type: "cidr", Value: "10.0.0.0/8"},
+			{Type: "cidr", Value: "172.16.0.0/12"},
+		}},
+		// A view without rules never matches
+		{ID: 4, Priority: 100},
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		client ViewClient
+		view   int64
+	}{
+		{"office over tcp", ViewClient{Addr: netip.MustParseAddr("10.1.1.1"), Transport: "tcp"}, 1},
+		{"office over udp", ViewClient{Addr: netip.MustParseAddr("10.1.1.1"), Transport: "udp"}, 3},
+		{"lab over tcp", ViewClient{Addr: netip.MustParseAddr("10.9.1.1"), Transport: "tcp"}, 3},
+		{"internal listener", ViewClient{
+			Addr: netip.MustParseAddr("10.9.1.1"), Transport: "udp", Listener: netip.MustParseAddr("192.0.2.53"),
+		}, 2},
+		{"client subnet", ViewClient{
+			Addr: netip.MustParseAddr("8.8.8.0"), Subnet: netip.MustParsePrefix("8.8.8.0/24"), Transport: "udp",
+		}, 2},
+		{"second cidr", ViewClient{Addr: netip.MustParseAddr("172.16.1.1"), Transport: "udp"}, 3},
+		{"no match", ViewClient{Addr: netip.MustParseAddr("8.8.8.8"), Transport: "udp"}, 0},
+	}
+
+	for _, tt := range tests {
+		view, err := vm.Match(ctx, tt.client, nil)
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.view, view, tt.name)
+	}
+}
+
+func TestViewMatcher_LegacyViews(t *testing.T) {
+	vm := NewViewMatcher(nil)
+	vm.Set([]model.View{
+		{ID: 1, Category: "acl", Value: "10.0.0.0/8", Priority: 10},
+		{ID: 2, Category: "geoip", Value: "CN-GD", Priority: 5},
+		// Rules take precedence over a leftover legacy value
+		{ID: 3, Category: "acl", Value: "0.0.0.0/0", Priority: 1, Rules: []model.ViewRule{
+			{Type: "country", Value: "US"},
+		}},
+		// A bare legacy value is a country or a region code
+		{ID: 4, Category: "geoip", Value: "GD", Priority: 3},
+	})
+	geo := func(country, region string) GeoIPProvider {
+		return &MockGeoIPProvider{LookupFn: func(ip string) (GeoInfo, error) {
+			return GeoInfo{Country: country, Region: region}, nil
+		}}
+	}
+	ctx := context.Background()
+
+	view, _ := vm.Match(ctx, viewClient("10.0.0.1"), geo("CN", "GD"))
+	assert.Equal(t, int64(1), view)
+	view, _ = vm.Match(ctx, viewClient("198.51.100.1"), geo("CN", "GD"))
+	assert.Equal(t, int64(2), view)
+	view, _ = vm.Match(ctx, viewClient("198.51.100.1"), geo("US", "CA"))
+	assert.Equal(t, int64(3), view)
+	view, _ = vm.Match(ctx, viewClient("198.51.100.1"), geo("CN", "BJ"))
+	assert.Equal(t, int64(0), view)
+	view, _ = vm.Match(ctx, viewClient("198.51.100.1"), geo("DE", "BE"))
+	assert.Equal(t, int64(0), view)
+	// Guangdong and Grenada both match the bare "GD"
+	view, _ = vm.Match(ctx, viewClient("198.51.100.2"), geo("CN", "GD"))
+	assert.Equal(t, int64(2), view)
+	vm.Set([]model.View{{ID: 4, Category: "geoip", Value: "GD"}})
+	view, _ = vm.Match(ctx, viewClient("198.51.100.2"), geo("CN", "GD"))
+	assert.Equal(t, int64(4), view)
+	view, _ = vm.Match(ctx, viewClient("198.51.100.2"), geo("GD", "03"))
+	assert.Equal(t, int64(4), view)
+	view, _ = vm.Match(ctx, viewClient("198.51.100.2"), geo("CN", "BJ"))
+	assert.Equal(t, int64(0), view)
+
+	// Unmigrated and migrated legacy views keep their original matching
+	for _, views := range [][]model.View{
+		{
+			{ID: 5, Category: "acl", Value: "198.51.100.3, 10.0.0.0/8", Priority: 3},
+			{ID: 6, Category: "geoip", Value: "CN,US", Priority: 2},
+			{ID: 7, Category: "geoip", Value: "Guangdong", Priority: 1},
+		},
+		{
+			{ID: 5, Priority: 3, Rules: []model.ViewRule{{Type: "acl", Value: "198.51.100.3, 10.0.0.0/8"}}},
+			{ID: 6, Priority: 2, Rules: []model.ViewRule{{Type: "geoip", Value: "CN,US"}}},
+			{ID: 7, Priority: 1, Rules: []model.ViewRule{{Type: "geoip", Value: "Guangdong"}}},
+		},
+	} {
+		vm.Set(views)
+		// A bare address in an ACL never matched
+		view, _ = vm.Match(ctx, viewClient("198.51.100.3"), nil)
+		assert.Equal(t, int64(0), view)
+		view, _ = vm.Match(ctx, viewClient("10.0.0.3"), nil)
+		assert.Equal(t, int64(5), view)
+		// GeoIP values are compared whole
+		view, _ = vm.Match(ctx, viewClient("198.51.100.3"), geo("CN", "BJ"))
+		assert.Equal(t, int64(0), view)
+		view, _ = vm.Match(ctx, viewClient("198.51.100.3"), geo("CN,US", ""))
+		assert.Equal(t, int64(6), view)
+		// and case-sensitively
+		view, _ = vm.Match(ctx, viewClient("198.51.100.3"), geo("CN", "Guangdong"))
+		assert.Equal(t, int64(7), view)
+		view, _ = vm.Match(ctx, viewClient("198.51.100.3"), geo("CN", "GUANGDONG"))
+		assert.Equal(t, int64(0), view)
+	}
+}
+
+func TestResolver_MatchView(t *testing.T) {
+	r := NewResolver(&MockDNSQueryRepository{}, nil, nil)
+	r.Views().Set([]model.View{
+		{ID: 7, Match: model.ViewMatchAll, Rules: []model.ViewRule{
+			{Type: "transport", Value: "tcp"},
+			{Type: "listener", Value: "192.0.2.53"},
+			{Type: "ecs", Value: "198.51.100.0/24"},
+		}},
+	})
+
+	req := new(dns.Msg)
+	req.SetQuestion("www.test.com.", dns.TypeA)
+	w := &MockResponseWriter{RemoteIP: net.ParseIP("203.0.113.1"), LocalIP: net.ParseIP("192.0.2.53"), TCP: true}
+	state := request.Request{W: w, Req: req}
+	subnet := netip.MustParsePrefix("198.51.100.0/24")
+
+	view, err := r.matchView(context.Background(), state, "198.51.100.0", subnet)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), view)
+
+	// Without the client subnet the view no longer applies
+	view, err = r.matchView(context.Background(), state, "203.0.113.1", netip.Prefix{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), view)
+}
@@ -0,0 +1,54 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// wildcardSource returns the wildcard owner name that covers name according to
+// the closest encloser rules of RFC 4592. It returns "" when name exists, or
+// when name is the zone apex. Existing names and empty non-terminals between
+// name and the zone apex block wildcards further up the tree.
+func (r *Resolver) wildcardSource(ctx context.Context, zone, name string, viewID int64) (string, error) {
+	if strings.EqualFold(name, zone) || !dns.IsSubDomain(zone, name) {
+		return "", nil
+	}
+
+	exists, err := r.dao.NameExists(ctx, zone, name, viewID)
+	if err != nil || exists {
+		return "", err
+	}
+
+	// Walk up towards the apex until the closest existing ancestor is found
+	encloser := name
+	for {
+		off, end := dns.NextLabel(encloser, 0)
+		if end {
+			return "", nil
+		}
+		encloser = encloser[off:]
+		if strings.EqualFold(encloser, zone) {
+			break
+		}
+
+		exists, err := r.dao.NameExists(ctx, zone, encloser, viewID)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			break
+		}
+	}
+
+	return "*." + encloser, nil
+}
+
+// withOwner rewrites owner names of rrs, used to synthesize wildcard answers
+func withOwner(rrs []dns.RR, owner string) []dns.RR {
+	for _, rr := range rrs {
+		rr.Header().Name = owner
+	}
+	return rrs
+}
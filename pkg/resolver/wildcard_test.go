@@ -0,0 +1,106 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/model"
+)
+
+func TestResolver_Resolve_Wildcard(t *testing.T) {
+	// Names present in the zone, including the empty non-terminal b.test.com.
+	names := []string{"test.com.", "*.test.com.", "host.test.com.", "a.b.test.com.", "*.app.test.com.", "app.test.com."}
+	exists := func(name string) bool {
+		for _, n := range names {
+			if n == name || dns.IsSubDomain(name, n) {
+				return true
+			}
+		}
+		return false
+	}
+
+	mockRepo := &MockDNSQueryRepository{
+		NameExistsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) (bool, error) {
+			return exists(recordName), nil
+		},
+		QueryARecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.ARecord, error) {
+			switch {
+			case recordName == "*.test.com.":
+				return []*model.ARecord{{IP: 0x01020304, TTL: 60}}, nil
+			case recordName == "host.test.com.":
+				return []*model.ARecord{{IP: 0x05060708, TTL: 60}}, nil
+			case recordName == "*.app.test.com." && viewID == 10:
+				return []*model.ARecord{{IP: 0x0a000001, TTL: 60}}, nil
+			}
+			return nil, nil
+		},
+		QueryCNAMERecordsFn: func(ctx context.Context, zoneName, recordName string, viewID int64) ([]*model.CNAMERecord, error) {
+			if recordName == "*.app.test.com." && viewID == 0 {
+				return []*model.CNAMERecord{{Target: "host.test.com.", TTL: 60}}, nil
+			}
+			return nil, nil
+		},
+		QuerySOARecordFn: func(ctx context.Context, zoneName string, viewID int64) (*model.SOARecord, error) {
+			return &model.SOARecord{PrimaryNS: "ns1.test.com.", MBox: "admin.test.com.", TTL: 3600}, nil
+		},
+	}
+	r := newTestResolver(t, mockRepo, "test.com.")
+
+	mockW := &MockResponseWriter{RemoteIP: net.ParseIP("127.0.0.1")}
+	resolve := func(qName string, qType uint16) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion(qName, qType)
+		msg, err := r.Resolve(context.Background(), request.Request{W: mockW, Req: req})
+		assert.NoError(t, err)
+		return msg
+	}
+
+	t.Run("Synthesized from wildcard", func(t *testing.T) {
+		msg := resolve("x.y.test.com.", dns.TypeA)
+		if assert.Len(t, msg.Answer, 1) {
+			assert.Equal(t, "x.y.test.com.", msg.Answer[0].Header().Name)
+			assert.Equal(t, "1.2.3.4", msg.Answer[0].(*dns.A).A.String())
+		}
+	})
+
+	t.Run("Existing name blocks wildcard", func(t *testing.T) {
+		msg := resolve("host.test.com.", dns.TypeA)
+		if assert.Len(t, msg.Answer, 1) {
+			assert.Equal(t, "5.6.7.8", msg.Answer[0].(*dns.A).A.String())
+		}
+		msg = resolve("host.test.com.", dns.TypeMX)
+		assert.Empty(t, msg.Answer)
+	})
+
+	t.Run("Empty non-terminal blocks wildcard", func(t *testing.T) {
+		msg := resolve("b.test.com.", dns.TypeA)
+		assert.Empty(t, msg.Answer)
+		// Closest encloser is b.test.com. and *.b.test.com. does not exist
+		msg = resolve("c.b.test.com.", dns.TypeA)
+		assert.Empty(t, msg.Answer)
+	})
+
+	t.Run("Wildcard CNAME is chased", func(t *testing.T) {
+		msg := resolve("api.app.test.com.", dns.TypeA)
+		if assert.Len(t, msg.Answer, 2) {
+			assert.Equal(t, "api.app.test.com.", msg.Answer[0].Header().Name)
+			assert.Equal(t, "host.test.com.", msg.Answer[0].(*dns.CNAME).Target)
+			assert.Equal(t, "5.6.7.8", msg.Answer[1].(*dns.A).A.String())
+		}
+	})
+
+	t.Run("Wildcard honours views", func(t *testing.T) {
+		rView := newTestResolver(t, mockRepo, "test.com.")
+		rrs, err := rView.answer(context.Background(), "test.com.", "api.app.test.com.", dns.TypeA, 10)
+		assert.NoError(t, err)
+		if assert.Len(t, rrs, 1) {
+			assert.Equal(t, "api.app.test.com.", rrs[0].Header().Name)
+			assert.Equal(t, "10.0.0.1", rrs[0].(*dns.A).A.String())
+		}
+	})
+}
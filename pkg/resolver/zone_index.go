@@ -0,0 +1,133 @@
+package resolver
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/cylonchau/hermes/pkg/logger"
+)
+
+// ZoneLoader returns the names of all active zones served by Hermes
+type ZoneLoader func(ctx context.Context) ([]string, error)
+
+// ZoneIndex is an in-memory suffix index of active zones.
+// Lookups never touch the database; the index is swapped atomically on refresh.
+type ZoneIndex struct {
+	loader ZoneLoader
+	zones  atomic.Pointer[map[string]struct{}]
+	once   sync.Once
+}
+
+// NewZoneIndex creates a zone index backed by the given loader
+func NewZoneIndex(loader ZoneLoader) *ZoneIndex {
+	return &ZoneIndex{loader: loader}
+}
+
+// Refresh reloads zone names from the loader and swaps the index
+func (z *ZoneIndex) Refresh(ctx context.Context) error {
+	if z.loader == nil {
+		return nil
+	}
+	names, err := z.loader(ctx)
+	if err != nil {
+		return err
+	}
+	z.Set(names)
+	return nil
+}
+
+// Set replaces the indexed zone names
+func (z *ZoneIndex) Set(names []string) {
+	zones := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		zones[strings.ToLower(dns.Fqdn(name))] = struct{}{}
+	}
+	z.zones.Store(&zones)
+}
+
+// load returns the current index, loading it on first use unless it has
+// already been populated
+func (z *ZoneIndex) load(ctx context.Context) *map[string]struct{} {
+	z.once.Do(func() {
+		if z.zones.Load() == nil {
+			if err := z.Refresh(ctx); err != nil {
+				logger.Error("Failed to load zone index", logger.Err(err))
+			}
+		}
+	})
+	return z.zones.Load()
+}
+
+// Match returns the longest indexed zone that is a suffix of qName
+func (z *ZoneIndex) Match(ctx context.Context, qName string) (string, bool) {
+	zones := z.load(ctx)
+	if zones == nil {
+		return "", false
+	}
+
+	qName = strings.ToLower(dns.Fqdn(qName))
+	for off, end := 0, false; !end; off, end = dns.NextLabel(qName, off) {
+		if _, ok := (*zones)[qName[off:]]; ok {
+			return qName[off:], true
+		}
+	}
+	// Root zone
+	if _, ok := (*zones)["."]; ok {
+		return ".", true
+	}
+	return "", false
+}
+
+// Highest returns the shortest indexed name strictly below apex that is a
+// suffix of qName, i.e. the topmost zone cut on the path from apex to qName
+func (z *ZoneIndex) Highest(ctx context.Context, qName, apex string) (string, bool) {
+	zones := z.load(ctx)
+	if zones == nil || len(*zones) == 0 {
+		return "", false
+	}
+
+	qName = strings.ToLower(dns.Fqdn(qName))
+	apex = strings.ToLower(dns.Fqdn(apex))
+	if !dns.IsSubDomain(apex, qName) {
+		return "", false
+	}
+
+	// Labels of qName below the apex, walked from the apex downwards
+	labels := dns.CountLabel(qName) - dns.CountLabel(apex)
+	idx := dns.Split(qName)
+	for i := labels - 1; i >= 0; i-- {
+		if _, ok := (*zones)[qName[idx[i]:]]; ok {
+			return qName[idx[i]:], true
+		}
+	}
+	return "", false
+}
+
+// Run periodically refreshes the index until ctx is cancelled
+func (z *ZoneIndex) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := z.Refresh(ctx); err != nil {
+				logger.Warn("Failed to refresh zone index", logger.Err(err))
+			}
+		}
+	}
+}
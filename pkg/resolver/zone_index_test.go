@@ -0,0 +1,91 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZoneIndex_Match(t *testing.T) {
+	idx := NewZoneIndex(nil)
+	idx.Set([]string{"example.com.", "corp.example.com.", "example.co.uk", "Upper.NET."})
+	ctx := context.Background()
+
+	tests := []struct {
+		qName string
+		zone  string
+		ok    bool
+	}{
+		{"www.example.com.", "example.com.", true},
+		{"example.com.", "example.com.", true},
+		{"host.corp.example.com.", "corp.example.com.", true},
+		{"corp.example.com.", "corp.example.com.", true},
+		{"www.example.co.uk.", "example.co.uk.", true},
+		{"WWW.upper.net.", "upper.net.", true},
+		{"co.uk.", "", false},
+		{"www.example.org.", "", false},
+		{"com.", "", false},
+	}
+
+	for _, tt := range tests {
+		zone, ok := idx.Match(ctx, tt.qName)
+		assert.Equal(t, tt.ok, ok, tt.qName)
+		assert.Equal(t, tt.zone, zone, tt.qName)
+	}
+}
+
+func TestZoneIndex_Refresh(t *testing.T) {
+	zones := []string{"example.com."}
+	idx := NewZoneIndex(func(ctx context.Context) ([]string, error) {
+		return zones, nil
+	})
+	ctx := context.Background()
+
+	// First lookup loads lazily
+	zone, ok := idx.Match(ctx, "a.b.example.com.")
+	assert.True(t, ok)
+	assert.Equal(t, "example.com.", zone)
+
+	zones = []string{"example.com.", "b.example.com."}
+	assert.NoError(t, idx.Refresh(ctx))
+
+	zone, ok = idx.Match(ctx, "a.b.example.com.")
+	assert.True(t, ok)
+	assert.Equal(t, "b.example.com.", zone)
+}
+
+func TestZoneIndex_RefreshError(t *testing.T) {
+	fail := false
+	idx := NewZoneIndex(func(ctx context.Context) ([]string, error) {
+		if fail {
+			return nil, errors.New("db down")
+		}
+		return []string{"example.com."}, nil
+	})
+	ctx := context.Background()
+	assert.NoError(t, idx.Refresh(ctx))
+
+	// A failed refresh keeps serving the previous index
+	fail = true
+	assert.Error(t, idx.Refresh(ctx))
+	_, ok := idx.Match(ctx, "www.example.com.")
+	assert.True(t, ok)
+}
+
+func TestZoneIndex_Highest(t *testing.T) {
+	idx := NewZoneIndex(nil)
+	idx.Set([]string{"team.example.com.", "sub.team.example.com."})
+	ctx := context.Background()
+
+	cut, ok := idx.Highest(ctx, "a.sub.team.example.com.", "example.com.")
+	assert.True(t, ok)
+	assert.Equal(t, "team.example.com.", cut)
+
+	_, ok = idx.Highest(ctx, "www.example.com.", "example.com.")
+	assert.False(t, ok)
+
+	_, ok = idx.Highest(ctx, "team.example.com.", "team.example.com.")
+	assert.False(t, ok)
+}
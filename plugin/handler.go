@@ -3,8 +3,11 @@ package plugin
 import (
 	"context"
 	"fmt"
+	"net/netip"
+	"time"
 
 	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/pkg/fall"
 	"github.com/coredns/coredns/request"
 	"github.com/miekg/dns"
 	"gorm.io/gorm"
@@ -19,22 +22,42 @@ const pluginName = "hermes"
 // Hermes struct
 type Hermes struct {
 	Next           plugin.Handler
+	Origins        []string // Zones claimed by hermes, defaults to server block zones
+	Fall           fall.F   // Zones for which NXDOMAIN falls through to the next plugin
 	DatabaseConfig store.DatabaseConfig
 	Resolver       *resolver.Resolver
-	GeoIPPath      string
-	CacheSizeMB    int // Cache Size limit, Unit: MB
+	GeoIP          []GeoIPSource     // GeoIP providers in lookup order, earlier ones take precedence
+	CacheSizeMB    int               // Cache Size limit, Unit: MB
+	ZoneRefresh    time.Duration     // Zone index refresh interval
+	Upstream       resolver.Upstream // Resolves external ALIAS and CNAME targets, nil when disabled
+	HealthChecks   bool              // Probe health-checked records in the background
+	HealthRefresh  time.Duration     // Health check and target reload interval
+	ECS            bool              // Match views on EDNS Client Subnet
+	ECSTrusted     []netip.Prefix    // Resolvers allowed to send a client subnet, empty means any
+
+	cancel context.CancelFunc
 }
 
-
 // ServeDNS handles DNS requests
 func (h *Hermes) ServeDNS(ctx context.Context, w dns.ResponseWriter, r *dns.Msg) (int, error) {
 	state := request.Request{W: w, Req: r}
+	qName := state.Name()
+
+	// Only claim names inside the configured zones
+	if plugin.Zones(h.Origins).Matches(qName) == "" {
+		return plugin.NextOrFailure(h.Name(), h.Next, ctx, w, r)
+	}
 
+	// Names not owned by any hermes zone are handed to the next plugin
 	msg, err := h.Resolver.Resolve(ctx, state)
 	if err != nil {
 		return plugin.NextOrFailure(h.Name(), h.Next, ctx, w, r)
 	}
 
+	if msg.Rcode == dns.RcodeNameError && h.Fall.Through(qName) {
+		return plugin.NextOrFailure(h.Name(), h.Next, ctx, w, r)
+	}
+
 	_ = w.WriteMsg(msg)
 	return dns.RcodeSuccess, nil
 }
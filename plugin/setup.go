@@ -1,18 +1,31 @@
 package plugin
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/coredns/caddy"
 	"github.com/coredns/coredns/core/dnsserver"
 	"github.com/coredns/coredns/plugin"
+	"github.com/coredns/coredns/plugin/pkg/parse"
+	"github.com/coredns/coredns/plugin/pkg/upstream"
 
-	"github.com/cylonchau/hermes/pkg/dao/rdb"
 	"github.com/cylonchau/hermes/pkg/dao/memory"
+	"github.com/cylonchau/hermes/pkg/dao/rdb"
+	"github.com/cylonchau/hermes/pkg/health"
+	"github.com/cylonchau/hermes/pkg/logger"
 	"github.com/cylonchau/hermes/pkg/resolver"
 	"github.com/cylonchau/hermes/pkg/store"
 )
 
+// defaultZoneRefresh is the default refresh interval of the zone suffix index
+const defaultZoneRefresh = 30 * time.Second
+
 // init registers the plugin
 func init() {
 	caddy.RegisterPlugin(pluginName, caddy.Plugin{
@@ -35,12 +48,9 @@ func setup(c *caddy.Controller) error {
 			return err
 		}
 
-		var geoip resolver.GeoIPProvider
-		if h.GeoIPPath != "" {
-			geoip, err = resolver.NewMaxMindProvider(h.GeoIPPath)
-			if err != nil {
-				return plugin.Error(pluginName, err)
-			}
+		geoip, overrides, err := h.newGeoIP()
+		if err != nil {
+			return plugin.Error(pluginName, err)
 		}
 
 		// Initialize resolver
@@ -52,10 +62,48 @@ func setup(c *caddy.Controller) error {
 		rdbDAO := rdb.NewRecordDAO(h.GetDB())
 		cachedDAO := rdb.NewCachedDNSQueryRepository(rdbDAO, cache) // Mount L1 memory cache proxy
 		h.Resolver = resolver.NewResolver(cachedDAO, h.GetDB(), geoip)
+		h.Resolver.SetUpstream(h.Upstream)
+		if h.ECS {
+			h.Resolver.SetECS(h.ECSTrusted)
+		}
+
+		// Load zone and delegation indexes and keep them refreshed in background
+		ctx, cancel := context.WithCancel(context.Background())
+		h.cancel = cancel
+		if err := h.Resolver.Refresh(ctx); err != nil {
+			logger.Warn("Failed to load zone index", logger.Err(err))
+		}
+		zoneRefresh := defaultZoneRefresh
+		if h.ZoneRefresh > 0 {
+			zoneRefresh = h.ZoneRefresh
+		}
+		go h.Resolver.Run(ctx, zoneRefresh)
+
+		if overrides != nil {
+			if err := overrides.Refresh(ctx); err != nil {
+				logger.Warn("Failed to load GeoIP overrides", logger.Err(err))
+			}
+			go overrides.Run(ctx, zoneRefresh)
+		}
+
+		if h.HealthChecks {
+			checker := health.NewChecker(rdb.NewHealthCheckDAO(h.GetDB()))
+			// Health is applied to rows after they are read through the
+			// cache, so state changes need no cache invalidation
+			h.Resolver.SetHealth(checker)
+			healthRefresh := zoneRefresh
+			if h.HealthRefresh > 0 {
+				healthRefresh = h.HealthRefresh
+			}
+			go checker.Run(ctx, healthRefresh)
+		}
 		return nil
 	})
 
 	c.OnShutdown(func() error {
+		if h.cancel != nil {
+			h.cancel()
+		}
 		return h.Close()
 	})
 
@@ -72,6 +120,9 @@ func parseHermes(c *caddy.Controller) (*Hermes, error) {
 	h := &Hermes{}
 
 	for c.Next() {
+		// Zones claimed by hermes default to the server block zones
+		h.Origins = plugin.OriginsFromArgsOrServerBlock(c.RemainingArgs(), c.ServerBlockKeys)
+
 		for c.NextBlock() {
 			switch c.Val() {
 			case "db":
@@ -90,9 +141,22 @@ func parseHermes(c *caddy.Controller) (*Hermes, error) {
 					return nil, c.Errf("unsupported database type: %s", dbTypeStr)
 				}
 
-				// Parse database sub-block
-				for c.NextBlock() {
+				// Parse database sub-block. NextBlock only tracks a single level
+				// of nesting, so the braces of this one are consumed here.
+				if !c.NextArg() {
+					continue
+				}
+				if c.Val() != "{" {
+					return nil, c.SyntaxErr("{")
+				}
+				for {
+					if !c.Next() {
+						return nil, c.EOFErr()
+					}
 					val := c.Val()
+					if val == "}" {
+						break
+					}
 					switch val {
 					case "host":
 						if !c.NextArg() {
@@ -140,9 +204,6 @@ func parseHermes(c *caddy.Controller) (*Hermes, error) {
 							return nil, c.ArgErr()
 						}
 						h.DatabaseConfig.MaxIdleConnection = c.Val()
-					case "{", "}":
-						// Fault tolerance: explicitly ignore braces
-						continue
 					default:
 						return nil, c.Errf("unknown db property: %s", val)
 					}
@@ -157,10 +218,59 @@ func parseHermes(c *caddy.Controller) (*Hermes, error) {
 				}
 				h.CacheSizeMB = size
 			case "geoip":
+				// Each geoip line adds a provider to the chain
+				src, err := parseGeoIPSource(c.RemainingArgs())
+				if err != nil {
+					return nil, c.Err(err.Error())
+				}
+				h.GeoIP = append(h.GeoIP, src)
+			case "fallthrough":
+				h.Fall.SetZonesFromArgs(c.RemainingArgs())
+			case "zone_refresh":
 				if !c.NextArg() {
 					return nil, c.ArgErr()
 				}
-				h.GeoIPPath = c.Val()
+				d, err := time.ParseDuration(c.Val())
+				if err != nil || d <= 0 {
+					return nil, c.Errf("invalid zone_refresh value: %s", c.Val())
+				}
+				h.ZoneRefresh = d
+			case "upstream":
+				// Without addresses lookups go through the CoreDNS plugin chain again
+				args := c.RemainingArgs()
+				if len(args) == 0 {
+					h.Upstream = upstream.New()
+					continue
+				}
+				addrs, err := parse.HostPortOrFile(args...)
+				if err != nil {
+					return nil, c.Errf("invalid upstream: %v", err)
+				}
+				h.Upstream = newForwarder(addrs)
+			case "healthcheck":
+				// Optional interval for reloading checks and targets
+				h.HealthChecks = true
+				args := c.RemainingArgs()
+				if len(args) > 1 {
+					return nil, c.ArgErr()
+				}
+				if len(args) == 1 {
+					d, err := time.ParseDuration(args[0])
+					if err != nil || d <= 0 {
+						return nil, c.Errf("invalid healthcheck value: %s", args[0])
+					}
+					h.HealthRefresh = d
+				}
+			case "ecs":
+				// Optional networks of resolvers trusted to send a client subnet
+				h.ECS = true
+				for _, arg := range c.RemainingArgs() {
+					prefix, err := netip.ParsePrefix(arg)
+					if err != nil {
+						return nil, c.Errf("invalid ecs network: %s", arg)
+					}
+					h.ECSTrusted = append(h.ECSTrusted, prefix.Masked())
+				}
 			default:
 				return nil, c.Errf("unknown property: %s", c.Val())
 			}
@@ -169,3 +279,87 @@ func parseHermes(c *caddy.Controller) (*Hermes, error) {
 
 	return h, nil
 }
+
+// GeoIPSource is a GeoIP provider configured by a geoip line
+type GeoIPSource struct {
+	Kind string   // maxmind, csv or override
+	Args []string // database paths for maxmind, layout and file for csv
+}
+
+// parseGeoIPSource parses the arguments of a geoip line:
+//
+//	geoip [maxmind] CITY_DB [ASN_DB]   "-" skips the City database
+//	geoip csv LAYOUT FILE              IP2Location or DB-IP range file
+//	geoip override                     CIDR overrides stored in the database
+func parseGeoIPSource(args []string) (GeoIPSource, error) {
+	if len(args) == 0 {
+		return GeoIPSource{}, errors.New("geoip needs a provider")
+	}
+	switch args[0] {
+	case "override":
+		if len(args) != 1 {
+			return GeoIPSource{}, errors.New("geoip override takes no arguments")
+		}
+		return GeoIPSource{Kind: "override"}, nil
+	case "csv":
+		if len(args) != 3 {
+			return GeoIPSource{}, errors.New("usage: geoip csv LAYOUT FILE")
+		}
+		for _, layout := range resolver.CSVLayouts() {
+			if args[1] == layout {
+				return GeoIPSource{Kind: "csv", Args: args[1:]}, nil
+			}
+		}
+		return GeoIPSource{}, fmt.Errorf("unknown csv layout %q, use one of %s",
+			args[1], strings.Join(resolver.CSVLayouts(), ", "))
+	case "maxmind":
+		args = args[1:]
+	}
+	if len(args) == 0 || len(args) > 2 || (args[0] == "-" && len(args) == 1) {
+		return GeoIPSource{}, errors.New("usage: geoip [maxmind] CITY_DB [ASN_DB]")
+	}
+	paths := []string{args[0], ""}
+	if paths[0] == "-" {
+		paths[0] = ""
+	}
+	if len(args) == 2 {
+		paths[1] = args[1]
+	}
+	return GeoIPSource{Kind: "maxmind", Args: paths}, nil
+}
+
+// newGeoIP opens the configured GeoIP providers, chaining them when there is
+// more than one. The override provider is returned separately so it can be
+// kept refreshed.
+func (h *Hermes) newGeoIP() (resolver.GeoIPProvider, *resolver.OverrideProvider, error) {
+	var providers []resolver.GeoIPProvider
+	var overrides *resolver.OverrideProvider
+	for _, src := range h.GeoIP {
+		var p resolver.GeoIPProvider
+		var err error
+		switch src.Kind {
+		case "maxmind":
+			p, err = resolver.NewMaxMindProvider(src.Args[0], src.Args[1])
+		case "csv":
+			p, err = resolver.NewCSVProvider(src.Args[1], src.Args[0])
+		case "override":
+			if overrides == nil {
+				overrides = resolver.NewOverrideProvider(rdb.NewGeoIPOverrideDAO(h.GetDB()).GetAll)
+			}
+			p = overrides
+		}
+		if err != nil {
+			resolver.NewGeoIPChain(providers...).Close()
+			return nil, nil, err
+		}
+		providers = append(providers, p)
+	}
+
+	switch len(providers) {
+	case 0:
+		return nil, nil, nil
+	case 1:
+		return providers[0], overrides, nil
+	}
+	return resolver.NewGeoIPChain(providers...), overrides, nil
+}
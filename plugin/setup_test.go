@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/coredns/caddy"
+	"github.com/coredns/coredns/plugin/pkg/upstream"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/cylonchau/hermes/pkg/store"
+)
+
+func TestParseHermes(t *testing.T) {
+	t.Run("Full configuration", func(t *testing.T) {
+		c := caddy.NewTestController("dns", `hermes example.com {
+			db mysql {
+				host 127.0.0.1
+				port 3306
+				database hermes
+			}
+			cache_size 64
+			zone_refresh 1m
+			fallthrough in-addr.arpa.
+			upstream 192.0.2.53 192.0.2.54:5353
+			healthcheck 10s
+			ecs 10.0.0.1/8 2001:db8::/32
+			geoip override
+			geoip csv dbip ranges.csv
+			geoip - asn.mmdb
+		}`)
+		h, err := parseHermes(c)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"example.com."}, h.Origins)
+		assert.Equal(t, store.MySQL, h.DatabaseConfig.Type)
+		assert.Equal(t, "127.0.0.1", h.DatabaseConfig.Host)
+		assert.Equal(t, 3306, h.DatabaseConfig.Port)
+		assert.Equal(t, 64, h.CacheSizeMB)
+		assert.Equal(t, time.Minute, h.ZoneRefresh)
+		assert.Equal(t, []string{"in-addr.arpa."}, h.Fall.Zones)
+		if fwd, ok := h.Upstream.(*forwarder); assert.True(t, ok) {
+			assert.Equal(t, []string{"192.0.2.53:53", "192.0.2.54:5353"}, fwd.addrs)
+		}
+		assert.True(t, h.HealthChecks)
+		assert.Equal(t, 10*time.Second, h.HealthRefresh)
+		assert.True(t, h.ECS)
+		assert.Equal(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("2001:db8::/32")}, h.ECSTrusted)
+		assert.Equal(t, []GeoIPSource{
+			{Kind: "override"},
+			{Kind: "csv", Args: []string{"dbip", "ranges.csv"}},
+			{Kind: "maxmind", Args: []string{"", "asn.mmdb"}},
+		}, h.GeoIP)
+	})
+
+	t.Run("Defaults of optional arguments", func(t *testing.T) {
+		c := caddy.NewTestController("dns", `hermes {
+			fallthrough
+			upstream
+			healthcheck
+			ecs
+		}`)
+		c.ServerBlockKeys = []string{"example.org."}
+		h, err := parseHermes(c)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"example.org."}, h.Origins)
+		assert.Equal(t, []string{"."}, h.Fall.Zones)
+		assert.IsType(t, &upstream.Upstream{}, h.Upstream)
+		assert.True(t, h.HealthChecks)
+		assert.Zero(t, h.HealthRefresh)
+		assert.True(t, h.ECS)
+		assert.Empty(t, h.ECSTrusted)
+	})
+
+	// Each input is the body of a hermes block
+	// Each input is the body of a hermes block
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"Unknown property", "foo"},
+		{"Unsupported database", "db oracle"},
+		{"Unknown db property", "db mysql {\nsocket /tmp/db\n}"},
+		{"db block not opened", "db mysql host"},
+		{"Invalid cache_size", "cache_size big"},
+		{"Missing zone_refresh", "zone_refresh"},
+		{"Invalid zone_refresh", "zone_refresh often"},
+		{"Negative zone_refresh", "zone_refresh -1s"},
+		{"Invalid upstream", "upstream not:an:address:53"},
+		{"Invalid healthcheck", "healthcheck 0s"},
+		{"Too many healthcheck arguments", "healthcheck 10s 20s"},
+		{"Invalid ecs network", "ecs 10.0.0.1"},
+		{"Empty geoip", "geoip"},
+		{"Unknown csv layout", "geoip csv geo2 ranges.csv"},
+		{"Missing csv file", "geoip csv dbip"},
+		{"Override with arguments", "geoip override extra"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseHermes(caddy.NewTestController("dns", "hermes {\n"+tt.input+"\n}"))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseGeoIPSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    GeoIPSource
+		wantErr bool
+	}{
+		{"City only", []string{"city.mmdb"}, GeoIPSource{Kind: "maxmind", Args: []string{"city.mmdb", ""}}, false},
+		{"City and ASN", []string{"maxmind", "city.mmdb", "asn.mmdb"}, GeoIPSource{Kind: "maxmind", Args: []string{"city.mmdb", "asn.mmdb"}}, false},
+		{"ASN only", []string{"maxmind", "-", "asn.mmdb"}, GeoIPSource{Kind: "maxmind", Args: []string{"", "asn.mmdb"}}, false},
+		{"CSV", []string{"csv", "ip2location", "ranges.csv"}, GeoIPSource{Kind: "csv", Args: []string{"ip2location", "ranges.csv"}}, false},
+		{"Override", []string{"override"}, GeoIPSource{Kind: "override"}, false},
+		{"No provider", nil, GeoIPSource{}, true},
+		{"Maxmind without database", []string{"maxmind"}, GeoIPSource{}, true},
+		{"Skipped City without ASN", []string{"-"}, GeoIPSource{}, true},
+		{"Too many databases", []string{"a.mmdb", "b.mmdb", "c.mmdb"}, GeoIPSource{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGeoIPSource(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package plugin
+
+import (
+	"context"
+	"time"
+
+	"github.com/coredns/coredns/request"
+	"github.com/miekg/dns"
+)
+
+// upstreamTimeout bounds a single exchange with an upstream name server
+const upstreamTimeout = 2 * time.Second
+
+// forwarder resolves external names by querying fixed name servers in order
+type forwarder struct {
+	addrs []string
+	udp   *dns.Client
+	tcp   *dns.Client
+}
+
+// newForwarder creates a forwarder for host:port addresses
+func newForwarder(addrs []string) *forwarder {
+	return &forwarder{
+		addrs: addrs,
+		udp:   &dns.Client{Net: "udp", Timeout: upstreamTimeout},
+		tcp:   &dns.Client{Net: "tcp", Timeout: upstreamTimeout},
+	}
+}
+
+// Lookup implements resolver.Upstream
+func (f *forwarder) Lookup(ctx context.Context, state request.Request, name string, typ uint16) (*dns.Msg, error) {
+	req := new(dns.Msg)
+	req.SetQuestion(dns.Fqdn(name), typ)
+	req.SetEdns0(dns.DefaultMsgSize, false)
+
+	var lastErr error
+	for _, addr := range f.addrs {
+		res, _, err := f.udp.ExchangeContext(ctx, req, addr)
+		if err == nil && res.Truncated {
+			res, _, err = f.tcp.ExchangeContext(ctx, req, addr)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return res, nil
+	}
+	return nil, lastErr
+}